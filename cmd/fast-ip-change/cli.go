@@ -0,0 +1,1260 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/config"
+	"github.com/fast-ip-change/fast-ip-change/internal/history"
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/internal/network"
+	"github.com/fast-ip-change/fast-ip-change/internal/utils"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// コマンドラインの終了コード
+const (
+	exitOK          = 0 // 成功
+	exitError       = 1 // 設定ファイルの読み書きなどの一般的なエラー
+	exitUsage       = 2 // 引数の誤り
+	exitNotFound    = 3 // プロファイルが見つからない
+	exitInvalid     = 4 // プロファイルの検証エラー
+	exitApplyFailed = 5 // 設定の適用に失敗（ロールバック済み、またはロールバック不要）
+	exitRollback    = 6 // 設定の適用とロールバックの両方に失敗
+	exitNotAdmin    = 7 // 管理者権限がない
+	exitHealthCheck = 8 // 設定は適用したが疎通確認に失敗（変更前の設定に戻さないプロファイル）
+)
+
+// CLIのエラーコード（JSON出力の error.code）
+const (
+	errCodeUsage          = "USAGE"
+	errCodeNotFound       = "PROFILE_NOT_FOUND"
+	errCodeInvalid        = "INVALID_PROFILE"
+	errCodeDuplicateName  = "DUPLICATE_PROFILE_NAME"
+	errCodeNotAdmin       = "NOT_ADMIN"
+	errCodeConfig         = "CONFIG_ERROR"
+	errCodeIO             = "IO_ERROR"
+	errCodeNothingToUndo  = "NOTHING_TO_UNDO"
+	errCodeInvalidConfig  = "INVALID_CONFIG"
+	errCodeManagedProfile = "MANAGED_PROFILE"
+	errCodeSceneNotFound  = "SCENE_NOT_FOUND"
+	errCodeInvalidScene   = "INVALID_SCENE"
+	errCodeHealthCheck    = "HEALTH_CHECK_FAILED"
+)
+
+// UpdateConfig の中で検出したエラー（設定ファイルは変更されない）
+var (
+	errNotFound      = errors.New("profile not found")
+	errDuplicateName = errors.New("duplicate profile name")
+)
+
+// listEntry は list の JSON 出力の1件です（プロファイルの項目に集中管理の情報を加えたもの）
+type listEntry struct {
+	models.Profile
+	Managed       bool   `json:"managed,omitempty"`
+	ManagedSource string `json:"managedSource,omitempty"`
+}
+
+// cliCommand はサブコマンドを表します
+type cliCommand struct {
+	name    string
+	args    string
+	summary string
+	run     func(args []string) int
+}
+
+// cliCommands はサブコマンドの一覧を返します
+func cliCommands() []cliCommand {
+	return []cliCommand{
+		{"list", "[-json]", "プロファイルの一覧を表示", runList},
+		{"apply", "[-json] [-dry-run] [-set <変数名=値>]... <プロファイル名|テンプレート名|ID>", "プロファイル（またはテンプレートから作成したプロファイル）を適用", runApply},
+		{"dhcp", "[-json] [-dry-run] <NIC名>", "NICをDHCP（自動取得）に切り替え", runDHCP},
+		{"show", "[-json] <NIC名>", "NICの現在の設定を表示", runShow},
+		{"nics", "[-json]", "NICの一覧を表示", runNICs},
+		{"add", "[-json] -name <名前> -nic <NIC名> -ip <IP[/長さ]> [-mask <マスク>] [-gateway <GW>] [-dns <DNS>]... [-dns-suffix <サフィックス>] [-register-dns <true|false>] [-route <ルート>]... [-metric <automatic|数値>] [-mtu <MTU>] [-addr <IP/マスク>]... [-ipv6 <IPv6/長さ>] [-ipv6-gateway <GW>] [-ipv6-dns <DNS>]... [-check <疎通確認>]... [-rollback-on-check-failure] [-group <グループ>] [-pin]", "プロファイルを追加", runAdd},
+		{"remove", "[-json] <プロファイル名|ID>", "プロファイルを削除", runRemove},
+		{"templates", "[-json]", "テンプレートと変数の一覧を表示", runTemplates},
+		{"scene", "[-json] [-dry-run] <シーン名|ID>", "シーン（複数のNICの設定）をまとめて適用", runScene},
+		{"scenes", "[-json]", "シーンの一覧を表示", runScenes},
+		{"schedules", "[-json]", "スケジュールと次の切り替え時刻を表示", runSchedules},
+		{"export", "[-o <ファイル>] [-format json|csv] [-settings] [プロファイル名|ID]...", "プロファイルをJSONまたはCSV形式で出力", runExport},
+		{"import", "[-json] [-dry-run] [-on-conflict skip|overwrite|rename] [-new-ids] [-settings] <ファイル>", "JSONまたはCSVのプロファイルを取り込む", runImport},
+		{"validate", "[-json]", "設定ファイルを検証", runValidate},
+		{"history", "[-json] [-n <件数>]", "切り替えの履歴を新しい順に表示", runHistory},
+		{"undo", "[-json]", "最後の切り替え前の設定に戻す", runUndo},
+	}
+}
+
+// isCLICommand は引数がサブコマンド名かどうかを判定します
+func isCLICommand(name string) bool {
+	if name == "help" {
+		return true
+	}
+	for _, cmd := range cliCommands() {
+		if cmd.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// runCLI はサブコマンドを実行し、終了コードを返します
+func runCLI(args []string) int {
+	// ログはファイルのみに出力（コマンドの出力と混ざらないようにする）
+	logLevel := "INFO"
+	if cfg, err := config.LoadConfig(); err == nil {
+		logLevel = cfg.Settings.LogLevel
+	}
+	if err := logger.InitFileOnly(logLevel); err != nil {
+		fmt.Fprintf(os.Stderr, "警告: ロガーの初期化に失敗: %v\n", err)
+	}
+	defer logger.Close()
+
+	name := args[0]
+	for _, cmd := range cliCommands() {
+		if cmd.name == name {
+			return cmd.run(args[1:])
+		}
+	}
+
+	printUsage(os.Stdout)
+	return exitOK
+}
+
+// printUsage はサブコマンドの使い方を表示します
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "使い方: fast-ip-change.exe <コマンド> [オプション]")
+	fmt.Fprintln(w, "        fast-ip-change.exe            （引数なしでシステムトレイに常駐）")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "コマンド:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, cmd := range cliCommands() {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "終了コード: 0=成功 1=エラー 2=引数の誤り 3=プロファイルなし 4=検証エラー 5=適用失敗 6=ロールバック失敗 7=管理者権限なし 8=疎通確認失敗")
+}
+
+// cliOutput はサブコマンドの出力形式を保持します
+type cliOutput struct {
+	json bool
+}
+
+// cliResult はJSON出力の共通形式です
+type cliResult struct {
+	OK     bool      `json:"ok"`
+	Result any       `json:"result,omitempty"`
+	Error  *cliError `json:"error,omitempty"`
+}
+
+// cliError はJSON出力のエラー情報です
+type cliError struct {
+	Code     string           `json:"code"`
+	Message  string           `json:"message"`
+	Rollback *cliRollbackInfo `json:"rollback,omitempty"`
+	// Health は疎通確認に失敗して変更前の設定に戻した場合の疎通確認の結果です
+	Health *network.HealthReport `json:"health,omitempty"`
+}
+
+// cliRollbackInfo はJSON出力のロールバック結果です
+type cliRollbackInfo struct {
+	Attempted bool   `json:"attempted"`
+	Succeeded bool   `json:"succeeded"`
+	Error     string `json:"error,omitempty"`
+}
+
+// success は結果を出力し、exitOK を返します
+// JSON出力でない場合は text を標準出力に表示します
+func (o cliOutput) success(result any, text func(w io.Writer)) int {
+	if o.json {
+		writeJSON(os.Stdout, cliResult{OK: true, Result: result})
+	} else if text != nil {
+		text(os.Stdout)
+	}
+	return exitOK
+}
+
+// healthCheckFailed は設定を適用したが疎通確認に失敗した結果を出力し、exitHealthCheck を返します
+// JSON出力では ok を false、エラーコードを HEALTH_CHECK_FAILED とし、適用結果（疎通確認の結果を含む）は result に出力します
+// JSON出力でない場合は text を標準出力に、warnings を警告として標準エラー出力に表示します
+func (o cliOutput) healthCheckFailed(result any, text func(w io.Writer), warnings []string) int {
+	if o.json {
+		writeJSON(os.Stdout, cliResult{OK: false, Result: result, Error: &cliError{
+			Code:    errCodeHealthCheck,
+			Message: "設定を適用しましたが、疎通確認に失敗しました: " + strings.Join(warnings, "; "),
+		}})
+		return exitHealthCheck
+	}
+	text(os.Stdout)
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "警告: %s\n", warning)
+	}
+	return exitHealthCheck
+}
+
+// fail はエラーを出力し、指定された終了コードを返します
+func (o cliOutput) fail(exitCode int, code string, err error) int {
+	if o.json {
+		writeJSON(os.Stdout, cliResult{OK: false, Error: &cliError{Code: code, Message: err.Error()}})
+	} else {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+	}
+	return exitCode
+}
+
+// failNetwork はネットワーク操作のエラーを出力し、ロールバック結果に応じた終了コードを返します
+func (o cliOutput) failNetwork(err error) int {
+	var netErr *network.NetworkError
+	if !errors.As(err, &netErr) {
+		return o.fail(exitApplyFailed, "APPLY_FAILED", err)
+	}
+
+	exitCode := exitApplyFailed
+	var rollback *cliRollbackInfo
+	if netErr.Rollback != nil {
+		rollback = &cliRollbackInfo{
+			Attempted: netErr.Rollback.Attempted,
+			Succeeded: netErr.Rollback.Succeeded(),
+		}
+		if netErr.Rollback.Err != nil {
+			rollback.Error = netErr.Rollback.Err.Error()
+		}
+		if !netErr.Rollback.Succeeded() {
+			exitCode = exitRollback
+		}
+	}
+
+	var health *network.HealthReport
+	var healthErr *network.HealthCheckError
+	if errors.As(err, &healthErr) {
+		health = healthErr.Report
+	}
+
+	if o.json {
+		writeJSON(os.Stdout, cliResult{OK: false, Error: &cliError{
+			Code:     netErr.Code,
+			Message:  netErr.Error(),
+			Rollback: rollback,
+			Health:   health,
+		}})
+	} else {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", netErr)
+	}
+	return exitCode
+}
+
+func writeJSON(w io.Writer, v any) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+// newFlagSet はサブコマンド用の FlagSet を作成します
+func newFlagSet(name string, out *cliOutput) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.BoolVar(&out.json, "json", false, "結果をJSON形式で出力")
+	fs.Usage = func() {
+		for _, cmd := range cliCommands() {
+			if cmd.name == name {
+				fmt.Fprintf(os.Stderr, "使い方: fast-ip-change.exe %s %s\n", cmd.name, cmd.args)
+			}
+		}
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs はオプションと位置引数が混在した引数を解析し、位置引数を返します
+// 解析に失敗した場合はエラーを出力し、exitUsage を返します（成功した場合は exitOK）
+func parseArgs(fs *flag.FlagSet, out *cliOutput, args []string) ([]string, int) {
+	var positional []string
+	rest := args
+	for {
+		if err := fs.Parse(rest); err != nil {
+			// 誤ったオプションより後の -json は解析されないため、元の引数から判定する
+			out.json = out.json || hasJSONFlag(args)
+			return nil, out.fail(exitUsage, errCodeUsage, err)
+		}
+		if fs.NArg() == 0 {
+			return positional, exitOK
+		}
+		positional = append(positional, fs.Arg(0))
+		rest = fs.Args()[1:]
+	}
+}
+
+// hasJSONFlag は引数に -json が含まれるかどうかを返します（"--" より後は位置引数のため含めません）
+func hasJSONFlag(args []string) bool {
+	for _, arg := range args {
+		switch arg {
+		case "--":
+			return false
+		case "-json", "--json", "-json=true", "--json=true":
+			return true
+		}
+	}
+	return false
+}
+
+// parseSingleArg は位置引数を1つだけ受け取るサブコマンドの引数を解析します
+func parseSingleArg(fs *flag.FlagSet, out cliOutput, args []string) (string, int) {
+	positional, code := parseArgs(fs, &out, args)
+	if code != exitOK {
+		return "", code
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return "", out.fail(exitUsage, errCodeUsage, fmt.Errorf("引数の数が正しくありません"))
+	}
+	return positional[0], exitOK
+}
+
+// requireAdmin は管理者権限がない場合にエラーを出力します
+func requireAdmin(out cliOutput) (int, bool) {
+	if utils.IsAdmin() {
+		return exitOK, true
+	}
+	return out.fail(exitNotAdmin, errCodeNotAdmin, fmt.Errorf("このコマンドは管理者権限で実行する必要があります")), false
+}
+
+func runList(args []string) int {
+	var out cliOutput
+	fs := newFlagSet("list", &out)
+	if _, code := parseArgs(fs, &out, args); code != exitOK {
+		return code
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return out.fail(exitError, errCodeConfig, err)
+	}
+
+	entries := make([]listEntry, len(cfg.Profiles))
+	for i, p := range cfg.Profiles {
+		entries[i] = listEntry{Profile: p, Managed: p.IsManaged(), ManagedSource: p.ManagedSource}
+	}
+	return out.success(entries, func(w io.Writer) {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "名前\tグループ\tIPアドレス\tサブネットマスク\tゲートウェイ\tNIC名\tID")
+		for _, p := range cfg.Profiles {
+			name := p.Name
+			if p.Pinned {
+				name = "* " + name
+			}
+			if p.IsManaged() {
+				name += " [管理]"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", name, p.Group, p.IPAddress, models.FormatSubnetMask(p.SubnetMask), p.Gateway, p.NICName, p.ID)
+		}
+		tw.Flush()
+	})
+}
+
+func runApply(args []string) int {
+	var (
+		out    cliOutput
+		dryRun bool
+		sets   []string
+	)
+	fs := newFlagSet("apply", &out)
+	fs.BoolVar(&dryRun, "dry-run", false, "実行するコマンドと差分を表示のみ（設定は変更しない）")
+	fs.Func("set", "テンプレートの変数の値（変数名=値、複数指定可）", func(value string) error {
+		sets = append(sets, value)
+		return nil
+	})
+	key, code := parseSingleArg(fs, out, args)
+	if code != exitOK {
+		return code
+	}
+	values, err := models.ParseTemplateValues(sets)
+	if err != nil {
+		return out.fail(exitUsage, errCodeUsage, err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return out.fail(exitError, errCodeConfig, err)
+	}
+
+	profile, code := resolveApplyTarget(out, cfg, key, values)
+	if code != exitOK {
+		return code
+	}
+
+	if dryRun {
+		plan, err := network.PlanProfile(profile)
+		if err != nil {
+			return out.failNetwork(err)
+		}
+		return out.success(plan, func(w io.Writer) { fmt.Fprint(w, plan.String()) })
+	}
+
+	if code, ok := requireAdmin(out); !ok {
+		return code
+	}
+
+	configureNetwork(cfg)
+	report, err := history.ApplyProfile(profile)
+	if err != nil {
+		logger.Error("プロファイルの適用に失敗", err, "profile", profile.Name)
+		return out.failNetwork(err)
+	}
+
+	logger.Info("プロファイルを適用しました", "profile", profile.Name)
+	result := applyResult{Profile: *profile, Health: report}
+	text := func(w io.Writer) {
+		fmt.Fprintf(w, "%s に %s（%s）を適用しました\n", profile.NICName, profile.Name, profile.IPAddress)
+		if report != nil {
+			printHealthReport(w, report)
+		}
+	}
+	if report != nil && !report.Passed() {
+		return out.healthCheckFailed(result, text, []string{report.String()})
+	}
+	return out.success(result, text)
+}
+
+// configureNetwork は設定に応じてIPアドレスの重複を検出した場合の動作を設定し、適用を続ける警告を標準エラー出力に表示します
+func configureNetwork(cfg *models.Config) {
+	client := network.DefaultClient()
+	client.SetAddressConflictPolicy(cfg.Settings.AddressConflictPolicy())
+	client.SetWarningHandler(func(message string) {
+		fmt.Fprintf(os.Stderr, "警告: %s\n", message)
+	})
+}
+
+// applyResult は apply の -json 出力の結果です
+type applyResult struct {
+	models.Profile
+	// Health は適用後の疎通確認の結果です（疎通確認がない場合は省略）
+	Health *network.HealthReport `json:"health,omitempty"`
+}
+
+// printHealthReport は疎通確認の結果を1件ずつ表示します
+func printHealthReport(w io.Writer, report *network.HealthReport) {
+	fmt.Fprintln(w, "疎通確認:")
+	for i := range report.Results {
+		result := &report.Results[i]
+		status := "OK"
+		if !result.OK {
+			status = "NG"
+		}
+		fmt.Fprintf(w, "  %s  %s（%dms）\n", status, result, result.ElapsedMS)
+	}
+}
+
+// resolveApplyTarget は適用するプロファイルを返します
+// key がテンプレートの場合は values で変数を置き換えたプロファイルを作成します
+func resolveApplyTarget(out cliOutput, cfg *models.Config, key string, values map[string]string) (*models.Profile, int) {
+	if profile := cfg.FindProfile(key); profile != nil {
+		if len(values) > 0 {
+			return nil, out.fail(exitUsage, errCodeUsage, fmt.Errorf("-set はテンプレートを適用する場合のみ指定できます: %s", key))
+		}
+		if err := profile.Validate(); err != nil {
+			return nil, out.fail(exitInvalid, errCodeInvalid, fmt.Errorf("プロファイル設定が不正です: %w", err))
+		}
+		return profile, exitOK
+	}
+
+	template := cfg.FindTemplate(key)
+	if template == nil {
+		return nil, out.fail(exitNotFound, errCodeNotFound, fmt.Errorf("プロファイルが見つかりません: %s", key))
+	}
+	profile, err := template.Expand(values)
+	if errors.Is(err, models.ErrMissingVariable) || errors.Is(err, models.ErrUnknownVariable) {
+		return nil, out.fail(exitUsage, errCodeUsage, fmt.Errorf("%w（テンプレート %s の変数: %s）", err, template.Name, formatTemplateVariables(template)))
+	}
+	if err != nil {
+		return nil, out.fail(exitInvalid, errCodeInvalid, fmt.Errorf("テンプレートから作成したプロファイルが不正です: %w", err))
+	}
+	return profile, exitOK
+}
+
+// formatTemplateVariables はテンプレートの変数を「名前=既定値」の形式で列挙します
+func formatTemplateVariables(t *models.ProfileTemplate) string {
+	names := make([]string, len(t.Variables))
+	for i, v := range t.Variables {
+		names[i] = v.Name
+		if v.Default != "" {
+			names[i] += "=" + v.Default
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func runTemplates(args []string) int {
+	var out cliOutput
+	fs := newFlagSet("templates", &out)
+	if _, code := parseArgs(fs, &out, args); code != exitOK {
+		return code
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return out.fail(exitError, errCodeConfig, err)
+	}
+
+	templates := cfg.Templates
+	if templates == nil {
+		templates = []models.ProfileTemplate{}
+	}
+	return out.success(templates, func(w io.Writer) {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "名前\tIPアドレス\tNIC名\t変数\tID")
+		for i := range templates {
+			t := &templates[i]
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", t.Name, t.Profile.IPAddress, t.Profile.NICName, formatTemplateVariables(t), t.ID)
+		}
+		tw.Flush()
+	})
+}
+
+// sceneStepEntry は scene の JSON 出力の1件です（NICごとの適用結果）
+type sceneStepEntry struct {
+	NICName     string                `json:"nicName"`
+	ProfileID   string                `json:"profileId,omitempty"`
+	ProfileName string                `json:"profileName,omitempty"`
+	DHCP        bool                  `json:"dhcp,omitempty"`
+	Applied     bool                  `json:"applied"`
+	Error       string                `json:"error,omitempty"`
+	Rollback    *cliRollbackInfo      `json:"rollback,omitempty"`
+	Health      *network.HealthReport `json:"health,omitempty"`
+}
+
+func runScene(args []string) int {
+	var (
+		out    cliOutput
+		dryRun bool
+	)
+	fs := newFlagSet("scene", &out)
+	fs.BoolVar(&dryRun, "dry-run", false, "実行するコマンドと差分を表示のみ（設定は変更しない）")
+	key, code := parseSingleArg(fs, out, args)
+	if code != exitOK {
+		return code
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return out.fail(exitError, errCodeConfig, err)
+	}
+
+	scene := cfg.FindScene(key)
+	if scene == nil {
+		return out.fail(exitNotFound, errCodeSceneNotFound, fmt.Errorf("シーンが見つかりません: %s", key))
+	}
+	targets, err := cfg.ResolveScene(scene)
+	if err != nil {
+		return out.fail(exitInvalid, errCodeInvalidScene, fmt.Errorf("シーン設定が不正です: %w", err))
+	}
+
+	if dryRun {
+		plans, err := network.PlanScene(targets)
+		if err != nil {
+			return out.failNetwork(err)
+		}
+		return out.success(plans, func(w io.Writer) {
+			for _, plan := range plans {
+				fmt.Fprint(w, plan.String())
+			}
+		})
+	}
+
+	if code, ok := requireAdmin(out); !ok {
+		return code
+	}
+
+	configureNetwork(cfg)
+	results, err := history.ApplyScene(scene, targets)
+	steps := sceneStepEntries(results)
+	if err != nil {
+		logger.Error("シーンの適用に失敗", err, "scene", scene.Name)
+		return out.failScene(err, steps)
+	}
+
+	logger.Info("シーンを適用しました", "scene", scene.Name)
+	text := func(w io.Writer) {
+		fmt.Fprintf(w, "シーン「%s」を適用しました\n", scene.Name)
+		for _, result := range results {
+			fmt.Fprintf(w, "  %s\n", result.Target.Description())
+			if result.Health != nil {
+				printHealthReport(w, result.Health)
+			}
+		}
+	}
+	var warnings []string
+	for _, result := range results {
+		if result.Health != nil && !result.Health.Passed() {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", result.Target.NICName, result.Health))
+		}
+	}
+	if len(warnings) > 0 {
+		return out.healthCheckFailed(steps, text, warnings)
+	}
+	return out.success(steps, text)
+}
+
+// sceneStepEntries はシーンの適用結果を JSON 出力の形式に変換します
+func sceneStepEntries(results []network.SceneStepResult) []sceneStepEntry {
+	steps := make([]sceneStepEntry, len(results))
+	for i, result := range results {
+		step := sceneStepEntry{NICName: result.Target.NICName, Applied: result.Applied, Health: result.Health}
+		if profile := result.Target.Profile; profile != nil {
+			step.ProfileID = profile.ID
+			step.ProfileName = profile.Name
+		} else {
+			step.DHCP = true
+		}
+		if result.Err != nil {
+			step.Error = result.Err.Error()
+		}
+		if rollback := result.Rollback; rollback != nil {
+			step.Rollback = &cliRollbackInfo{Attempted: rollback.Attempted, Succeeded: rollback.Succeeded()}
+			if rollback.Err != nil {
+				step.Rollback.Error = rollback.Err.Error()
+			}
+		}
+		steps[i] = step
+	}
+	return steps
+}
+
+// failScene はシーンの適用エラーを出力し、ロールバック結果に応じた終了コードを返します
+func (o cliOutput) failScene(err error, steps []sceneStepEntry) int {
+	var sceneErr *network.SceneError
+	if !errors.As(err, &sceneErr) {
+		return o.failNetwork(err)
+	}
+
+	exitCode := exitApplyFailed
+	if len(sceneErr.FailedRollbacks()) > 0 {
+		exitCode = exitRollback
+	}
+
+	if o.json {
+		code := "APPLY_FAILED"
+		var netErr *network.NetworkError
+		if errors.As(err, &netErr) {
+			code = netErr.Code
+		}
+		writeJSON(os.Stdout, cliResult{OK: false, Result: steps, Error: &cliError{Code: code, Message: sceneErr.Error()}})
+	} else {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", sceneErr)
+	}
+	return exitCode
+}
+
+func runScenes(args []string) int {
+	var out cliOutput
+	fs := newFlagSet("scenes", &out)
+	if _, code := parseArgs(fs, &out, args); code != exitOK {
+		return code
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return out.fail(exitError, errCodeConfig, err)
+	}
+
+	scenes := cfg.Scenes
+	if scenes == nil {
+		scenes = []models.Scene{}
+	}
+	return out.success(scenes, func(w io.Writer) {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "名前\tグループ\tステップ\tID")
+		for i := range scenes {
+			s := &scenes[i]
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Name, s.Group, formatSceneSteps(cfg, s), s.ID)
+		}
+		tw.Flush()
+	})
+}
+
+// scheduleEntry は schedules の -json 出力の1件です
+type scheduleEntry struct {
+	models.Schedule
+	// Next は次の切り替え時刻です（無効なスケジュール・停止中の場合は省略）
+	Next *time.Time `json:"next,omitempty"`
+}
+
+func runSchedules(args []string) int {
+	var out cliOutput
+	fs := newFlagSet("schedules", &out)
+	if _, code := parseArgs(fs, &out, args); code != exitOK {
+		return code
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return out.fail(exitError, errCodeConfig, err)
+	}
+
+	now := time.Now()
+	entries := make([]scheduleEntry, len(cfg.Schedules))
+	for i, s := range cfg.Schedules {
+		entries[i].Schedule = s
+		if next, err := s.Next(now); err == nil && !s.Disabled {
+			entries[i].Next = &next
+		}
+	}
+	return out.success(entries, func(w io.Writer) {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "名前\t曜日・時刻\t切り替え先\t次の切り替え\tID")
+		for i := range entries {
+			e := &entries[i]
+			next := "-"
+			switch {
+			case e.Disabled:
+				next = "（停止中）"
+			case e.Next != nil:
+				next = e.Next.Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.Name, e.WhenString(), cfg.TargetString(&e.Schedule), next, e.ID)
+		}
+		tw.Flush()
+	})
+}
+
+// formatSceneSteps はシーンのステップを「NIC名: プロファイル名」の形式で列挙します
+func formatSceneSteps(cfg *models.Config, s *models.Scene) string {
+	steps := make([]string, len(s.Steps))
+	for i, step := range s.Steps {
+		if step.DHCPNIC != "" {
+			steps[i] = step.DHCPNIC + ": DHCP"
+		} else if profile := cfg.FindProfile(step.ProfileID); profile != nil {
+			steps[i] = fmt.Sprintf("%s: %s", profile.NICName, profile.Name)
+		} else {
+			steps[i] = "?: " + step.ProfileID // 存在しないプロファイル（validate で検出）
+		}
+	}
+	return strings.Join(steps, ", ")
+}
+
+func runDHCP(args []string) int {
+	var (
+		out    cliOutput
+		dryRun bool
+	)
+	fs := newFlagSet("dhcp", &out)
+	fs.BoolVar(&dryRun, "dry-run", false, "実行するコマンドと差分を表示のみ（設定は変更しない）")
+	nicName, code := parseSingleArg(fs, out, args)
+	if code != exitOK {
+		return code
+	}
+
+	if dryRun {
+		plan, err := network.PlanDHCP(nicName)
+		if err != nil {
+			return out.failNetwork(err)
+		}
+		return out.success(plan, func(w io.Writer) { fmt.Fprint(w, plan.String()) })
+	}
+
+	if code, ok := requireAdmin(out); !ok {
+		return code
+	}
+
+	if err := history.ApplyDHCP(nicName); err != nil {
+		logger.Error("DHCP設定の適用に失敗", err)
+		return out.failNetwork(err)
+	}
+
+	logger.Info("DHCP設定を適用しました", "nic", nicName)
+	return out.success(map[string]string{"nicName": nicName}, func(w io.Writer) {
+		fmt.Fprintf(w, "%s をDHCP（自動取得）に切り替えました\n", nicName)
+	})
+}
+
+func runShow(args []string) int {
+	var out cliOutput
+	fs := newFlagSet("show", &out)
+	nicName, code := parseSingleArg(fs, out, args)
+	if code != exitOK {
+		return code
+	}
+
+	if !models.IsValidNICName(nicName) {
+		return out.fail(exitUsage, errCodeUsage, fmt.Errorf("NIC名に不正な文字が含まれています"))
+	}
+
+	snapshot, err := network.TakeSnapshot(nicName)
+	if err != nil {
+		return out.failNetwork(err)
+	}
+
+	return out.success(snapshot, func(w io.Writer) {
+		mode := "静的"
+		if snapshot.DHCPEnabled {
+			mode = "DHCP"
+		}
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "NIC名\t%s\n", nicName)
+		fmt.Fprintf(tw, "アドレスの取得方法\t%s\n", mode)
+		fmt.Fprintf(tw, "IPアドレス\t%s\n", snapshot.Config.IPAddress)
+		fmt.Fprintf(tw, "サブネットマスク\t%s\n", models.FormatSubnetMask(snapshot.Config.SubnetMask))
+		for _, entry := range snapshot.Config.AdditionalAddresses {
+			fmt.Fprintf(tw, "追加アドレス\t%s\n", entry)
+		}
+		fmt.Fprintf(tw, "デフォルトゲートウェイ\t%s\n", snapshot.Config.Gateway)
+		fmt.Fprintf(tw, "DNSサーバー\t%s\n", strings.Join(snapshot.Config.DNSServers, ", "))
+		fmt.Fprintf(tw, "インターフェイスメトリック\t%s\n", snapshot.Config.InterfaceMetric)
+		if snapshot.Config.MTU > 0 {
+			fmt.Fprintf(tw, "MTU\t%d\n", snapshot.Config.MTU)
+		}
+		for _, route := range snapshot.Routes {
+			fmt.Fprintf(tw, "静的ルート\t%s（%s）\n", route, snapshot.RoutesOwner)
+		}
+		if snapshot.Config.HasIPv6() {
+			fmt.Fprintf(tw, "IPv6アドレス\t%s\n", snapshot.Config.IPv6CIDR())
+			fmt.Fprintf(tw, "IPv6ゲートウェイ\t%s\n", snapshot.Config.IPv6Gateway)
+			fmt.Fprintf(tw, "IPv6 DNSサーバー\t%s\n", strings.Join(snapshot.Config.IPv6DNSServers, ", "))
+		}
+		tw.Flush()
+	})
+}
+
+func runNICs(args []string) int {
+	var out cliOutput
+	fs := newFlagSet("nics", &out)
+	if _, code := parseArgs(fs, &out, args); code != exitOK {
+		return code
+	}
+
+	nics, err := network.GetNICList()
+	if err != nil {
+		return out.failNetwork(err)
+	}
+
+	return out.success(nics, func(w io.Writer) {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "名前\t状態\t種類\tMACアドレス\t説明\tインデックス")
+		for _, nic := range nics {
+			index := ""
+			if nic.Index > 0 {
+				index = strconv.Itoa(nic.Index)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", nic.Name, nic.StateString(), nic.Type, nic.MAC, nic.Description, index)
+		}
+		tw.Flush()
+	})
+}
+
+func runAdd(args []string) int {
+	var out cliOutput
+	profile := models.NewProfile()
+	fs := newFlagSet("add", &out)
+	fs.StringVar(&profile.Name, "name", "", "プロファイル名（必須）")
+	fs.StringVar(&profile.NICName, "nic", "", "NIC名（必須）")
+	fs.StringVar(&profile.IPAddress, "ip", "", "IPアドレス（必須。10.0.0.10/24 の形式の場合は -mask を省略可）")
+	fs.StringVar(&profile.SubnetMask, "mask", "", "サブネットマスクまたはプレフィックス長（255.255.255.0 または 24）")
+	fs.StringVar(&profile.Gateway, "gateway", "", "デフォルトゲートウェイ")
+	fs.Func("dns", "DNSサーバー（複数指定可、指定順に優先）", func(value string) error {
+		profile.DNSServers = append(profile.DNSServers, value)
+		return nil
+	})
+	fs.StringVar(&profile.DNSSuffix, "dns-suffix", "", "接続固有のDNSサフィックス")
+	fs.Func("register-dns", "この接続のアドレスをDNSに登録するか（true/false）", func(value string) error {
+		register, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		profile.RegisterDNS = &register
+		return nil
+	})
+	fs.Func("addr", "追加アドレス（IP/マスク、複数指定可）", func(value string) error {
+		entry, err := models.ParseIPAddressEntry(value)
+		if err != nil {
+			return err
+		}
+		profile.AdditionalAddresses = append(profile.AdditionalAddresses, entry)
+		return nil
+	})
+	fs.Func("route", "静的ルート（\"宛先/マスク [ゲートウェイ [メトリック]]\"、複数指定可）", func(value string) error {
+		route, err := models.ParseStaticRoute(value)
+		if err != nil {
+			return err
+		}
+		profile.Routes = append(profile.Routes, route)
+		return nil
+	})
+	fs.StringVar(&profile.InterfaceMetric, "metric", "", "インターフェイスメトリック（automatic または 1〜9999）")
+	fs.IntVar(&profile.MTU, "mtu", 0, "MTU")
+	fs.Func("ipv6", "IPv6アドレス（アドレス/プレフィックス長、省略時は /64）", func(value string) error {
+		addr, length, err := models.ParseIPv6CIDR(value)
+		if err != nil {
+			return err
+		}
+		profile.IPv6Address, profile.IPv6PrefixLength = addr, length
+		return nil
+	})
+	fs.StringVar(&profile.IPv6Gateway, "ipv6-gateway", "", "IPv6ゲートウェイ")
+	fs.Func("ipv6-dns", "IPv6 DNSサーバー（複数指定可）", func(value string) error {
+		profile.IPv6DNSServers = append(profile.IPv6DNSServers, value)
+		return nil
+	})
+	fs.Func("check", "適用後の疎通確認（\"gateway [IP]\"、\"dns <ホスト名>\"、\"tcp <ホスト:ポート>\"。末尾にタイムアウト秒を指定可、複数指定可）", func(value string) error {
+		check, err := models.ParseHealthCheck(value)
+		if err != nil {
+			return err
+		}
+		profile.HealthChecks = append(profile.HealthChecks, check)
+		return nil
+	})
+	fs.BoolVar(&profile.RollbackOnHealthCheckFailure, "rollback-on-check-failure", false, "疎通確認に失敗した場合に変更前の設定に戻す")
+	fs.StringVar(&profile.Group, "group", "", "メニューで表示するグループ（\"拠点/東京\" のように / で区切ると階層）")
+	fs.BoolVar(&profile.Pinned, "pin", false, "メニューの先頭に表示する")
+	positional, code := parseArgs(fs, &out, args)
+	if code != exitOK {
+		return code
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return out.fail(exitUsage, errCodeUsage, fmt.Errorf("不明な引数です: %s", strings.Join(positional, " ")))
+	}
+
+	if err := profile.Normalize(); err != nil {
+		return out.fail(exitInvalid, errCodeInvalid, fmt.Errorf("入力値が不正です: %w", err))
+	}
+	if err := profile.Validate(); err != nil {
+		return out.fail(exitInvalid, errCodeInvalid, fmt.Errorf("入力値が不正です: %w", err))
+	}
+
+	_, err := config.UpdateConfig(func(cfg *models.Config) error {
+		for _, p := range cfg.Profiles {
+			if p.Name == profile.Name {
+				return errDuplicateName
+			}
+		}
+		cfg.Profiles = append(cfg.Profiles, *profile)
+		return nil
+	})
+	if errors.Is(err, errDuplicateName) {
+		return out.fail(exitInvalid, errCodeDuplicateName, fmt.Errorf("同じ名前のプロファイルが既に存在します: %s", profile.Name))
+	}
+	if err != nil {
+		return out.fail(exitError, errCodeConfig, err)
+	}
+
+	logger.Info("プロファイルを追加しました", "profile", profile.Name)
+	return out.success(profile, func(w io.Writer) {
+		fmt.Fprintf(w, "プロファイルを追加しました: %s（ID: %s）\n", profile.Name, profile.ID)
+	})
+}
+
+func runRemove(args []string) int {
+	var out cliOutput
+	fs := newFlagSet("remove", &out)
+	key, code := parseSingleArg(fs, out, args)
+	if code != exitOK {
+		return code
+	}
+
+	var removed models.Profile
+	_, err := config.UpdateConfig(func(cfg *models.Config) error {
+		target := cfg.FindProfile(key)
+		if target == nil {
+			return errNotFound
+		}
+		if target.IsManaged() {
+			return fmt.Errorf("%w: %s（%s）", models.ErrManagedProfile, target.Name, target.ManagedSource)
+		}
+		removed = *target
+
+		profiles := make([]models.Profile, 0, len(cfg.Profiles)-1)
+		for _, p := range cfg.Profiles {
+			if p.ID != removed.ID {
+				profiles = append(profiles, p)
+			}
+		}
+		cfg.Profiles = profiles
+		return nil
+	})
+	if errors.Is(err, errNotFound) {
+		return out.fail(exitNotFound, errCodeNotFound, fmt.Errorf("プロファイルが見つかりません: %s", key))
+	}
+	if errors.Is(err, models.ErrManagedProfile) {
+		return out.fail(exitError, errCodeManagedProfile, err)
+	}
+	if err != nil {
+		return out.fail(exitError, errCodeConfig, err)
+	}
+
+	logger.Info("プロファイルを削除しました", "profile", removed.Name)
+	return out.success(removed, func(w io.Writer) {
+		fmt.Fprintf(w, "プロファイルを削除しました: %s\n", removed.Name)
+	})
+}
+
+func runExport(args []string) int {
+	var (
+		out             cliOutput
+		outputPath      string
+		format          string
+		includeSettings bool
+	)
+	fs := newFlagSet("export", &out)
+	fs.StringVar(&outputPath, "o", "", "出力先ファイル（省略時は標準出力）")
+	fs.StringVar(&format, "format", "", "出力形式 json / csv（省略時は出力先の拡張子から判定）")
+	fs.BoolVar(&includeSettings, "settings", false, "動作設定も出力（JSONのみ）")
+	keys, code := parseArgs(fs, &out, args)
+	if code != exitOK {
+		return code
+	}
+	csvFormat, err := exportFormat(format, outputPath)
+	if err != nil {
+		fs.Usage()
+		return out.fail(exitUsage, errCodeUsage, err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return out.fail(exitError, errCodeConfig, err)
+	}
+	doc, err := config.NewExportDocument(cfg, keys, includeSettings)
+	if err != nil {
+		return out.fail(exitNotFound, errCodeNotFound, err)
+	}
+
+	if outputPath == "" {
+		if csvFormat {
+			err = config.WriteProfilesCSV(os.Stdout, doc.Profiles)
+		} else {
+			err = config.WriteExportJSON(os.Stdout, doc)
+		}
+		if err != nil {
+			return out.fail(exitError, errCodeIO, err)
+		}
+		return exitOK
+	}
+
+	if csvFormat != config.IsCSVPath(outputPath) {
+		return out.fail(exitUsage, errCodeUsage, fmt.Errorf("出力形式と出力先の拡張子が一致しません: %s", outputPath))
+	}
+	if err := config.WriteExportFile(outputPath, doc); err != nil {
+		return out.fail(exitError, errCodeIO, err)
+	}
+
+	return out.success(map[string]any{"path": outputPath, "count": len(doc.Profiles)}, func(w io.Writer) {
+		fmt.Fprintf(w, "%d 件のプロファイルを %s に出力しました\n", len(doc.Profiles), outputPath)
+	})
+}
+
+// exportFormat は出力形式がCSVかどうかを返します（指定がない場合は出力先の拡張子から判定）
+func exportFormat(format, outputPath string) (bool, error) {
+	switch format {
+	case "":
+		return config.IsCSVPath(outputPath), nil
+	case "json":
+		return false, nil
+	case "csv":
+		return true, nil
+	default:
+		return false, fmt.Errorf("出力形式は json または csv で指定してください: %s", format)
+	}
+}
+
+func runImport(args []string) int {
+	var (
+		out        cliOutput
+		onConflict string
+		opts       config.ImportOptions
+		dryRun     bool
+	)
+	fs := newFlagSet("import", &out)
+	fs.StringVar(&onConflict, "on-conflict", string(config.ConflictSkip), "既存のプロファイルとIDまたは名前が重複した場合の扱い skip / overwrite / rename")
+	fs.BoolVar(&opts.RegenerateIDs, "new-ids", false, "すべてのプロファイルに新しいIDを発行")
+	fs.BoolVar(&opts.ImportSettings, "settings", false, "ファイルに含まれる動作設定も取り込む")
+	fs.BoolVar(&dryRun, "dry-run", false, "変更内容を表示のみ（設定は変更しない）")
+	path, code := parseSingleArg(fs, out, args)
+	if code != exitOK {
+		return code
+	}
+	policy, err := config.ParseConflictPolicy(onConflict)
+	if err != nil {
+		fs.Usage()
+		return out.fail(exitUsage, errCodeUsage, err)
+	}
+	opts.OnConflict = policy
+
+	doc, err := config.ReadImportFile(path)
+	if err != nil {
+		return out.fail(exitError, errCodeIO, err)
+	}
+
+	var plan *config.ImportPlan
+	if dryRun {
+		var cfg *models.Config
+		if cfg, err = config.LoadConfig(); err == nil {
+			plan, err = config.PlanImport(cfg, doc, opts)
+		}
+		if err == nil {
+			err = plan.Issues.Err()
+		}
+	} else {
+		plan, err = config.Import(doc, opts)
+	}
+
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		if out.json {
+			writeJSON(os.Stdout, cliResult{OK: false, Result: plan, Error: &cliError{Code: errCodeInvalidConfig, Message: err.Error()}})
+		} else {
+			fmt.Fprintln(os.Stderr, plan)
+			fmt.Fprintln(os.Stderr, "エラーがあるためインポートできません")
+		}
+		return exitInvalid
+	}
+	if err != nil {
+		return out.fail(exitError, errCodeConfig, err)
+	}
+
+	if !dryRun {
+		logger.Info("プロファイルをインポートしました", "path", path,
+			"added", plan.Count(config.ImportAdd)+plan.Count(config.ImportRename), "overwritten", plan.Count(config.ImportOverwrite))
+	}
+	return out.success(plan, func(w io.Writer) {
+		fmt.Fprintln(w, plan)
+		if dryRun {
+			fmt.Fprintln(w, "（-dry-run のため設定は変更していません）")
+		}
+	})
+}
+
+func runValidate(args []string) int {
+	var out cliOutput
+	fs := newFlagSet("validate", &out)
+	positional, code := parseArgs(fs, &out, args)
+	if code != exitOK {
+		return code
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return out.fail(exitUsage, errCodeUsage, fmt.Errorf("不明な引数です: %s", strings.Join(positional, " ")))
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return out.fail(exitError, errCodeConfig, err)
+	}
+
+	issues := cfg.Validate()
+	if issues == nil {
+		issues = models.ValidationIssues{}
+	}
+	if err := issues.Err(); err != nil {
+		if out.json {
+			writeJSON(os.Stdout, cliResult{OK: false, Result: issues, Error: &cliError{Code: errCodeInvalidConfig, Message: err.Error()}})
+		} else {
+			printIssues(os.Stderr, issues)
+		}
+		return exitInvalid
+	}
+
+	return out.success(issues, func(w io.Writer) {
+		printIssues(w, issues)
+		fmt.Fprintln(w, "設定ファイルにエラーはありません")
+	})
+}
+
+// printIssues は検証で見つかった問題を1行ずつ表示します
+func printIssues(w io.Writer, issues models.ValidationIssues) {
+	for _, issue := range issues {
+		fmt.Fprintln(w, issue.String())
+	}
+}
+
+func runHistory(args []string) int {
+	var (
+		out   cliOutput
+		limit int
+	)
+	fs := newFlagSet("history", &out)
+	fs.IntVar(&limit, "n", 20, "表示する件数（0 の場合はすべて）")
+	if _, code := parseArgs(fs, &out, args); code != exitOK {
+		return code
+	}
+
+	entries, err := history.Load()
+	if err != nil {
+		return out.fail(exitError, errCodeIO, err)
+	}
+
+	// 新しい順に並べ替えて件数を制限
+	recent := make([]history.Entry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		if limit > 0 && len(recent) >= limit {
+			break
+		}
+		recent = append(recent, entries[i])
+	}
+
+	return out.success(recent, func(w io.Writer) {
+		if len(recent) == 0 {
+			fmt.Fprintln(w, "履歴がありません")
+			return
+		}
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "日時\t結果\t内容\t変更前\tエラー")
+		for _, entry := range recent {
+			result := "成功"
+			if !entry.Success {
+				result = "失敗"
+			}
+			before := ""
+			if entry.Before != nil && entry.Before.Config != nil {
+				before = entry.Before.Config.IPAddress
+				if entry.Before.DHCPEnabled {
+					before = "DHCP"
+				}
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", entry.Time.Format("2006-01-02 15:04:05"), result, entry.Description(), before, entry.ErrorCode)
+		}
+		tw.Flush()
+	})
+}
+
+func runUndo(args []string) int {
+	var out cliOutput
+	fs := newFlagSet("undo", &out)
+	positional, code := parseArgs(fs, &out, args)
+	if code != exitOK {
+		return code
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return out.fail(exitUsage, errCodeUsage, fmt.Errorf("不明な引数です: %s", strings.Join(positional, " ")))
+	}
+
+	if code, ok := requireAdmin(out); !ok {
+		return code
+	}
+
+	target, err := history.Undo()
+	if errors.Is(err, history.ErrNothingToUndo) {
+		return out.fail(exitError, errCodeNothingToUndo, err)
+	}
+	if err != nil {
+		logger.Error("直前の設定への復元に失敗", err)
+		return out.failNetwork(err)
+	}
+
+	logger.Info("直前の設定に戻しました", "nic", target.NICName, "history", target.ID)
+	return out.success(target, func(w io.Writer) {
+		if target.InBatch() {
+			fmt.Fprintf(w, "シーン「%s」（%s）を適用する前の設定に戻しました\n", target.SceneName, target.Time.Format("2006-01-02 15:04:05"))
+			return
+		}
+		fmt.Fprintf(w, "%s を「%s」（%s）の前の設定に戻しました\n", target.NICName, target.Description(), target.Time.Format("2006-01-02 15:04:05"))
+	})
+}
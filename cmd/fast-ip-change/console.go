@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// attachParentProcess は AttachConsole に渡す ATTACH_PARENT_PROCESS の値です
+const attachParentProcess = ^uintptr(0)
+
+// attachConsole は親プロセスのコンソールに標準出力・標準エラー出力を接続します
+// -H windowsgui でビルドした実行ファイルはコンソールを持たないため、
+// コマンドプロンプトから実行したときに結果を表示するために使用します
+// 出力がファイルやパイプにリダイレクトされている場合はそのまま使用します
+func attachConsole() {
+	stdout, _ := windows.GetStdHandle(windows.STD_OUTPUT_HANDLE)
+	stderr, _ := windows.GetStdHandle(windows.STD_ERROR_HANDLE)
+	if isValidHandle(stdout) && isValidHandle(stderr) {
+		return
+	}
+
+	attach := windows.NewLazySystemDLL("kernel32.dll").NewProc("AttachConsole")
+	if ret, _, _ := attach.Call(attachParentProcess); ret == 0 {
+		return
+	}
+
+	conout, err := os.OpenFile("CONOUT$", os.O_WRONLY, 0)
+	if err != nil {
+		return
+	}
+	if !isValidHandle(stdout) {
+		os.Stdout = conout
+	}
+	if !isValidHandle(stderr) {
+		os.Stderr = conout
+	}
+}
+
+func isValidHandle(h windows.Handle) bool {
+	return h != 0 && h != windows.InvalidHandle
+}
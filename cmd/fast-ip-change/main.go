@@ -7,6 +7,7 @@ import (
 
 	"github.com/fast-ip-change/fast-ip-change/internal/config"
 	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/internal/network"
 	"github.com/fast-ip-change/fast-ip-change/internal/systray"
 	"github.com/fast-ip-change/fast-ip-change/internal/utils"
 )
@@ -16,17 +17,65 @@ var (
 )
 
 func main() {
+	// 引数付きで起動された場合はコマンドプロンプトに結果を表示する
+	if len(os.Args) > 1 {
+		attachConsole()
+	}
+
+	// プロファイルが追加したルートを状態ファイルに記録し、切り替え時に削除できるようにする
+	network.DefaultClient().SetRouteStore(config.RouteStore{})
+
+	// 通知の「元に戻す」ボタンから起動された場合は、起動中のシステムトレイに依頼して終了
+	if len(os.Args) > 1 && systray.IsUndoActionURI(os.Args[1]) {
+		if err := systray.RequestUndo(); err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// サブコマンドが指定された場合はコマンドラインモードで実行
+	if len(os.Args) > 1 && isCLICommand(os.Args[1]) {
+		os.Exit(runCLI(os.Args[1:]))
+	}
+
 	// コマンドライン引数の解析
-	var showVersion bool
+	var (
+		showVersion bool
+		planProfile string
+		planDHCP    string
+	)
 	flag.BoolVar(&showVersion, "version", false, "バージョン情報を表示")
 	flag.BoolVar(&showVersion, "v", false, "バージョン情報を表示（短縮形）")
+	flag.StringVar(&planProfile, "plan", "", "プロファイル（名前またはID）を適用した場合の変更内容を表示（設定は変更しない）")
+	flag.StringVar(&planDHCP, "plan-dhcp", "", "指定したNICをDHCPに切り替えた場合の変更内容を表示（設定は変更しない）")
+	flag.Usage = func() {
+		printUsage(os.Stderr)
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "オプション:")
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
+	if flag.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "エラー: 不明なコマンドです: %s\n\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(exitUsage)
+	}
+
 	if showVersion {
 		fmt.Printf("Fast IP Change version %s\n", version)
 		os.Exit(0)
 	}
 
+	// -plan / -plan-dhcp は apply -dry-run / dhcp -dry-run と同じ
+	if planProfile != "" {
+		os.Exit(runCLI([]string{"apply", "-dry-run", planProfile}))
+	}
+	if planDHCP != "" {
+		os.Exit(runCLI([]string{"dhcp", "-dry-run", planDHCP}))
+	}
+
 	// 管理者権限の確認
 	if !utils.IsAdmin() {
 		fmt.Fprintf(os.Stderr, "エラー: このアプリケーションは管理者権限で実行する必要があります。\n")
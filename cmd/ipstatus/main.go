@@ -9,6 +9,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
 	"github.com/lxn/walk"
 	. "github.com/lxn/walk/declarative"
 	"golang.org/x/text/encoding/japanese"
@@ -56,7 +57,7 @@ func (m *StatusModel) Value(row, col int) interface{} {
 	case 2:
 		return item.IPAddress
 	case 3:
-		return item.SubnetMask
+		return models.FormatSubnetMask(item.SubnetMask)
 	case 4:
 		return item.Gateway
 	case 5:
@@ -137,7 +138,7 @@ func main() {
 					{Title: "アダプター名", Width: 150},
 					{Title: "状態", Width: 80},
 					{Title: "IPアドレス", Width: 130},
-					{Title: "サブネットマスク", Width: 130},
+					{Title: "サブネットマスク", Width: 160},
 					{Title: "ゲートウェイ", Width: 130},
 					{Title: "DNS", Width: 150},
 					{Title: "DHCP", Width: 80},
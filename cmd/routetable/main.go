@@ -10,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/fast-ip-change/fast-ip-change/internal/config"
 	"github.com/lxn/walk"
 	. "github.com/lxn/walk/declarative"
 	"golang.org/x/text/encoding/japanese"
@@ -31,6 +32,8 @@ type RouteEntry struct {
 	Interface   string
 	Metric      int
 	MetricStr   string
+	// Owner はこのルートを追加したプロファイル名です（プロファイルのルートでない場合は空）
+	Owner string
 }
 
 // RouteModel はテーブルモデルです
@@ -59,6 +62,8 @@ func (m *RouteModel) Value(row, col int) interface{} {
 		return item.Interface
 	case 4:
 		return item.MetricStr
+	case 5:
+		return item.Owner
 	default:
 		return ""
 	}
@@ -81,6 +86,8 @@ func (m *RouteModel) Sort(col int, order walk.SortOrder) error {
 			less = compareIP(m.items[i].Interface, m.items[j].Interface)
 		case 4:
 			less = m.items[i].Metric < m.items[j].Metric
+		case 5:
+			less = m.items[i].Owner < m.items[j].Owner
 		default:
 			return false
 		}
@@ -116,7 +123,7 @@ func main() {
 
 	err := MainWindow{
 		Title:    "Fast IP Change - ルーティングテーブル",
-		Size:     Size{Width: 900, Height: 500},
+		Size:     Size{Width: 1000, Height: 500},
 		MinSize:  Size{Width: 700, Height: 400},
 		Layout:   VBox{Margins: Margins{Top: 10, Left: 10, Right: 10, Bottom: 10}},
 		AssignTo: &mainWindow,
@@ -146,6 +153,7 @@ func main() {
 					{Title: "ゲートウェイ", Width: 150},
 					{Title: "インターフェース", Width: 150},
 					{Title: "メトリック", Width: 80},
+					{Title: "プロファイル", Width: 120},
 				},
 			},
 			Composite{
@@ -227,9 +235,29 @@ func getRouteEntries() []RouteEntry {
 	}
 
 	entries = parseRouteOutput(output)
+	markRouteOwners(entries)
 	return entries
 }
 
+// markRouteOwners はプロファイルが追加したルートに所有プロファイル名を設定します
+func markRouteOwners(entries []RouteEntry) {
+	state, err := config.LoadState()
+	if err != nil {
+		return
+	}
+
+	owners := make(map[string]string)
+	for _, installed := range state.Routes {
+		for _, route := range installed.Routes {
+			owners[route.Destination+"/"+route.SubnetMask] = installed.Profile
+		}
+	}
+
+	for i := range entries {
+		entries[i].Owner = owners[entries[i].Destination+"/"+entries[i].Netmask]
+	}
+}
+
 func parseRouteOutput(output string) []RouteEntry {
 	var entries []RouteEntry
 
@@ -1,7 +1,13 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/fast-ip-change/fast-ip-change/internal/config"
@@ -15,8 +21,13 @@ var (
 	settingsWindow    *walk.MainWindow
 	profileModel      *ProfileModel
 	nicListBox        *walk.ListBox
-	allNICs           []string
+	allNICs           []network.NIC
 	enabledDHCPNICMap map[string]bool
+	confirmCheck      *walk.CheckBox
+	autoSwitchCheck   *walk.CheckBox
+	conflictCombo     *walk.ComboBox
+	// configRevision は読み込んだ設定ファイルのリビジョンです（他のプロセスによる更新の検出に使用）
+	configRevision int64
 )
 
 // ProfileModel はプロファイルのテーブルモデルです
@@ -33,12 +44,21 @@ func (m *ProfileModel) Value(row, col int) interface{} {
 	profile := m.items[row]
 	switch col {
 	case 0:
-		return profile.Name
+		name := profile.Name
+		if profile.Pinned {
+			name = "★ " + name
+		}
+		if profile.IsManaged() {
+			name += "（管理）"
+		}
+		return name
 	case 1:
-		return profile.IPAddress
+		return profile.Group
 	case 2:
-		return profile.SubnetMask
+		return profile.IPAddress
 	case 3:
+		return models.FormatSubnetMask(profile.SubnetMask)
+	case 4:
 		return profile.NICName
 	default:
 		return ""
@@ -51,8 +71,17 @@ func main() {
 		addBtn    *walk.PushButton
 		editBtn   *walk.PushButton
 		deleteBtn *walk.PushButton
+		upBtn     *walk.PushButton
+		downBtn   *walk.PushButton
 	)
 
+	// システムトレイからテンプレートを適用する場合は、変数の入力のみを行う
+	templateID := flag.String("template", "", "変数の値を入力するテンプレートのID")
+	flag.Parse()
+	if *templateID != "" {
+		os.Exit(promptTemplateValues(*templateID))
+	}
+
 	// 設定を読み込み
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -64,11 +93,13 @@ func main() {
 	profileModel = &ProfileModel{
 		items: cfg.Profiles,
 	}
+	configRevision = cfg.Revision
+	issues := cfg.Validate()
 
 	// NICリストを取得
 	allNICs, err = network.GetNICList()
 	if err != nil {
-		allNICs = []string{}
+		allNICs = []network.NIC{}
 	}
 
 	// DHCP有効NICのマップを初期化
@@ -76,7 +107,7 @@ func main() {
 	if len(cfg.Settings.EnabledDHCPNICs) == 0 {
 		// 未設定の場合は全て有効
 		for _, nic := range allNICs {
-			enabledDHCPNICMap[nic] = true
+			enabledDHCPNICMap[nic.Name] = true
 		}
 	} else {
 		for _, nic := range cfg.Settings.EnabledDHCPNICs {
@@ -100,11 +131,13 @@ func main() {
 				Model:            profileModel,
 				AlternatingRowBG: true,
 				ColumnsOrderable: true,
+				MultiSelection:   true,
 				Columns: []TableViewColumn{
 					{Title: "名前", Width: 150},
-					{Title: "IPアドレス", Width: 150},
+					{Title: "グループ", Width: 100},
+					{Title: "IPアドレス", Width: 120},
 					{Title: "サブネットマスク", Width: 150},
-					{Title: "NIC名", Width: 200},
+					{Title: "NIC名", Width: 150},
 				},
 				OnItemActivated: func() {
 					editProfile(tableView)
@@ -128,7 +161,25 @@ func main() {
 						AssignTo:  &deleteBtn,
 						OnClicked: func() { deleteProfile(tableView) },
 					},
+					PushButton{
+						Text:      "上へ",
+						AssignTo:  &upBtn,
+						OnClicked: func() { moveProfile(tableView, -1) },
+					},
+					PushButton{
+						Text:      "下へ",
+						AssignTo:  &downBtn,
+						OnClicked: func() { moveProfile(tableView, 1) },
+					},
 					HSpacer{},
+					PushButton{
+						Text:      "インポート...",
+						OnClicked: func() { importProfiles() },
+					},
+					PushButton{
+						Text:      "エクスポート...",
+						OnClicked: func() { exportProfiles(tableView) },
+					},
 				},
 			},
 			VSpacer{Size: 10},
@@ -141,7 +192,7 @@ func main() {
 			},
 			ListBox{
 				AssignTo:       &nicListBox,
-				Model:          allNICs,
+				Model:          nicListLabels(allNICs),
 				MultiSelection: true,
 				MinSize:        Size{Height: 100},
 			},
@@ -150,6 +201,33 @@ func main() {
 				Font: Font{PointSize: 8},
 			},
 			VSpacer{Size: 10},
+			Label{
+				Text: "動作設定",
+				Font: Font{Bold: true, PointSize: 10},
+			},
+			CheckBox{
+				AssignTo: &confirmCheck,
+				Text:     "適用前に変更内容（実行するコマンドと差分）を確認する",
+				Checked:  cfg.Settings.ConfirmBeforeApply,
+			},
+			CheckBox{
+				AssignTo: &autoSwitchCheck,
+				Text:     "ネットワークに応じてプロファイルを自動で切り替える（プロファイルの自動切り替えの条件を使用）",
+				Checked:  cfg.Settings.AutoSwitch,
+			},
+			Composite{
+				Layout: HBox{MarginsZero: true},
+				Children: []Widget{
+					Label{Text: "適用するIPアドレスを他のホストが使用している場合:"},
+					ComboBox{
+						AssignTo:     &conflictCombo,
+						Model:        addressConflictOptions,
+						CurrentIndex: slices.Index(models.AddressConflictPolicies, cfg.Settings.AddressConflictPolicy()),
+					},
+					HSpacer{},
+				},
+			},
+			VSpacer{Size: 10},
 			Composite{
 				Layout: HBox{},
 				Children: []Widget{
@@ -174,21 +252,26 @@ func main() {
 		return
 	}
 
-	// テーブルの選択が変更されたときにボタンの有効/無効を切り替え
+	// テーブルの選択が変更されたときにボタンの有効/無効を切り替え（集中管理されたプロファイルは変更不可）
 	tableView.CurrentIndexChanged().Attach(func() {
-		hasSelection := tableView.CurrentIndex() >= 0
-		editBtn.SetEnabled(hasSelection)
-		deleteBtn.SetEnabled(hasSelection)
+		idx := tableView.CurrentIndex()
+		editable := idx >= 0 && !profileModel.items[idx].IsManaged()
+		editBtn.SetEnabled(editable)
+		deleteBtn.SetEnabled(editable)
+		upBtn.SetEnabled(canMoveProfile(idx, -1))
+		downBtn.SetEnabled(canMoveProfile(idx, 1))
 	})
 
 	// 初期状態でボタンを無効化
 	editBtn.SetEnabled(false)
 	deleteBtn.SetEnabled(false)
+	upBtn.SetEnabled(false)
+	downBtn.SetEnabled(false)
 
 	// NICリストの初期選択状態を設定（イベントハンドラ登録前に行う）
 	var initialSelectedIndexes []int
 	for i, nic := range allNICs {
-		if enabledDHCPNICMap[nic] {
+		if enabledDHCPNICMap[nic.Name] {
 			initialSelectedIndexes = append(initialSelectedIndexes, i)
 		}
 	}
@@ -203,7 +286,7 @@ func main() {
 		enabledDHCPNICMap = make(map[string]bool)
 		for _, idx := range selectedIndexes {
 			if idx >= 0 && idx < len(allNICs) {
-				enabledDHCPNICMap[allNICs[idx]] = true
+				enabledDHCPNICMap[allNICs[idx].Name] = true
 			}
 		}
 		// 設定を保存
@@ -212,9 +295,62 @@ func main() {
 		}
 	})
 
+	// 設定ファイルの問題を表示（手で編集した場合など）
+	if len(issues) > 0 {
+		walk.MsgBox(settingsWindow, "警告", "設定ファイルに問題があります。\n\n"+formatIssues(issues), walk.MsgBoxIconWarning)
+	}
+
 	settingsWindow.Run()
 }
 
+// focusIssueField は最初の問題の項目に対応する入力欄にフォーカスを移します
+// fields は項目名（dnsServers[1] の場合は dnsServers）から入力欄への対応です
+func focusIssueField(fields map[string]walk.Window, issues models.ValidationIssues) {
+	for _, issue := range issues {
+		if focusErrorField(fields, issue.Err) {
+			return
+		}
+	}
+}
+
+// focusErrorField はエラーが *models.FieldError の場合、項目に対応する入力欄にフォーカスを移します
+func focusErrorField(fields map[string]walk.Window, err error) bool {
+	var fieldErr *models.FieldError
+	if !errors.As(err, &fieldErr) {
+		return false
+	}
+	// autoSwitch.ssid のような項目は、入力欄がなければ autoSwitch の入力欄に移す
+	name, _, _ := strings.Cut(fieldErr.Field, "[")
+	w, ok := fields[name]
+	if !ok {
+		name, _, _ = strings.Cut(name, ".")
+		w, ok = fields[name]
+	}
+	if ok {
+		w.SetFocus()
+	}
+	return ok
+}
+
+// prefixLengthText はサブネットマスク入力欄の横に表示するプレフィックス長です
+func prefixLengthText(mask string) string {
+	normalized, err := models.ParseSubnetMask(mask)
+	if err != nil {
+		return ""
+	}
+	prefixLength, _ := models.MaskToPrefixLength(normalized)
+	return fmt.Sprintf("/%d", prefixLength)
+}
+
+// formatIssues は検証で見つかった問題を1行ずつの文字列にします
+func formatIssues(issues models.ValidationIssues) string {
+	lines := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		lines = append(lines, issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
 func addProfile() {
 	editProfileDialog(nil)
 }
@@ -225,6 +361,10 @@ func editProfile(tableView *walk.TableView) {
 		return
 	}
 	profile := &profileModel.items[idx]
+	if profile.IsManaged() {
+		showManagedProfileMessage(profile)
+		return
+	}
 	editProfileDialog(profile)
 }
 
@@ -233,6 +373,10 @@ func deleteProfile(tableView *walk.TableView) {
 	if idx < 0 {
 		return
 	}
+	if profile := &profileModel.items[idx]; profile.IsManaged() {
+		showManagedProfileMessage(profile)
+		return
+	}
 
 	result := walk.MsgBox(settingsWindow, "確認", "このプロファイルを削除しますか？", walk.MsgBoxYesNo|walk.MsgBoxIconQuestion)
 	if result != walk.DlgCmdYes {
@@ -250,17 +394,86 @@ func deleteProfile(tableView *walk.TableView) {
 	}
 }
 
+// canMoveProfile は idx のプロファイルを delta だけ移動できるかどうかを返します
+// 集中管理されたプロファイルは常に先頭に表示されるため、移動できません
+func canMoveProfile(idx, delta int) bool {
+	target := idx + delta
+	if idx < 0 || target < 0 || target >= len(profileModel.items) {
+		return false
+	}
+	return !profileModel.items[idx].IsManaged() && !profileModel.items[target].IsManaged()
+}
+
+// moveProfile は選択したプロファイルの表示順を delta だけ移動して保存します
+// システムトレイのメニュー（グループ内を含む）はこの順序で表示されます
+func moveProfile(tableView *walk.TableView, delta int) {
+	idx := tableView.CurrentIndex()
+	if !canMoveProfile(idx, delta) {
+		return
+	}
+	target := idx + delta
+	items := profileModel.items
+	items[idx], items[target] = items[target], items[idx]
+	profileModel.PublishRowsChanged(min(idx, target), max(idx, target))
+
+	if err := saveProfiles(); err != nil {
+		walk.MsgBox(settingsWindow, "エラー", fmt.Sprintf("設定の保存に失敗しました: %v", err), walk.MsgBoxIconError)
+		return
+	}
+	tableView.SetCurrentIndex(target)
+}
+
+// profileGroups はプロファイルで使用しているグループの一覧を返します（入力欄の候補）
+func profileGroups() []string {
+	var groups []string
+	seen := make(map[string]bool)
+	for _, p := range profileModel.items {
+		if p.Group != "" && !seen[p.Group] {
+			seen[p.Group] = true
+			groups = append(groups, p.Group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// showManagedProfileMessage は集中管理されたプロファイルを変更できないことを表示します
+func showManagedProfileMessage(profile *models.Profile) {
+	walk.MsgBox(settingsWindow, "情報",
+		fmt.Sprintf("%s は管理者が配布したプロファイルのため、変更・削除できません。\n\n配布元: %s", profile.Name, profile.ManagedSource),
+		walk.MsgBoxIconInformation)
+}
+
 func editProfileDialog(profile *models.Profile) {
 	var (
-		dlg            *walk.Dialog
-		nameEdit       *walk.LineEdit
-		ipEdit         *walk.LineEdit
-		subnetEdit     *walk.LineEdit
-		gatewayEdit    *walk.LineEdit
-		dnsPrimaryEdit *walk.LineEdit
-		dnsSecEdit     *walk.LineEdit
-		nicCombo       *walk.ComboBox
-		saveBtn        *walk.PushButton
+		dlg           *walk.Dialog
+		nameEdit      *walk.LineEdit
+		ipEdit        *walk.LineEdit
+		subnetEdit    *walk.LineEdit
+		prefixLabel   *walk.Label
+		addressesEdit *walk.TextEdit
+		routesEdit    *walk.TextEdit
+		checksEdit    *walk.TextEdit
+		rollbackCheck *walk.CheckBox
+		metricEdit    *walk.LineEdit
+		mtuEdit       *walk.LineEdit
+		gatewayEdit   *walk.LineEdit
+		dnsEdit       *walk.LineEdit
+		dnsSuffixEdit *walk.LineEdit
+		registerCombo *walk.ComboBox
+		ipv6Edit      *walk.LineEdit
+		ipv6GwEdit    *walk.LineEdit
+		ipv6DNSEdit   *walk.LineEdit
+		nicCombo      *walk.ComboBox
+		nicInfoLabel  *walk.Label
+		groupCombo    *walk.ComboBox
+		pinnedCheck   *walk.CheckBox
+		gwMACEdit     *walk.LineEdit
+		ssidEdit      *walk.LineEdit
+		autoDNSEdit   *walk.LineEdit
+		probeEdit     *walk.LineEdit
+		linkUpEdit    *walk.LineEdit
+		saveBtn       *walk.PushButton
 	)
 
 	isNew := profile == nil
@@ -271,18 +484,23 @@ func editProfileDialog(profile *models.Profile) {
 	// NICリストを取得
 	nics, err := network.GetNICList()
 	if err != nil {
-		nics = []string{"イーサネット", "Wi-Fi"}
+		nics = []network.NIC{{Name: "イーサネット"}, {Name: "Wi-Fi"}}
 	}
 
 	// NICのインデックスを取得
 	nicIndex := 0
 	for i, nic := range nics {
-		if nic == profile.NICName {
+		if nic.Name == profile.NICName {
 			nicIndex = i
 			break
 		}
 	}
 
+	rule := models.AutoSwitchRule{}
+	if profile.AutoSwitch != nil {
+		rule = *profile.AutoSwitch
+	}
+
 	dialogTitle := "プロファイル追加"
 	if !isNew {
 		dialogTitle = "プロファイル編集"
@@ -291,7 +509,7 @@ func editProfileDialog(profile *models.Profile) {
 	err = Dialog{
 		AssignTo: &dlg,
 		Title:    dialogTitle,
-		Size:     Size{Width: 400, Height: 450},
+		Size:     Size{Width: 420, Height: 1010},
 		MinSize:  Size{Width: 350, Height: 400},
 		Layout:   VBox{Margins: Margins{Top: 10, Left: 10, Right: 10, Bottom: 10}},
 		Children: []Widget{
@@ -301,16 +519,53 @@ func editProfileDialog(profile *models.Profile) {
 				Text:     profile.Name,
 			},
 			VSpacer{Size: 5},
-			Label{Text: "IPアドレス:"},
+			Label{Text: "グループ (オプション、「拠点/東京」のように / で区切ると階層):"},
+			ComboBox{
+				AssignTo: &groupCombo,
+				Editable: true,
+				Model:    profileGroups(),
+				Value:    profile.Group,
+			},
+			CheckBox{
+				AssignTo: &pinnedCheck,
+				Text:     "メニューの先頭に表示する（ピン留め）",
+				Checked:  profile.Pinned,
+			},
+			VSpacer{Size: 5},
+			Label{Text: "IPアドレス (「10.0.0.10/24」の形式も可):"},
 			LineEdit{
 				AssignTo: &ipEdit,
 				Text:     profile.IPAddress,
 			},
 			VSpacer{Size: 5},
-			Label{Text: "サブネットマスク:"},
-			LineEdit{
-				AssignTo: &subnetEdit,
-				Text:     profile.SubnetMask,
+			Label{Text: "サブネットマスク (「255.255.255.0」または「24」):"},
+			Composite{
+				Layout: HBox{MarginsZero: true},
+				Children: []Widget{
+					LineEdit{
+						AssignTo: &subnetEdit,
+						Text:     profile.SubnetMask,
+						OnTextChanged: func() {
+							// 作成中（ラベルの作成前）にも呼ばれるため確認する
+							if prefixLabel != nil {
+								prefixLabel.SetText(prefixLengthText(subnetEdit.Text()))
+							}
+						},
+					},
+					Label{
+						AssignTo: &prefixLabel,
+						Text:     prefixLengthText(profile.SubnetMask),
+						MinSize:  Size{Width: 30},
+					},
+				},
+			},
+			VSpacer{Size: 5},
+			Label{Text: "追加アドレス (オプション、1行に「IP/マスク」):"},
+			TextEdit{
+				AssignTo: &addressesEdit,
+				Text:     formatAddresses(profile.AdditionalAddresses),
+				VScroll:  true,
+				MinSize:  Size{Height: 50},
 			},
 			VSpacer{Size: 5},
 			Label{Text: "デフォルトゲートウェイ (オプション):"},
@@ -319,24 +574,132 @@ func editProfileDialog(profile *models.Profile) {
 				Text:     profile.Gateway,
 			},
 			VSpacer{Size: 5},
-			Label{Text: "優先DNSサーバー (オプション):"},
+			Label{Text: "DNSサーバー (オプション、カンマ区切りで優先順):"},
+			LineEdit{
+				AssignTo: &dnsEdit,
+				Text:     strings.Join(profile.DNSServers, ", "),
+			},
+			VSpacer{Size: 5},
+			Label{Text: "DNSサフィックス (オプション):"},
+			LineEdit{
+				AssignTo: &dnsSuffixEdit,
+				Text:     profile.DNSSuffix,
+			},
+			VSpacer{Size: 5},
+			Label{Text: "DNSへの登録:"},
+			ComboBox{
+				AssignTo:     &registerCombo,
+				Model:        registerDNSOptions,
+				CurrentIndex: registerDNSIndex(profile.RegisterDNS),
+			},
+			VSpacer{Size: 5},
+			Label{Text: "静的ルート (オプション、1行に「宛先/マスク ゲートウェイ メトリック」):"},
+			TextEdit{
+				AssignTo: &routesEdit,
+				Text:     formatRoutes(profile.Routes),
+				VScroll:  true,
+				MinSize:  Size{Height: 50},
+			},
+			VSpacer{Size: 5},
+			Composite{
+				Layout: Grid{Columns: 2, MarginsZero: true},
+				Children: []Widget{
+					Label{Text: "インターフェイスメトリック (automatic または数値):"},
+					Label{Text: "MTU (オプション):"},
+					LineEdit{
+						AssignTo: &metricEdit,
+						Text:     profile.InterfaceMetric,
+					},
+					LineEdit{
+						AssignTo: &mtuEdit,
+						Text:     formatOptionalInt(profile.MTU),
+					},
+				},
+			},
+			VSpacer{Size: 5},
+			Label{Text: "IPv6アドレス/プレフィックス長 (オプション):"},
+			LineEdit{
+				AssignTo: &ipv6Edit,
+				Text:     profile.IPv6CIDR(),
+			},
+			VSpacer{Size: 5},
+			Label{Text: "IPv6ゲートウェイ (オプション):"},
 			LineEdit{
-				AssignTo: &dnsPrimaryEdit,
-				Text:     profile.DNSPrimary,
+				AssignTo: &ipv6GwEdit,
+				Text:     profile.IPv6Gateway,
 			},
 			VSpacer{Size: 5},
-			Label{Text: "代替DNSサーバー (オプション):"},
+			Label{Text: "IPv6 DNSサーバー (オプション、カンマ区切り):"},
 			LineEdit{
-				AssignTo: &dnsSecEdit,
-				Text:     profile.DNSSecondary,
+				AssignTo: &ipv6DNSEdit,
+				Text:     strings.Join(profile.IPv6DNSServers, ", "),
 			},
 			VSpacer{Size: 5},
 			Label{Text: "NIC名:"},
 			ComboBox{
 				AssignTo:     &nicCombo,
 				Editable:     true,
-				Model:        nics,
+				Model:        network.NICNames(nics),
 				CurrentIndex: nicIndex,
+				OnCurrentIndexChanged: func() {
+					nicInfoLabel.SetText(nicInfo(nics, nicCombo.CurrentIndex()))
+				},
+			},
+			Label{
+				AssignTo: &nicInfoLabel,
+				Text:     nicInfo(nics, nicIndex),
+			},
+			VSpacer{Size: 5},
+			Label{Text: "適用後の疎通確認 (オプション、1行に「gateway [IP]」「dns ホスト名」「tcp ホスト:ポート」と秒数):"},
+			TextEdit{
+				AssignTo: &checksEdit,
+				Text:     formatHealthChecks(profile.HealthChecks),
+				VScroll:  true,
+				MinSize:  Size{Height: 50},
+			},
+			CheckBox{
+				AssignTo: &rollbackCheck,
+				Text:     "疎通確認に失敗した場合は変更前の設定に戻す",
+				Checked:  profile.RollbackOnHealthCheckFailure,
+			},
+			VSpacer{Size: 5},
+			Label{Text: "自動切り替えの条件 (オプション、指定した条件をすべて満たすネットワークで自動適用):"},
+			Composite{
+				Layout: Grid{Columns: 2, MarginsZero: true},
+				Children: []Widget{
+					Label{Text: "ゲートウェイのMACアドレス:"},
+					Label{Text: "SSID:"},
+					LineEdit{
+						AssignTo: &gwMACEdit,
+						Text:     rule.GatewayMAC,
+					},
+					LineEdit{
+						AssignTo: &ssidEdit,
+						Text:     rule.SSID,
+					},
+					Label{Text: "DNSサフィックス:"},
+					Label{Text: "応答を確認するホスト:"},
+					LineEdit{
+						AssignTo: &autoDNSEdit,
+						Text:     rule.DNSSuffix,
+					},
+					LineEdit{
+						AssignTo: &probeEdit,
+						Text:     rule.ProbeHost,
+					},
+					Label{Text: "リンクアップを確認するNIC:"},
+					HSpacer{},
+					LineEdit{
+						AssignTo: &linkUpEdit,
+						Text:     rule.LinkUpNIC,
+					},
+					PushButton{
+						Text: "現在のネットワークから取得",
+						OnClicked: func() {
+							detectNetwork(dlg, strings.TrimSpace(nicCombo.Text()), gwMACEdit, autoDNSEdit, ssidEdit)
+						},
+					},
+				},
 			},
 			VSpacer{Size: 10},
 			Composite{
@@ -349,18 +712,114 @@ func editProfileDialog(profile *models.Profile) {
 						OnClicked: func() {
 							// 入力値を取得
 							profile.Name = strings.TrimSpace(nameEdit.Text())
+							profile.Group = groupCombo.Text()
+							profile.Pinned = pinnedCheck.Checked()
 							profile.IPAddress = strings.TrimSpace(ipEdit.Text())
 							profile.SubnetMask = strings.TrimSpace(subnetEdit.Text())
 							profile.Gateway = strings.TrimSpace(gatewayEdit.Text())
-							profile.DNSPrimary = strings.TrimSpace(dnsPrimaryEdit.Text())
-							profile.DNSSecondary = strings.TrimSpace(dnsSecEdit.Text())
+							profile.DNSServers = splitList(dnsEdit.Text())
+							profile.DNSSuffix = strings.TrimSpace(dnsSuffixEdit.Text())
+							profile.RegisterDNS = registerDNSValue(registerCombo.CurrentIndex())
 							profile.NICName = strings.TrimSpace(nicCombo.Text())
 
-							// バリデーション
-							if err := profile.Validate(); err != nil {
+							addresses, err := parseAddresses(addressesEdit.Text())
+							if err != nil {
+								walk.MsgBox(dlg, "エラー", fmt.Sprintf("追加アドレスが不正です: %v", err), walk.MsgBoxIconError)
+								return
+							}
+							profile.AdditionalAddresses = addresses
+
+							routes, err := parseRoutes(routesEdit.Text())
+							if err != nil {
+								walk.MsgBox(dlg, "エラー", fmt.Sprintf("静的ルートが不正です: %v", err), walk.MsgBoxIconError)
+								return
+							}
+							profile.Routes = routes
+
+							checks, err := parseHealthChecks(checksEdit.Text())
+							if err != nil {
+								walk.MsgBox(dlg, "エラー", fmt.Sprintf("疎通確認が不正です: %v", err), walk.MsgBoxIconError)
+								return
+							}
+							profile.HealthChecks = checks
+							profile.RollbackOnHealthCheckFailure = rollbackCheck.Checked()
+
+							profile.InterfaceMetric = strings.ToLower(strings.TrimSpace(metricEdit.Text()))
+							profile.MTU = 0
+							if text := strings.TrimSpace(mtuEdit.Text()); text != "" {
+								mtu, err := strconv.Atoi(text)
+								if err != nil {
+									walk.MsgBox(dlg, "エラー", fmt.Sprintf("MTUが不正です: %s", text), walk.MsgBoxIconError)
+									return
+								}
+								profile.MTU = mtu
+							}
+
+							profile.IPv6Address, profile.IPv6PrefixLength = "", 0
+							if text := strings.TrimSpace(ipv6Edit.Text()); text != "" {
+								addr, length, err := models.ParseIPv6CIDR(text)
+								if err != nil {
+									walk.MsgBox(dlg, "エラー", fmt.Sprintf("入力値が不正です: %v", err), walk.MsgBoxIconError)
+									return
+								}
+								profile.IPv6Address, profile.IPv6PrefixLength = addr, length
+							}
+							profile.IPv6Gateway = strings.TrimSpace(ipv6GwEdit.Text())
+							profile.IPv6DNSServers = splitList(ipv6DNSEdit.Text())
+							// 条件がない場合は Normalize で nil になる
+							profile.AutoSwitch = &models.AutoSwitchRule{
+								GatewayMAC: gwMACEdit.Text(),
+								SSID:       ssidEdit.Text(),
+								DNSSuffix:  autoDNSEdit.Text(),
+								ProbeHost:  probeEdit.Text(),
+								LinkUpNIC:  linkUpEdit.Text(),
+							}
+
+							// バリデーション（エラーの項目の入力欄にフォーカスを移す）
+							fields := map[string]walk.Window{
+								"name":                  nameEdit,
+								"group":                 groupCombo,
+								"ipAddress":             ipEdit,
+								"subnetMask":            subnetEdit,
+								"additionalAddresses":   addressesEdit,
+								"routes":                routesEdit,
+								"healthChecks":          checksEdit,
+								"interfaceMetric":       metricEdit,
+								"mtu":                   mtuEdit,
+								"gateway":               gatewayEdit,
+								"dnsServers":            dnsEdit,
+								"dnsSuffix":             dnsSuffixEdit,
+								"ipv6Address":           ipv6Edit,
+								"ipv6PrefixLength":      ipv6Edit,
+								"ipv6Gateway":           ipv6GwEdit,
+								"ipv6DnsServers":        ipv6DNSEdit,
+								"nicName":               nicCombo,
+								"autoSwitch":            gwMACEdit,
+								"autoSwitch.gatewayMac": gwMACEdit,
+								"autoSwitch.ssid":       ssidEdit,
+								"autoSwitch.dnsSuffix":  autoDNSEdit,
+								"autoSwitch.probeHost":  probeEdit,
+								"autoSwitch.linkUpNic":  linkUpEdit,
+							}
+							// CIDR表記・プレフィックス長をサブネットマスクに変換
+							if err := profile.Normalize(); err != nil {
 								walk.MsgBox(dlg, "エラー", fmt.Sprintf("入力値が不正です: %v", err), walk.MsgBoxIconError)
+								focusErrorField(fields, err)
 								return
 							}
+							issues := profile.ValidateAll()
+							if issues.HasErrors() {
+								walk.MsgBox(dlg, "エラー", "入力値が不正です。\n\n"+formatIssues(issues), walk.MsgBoxIconError)
+								focusIssueField(fields, issues)
+								return
+							}
+							if len(issues) > 0 {
+								result := walk.MsgBox(dlg, "確認", "以下の警告があります。保存しますか？\n\n"+formatIssues(issues), walk.MsgBoxYesNo|walk.MsgBoxIconWarning)
+								if result != walk.DlgCmdYes {
+									focusIssueField(fields, issues)
+									return
+								}
+							}
 
 							// 新規の場合は追加、既存の場合は更新
 							if isNew {
@@ -404,6 +863,181 @@ func editProfileDialog(profile *models.Profile) {
 	dlg.Run()
 }
 
+// detectNetwork はNICが現在接続しているネットワークのゲートウェイのMACアドレス・DNSサフィックス・SSIDを入力欄に設定します
+func detectNetwork(owner walk.Form, nicName string, gwMACEdit, dnsSuffixEdit, ssidEdit *walk.LineEdit) {
+	env, err := network.GetEnvironment(nicName)
+	if err != nil {
+		walk.MsgBox(owner, "エラー", fmt.Sprintf("ネットワークの情報を取得できませんでした: %v", err), walk.MsgBoxIconError)
+		return
+	}
+	if !env.LinkUp {
+		walk.MsgBox(owner, "情報", fmt.Sprintf("%s は接続されていません", nicName), walk.MsgBoxIconInformation)
+		return
+	}
+
+	gwMACEdit.SetText(env.GatewayMAC)
+	dnsSuffixEdit.SetText(env.DNSSuffix)
+	// 無線NICの場合のみ（有線NICのプロファイルでは他の無線NICのSSIDを条件にできるため変更しない）
+	if ssids, err := network.GetWirelessSSIDs(); err == nil {
+		if ssid, ok := ssids[nicName]; ok {
+			ssidEdit.SetText(ssid)
+		}
+	}
+}
+
+// formatAddresses は追加アドレスを1行1件のテキストに変換します
+// nicListLabels はDHCP表示設定の一覧に表示するNICのラベルを返します（例: "Wi-Fi - Intel(R) Wi-Fi 6 AX201（切断）"）
+func nicListLabels(nics []network.NIC) []string {
+	labels := make([]string, len(nics))
+	for i, nic := range nics {
+		label := nic.Name
+		if nic.Description != "" {
+			label += " - " + nic.Description
+		}
+		if !nic.Up() {
+			label += "（" + nic.StateString() + "）"
+		}
+		labels[i] = label
+	}
+	return labels
+}
+
+// nicInfo は指定したインデックスのNICの説明・MACアドレス・状態を返します（一覧にないNICの場合は空文字列）
+func nicInfo(nics []network.NIC, index int) string {
+	if index < 0 || index >= len(nics) || nics[index].AdminState == "" {
+		return ""
+	}
+	nic := nics[index]
+	var parts []string
+	if nic.Description != "" {
+		parts = append(parts, nic.Description)
+	}
+	if nic.MAC != "" {
+		parts = append(parts, nic.MAC)
+	}
+	parts = append(parts, nic.StateString())
+	return strings.Join(parts, " / ")
+}
+
+func formatAddresses(entries []models.IPAddressEntry) string {
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = entry.String()
+	}
+	return strings.Join(lines, "\r\n")
+}
+
+// parseAddresses は1行1件のテキストから追加アドレスを読み取ります
+func parseAddresses(text string) ([]models.IPAddressEntry, error) {
+	var entries []models.IPAddressEntry
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		entry, err := models.ParseIPAddressEntry(line)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// formatOptionalInt は 0 を空文字列として数値を表示用の文字列に変換します
+func formatOptionalInt(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
+// formatHealthChecks は疎通確認を1行1件のテキストに変換します
+func formatHealthChecks(checks []models.HealthCheck) string {
+	lines := make([]string, len(checks))
+	for i, check := range checks {
+		lines[i] = check.Format()
+	}
+	return strings.Join(lines, "\r\n")
+}
+
+// parseHealthChecks は1行1件のテキストから疎通確認を読み取ります
+func parseHealthChecks(text string) ([]models.HealthCheck, error) {
+	var checks []models.HealthCheck
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		check, err := models.ParseHealthCheck(line)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// formatRoutes は静的ルートを1行1件のテキストに変換します
+func formatRoutes(routes []models.StaticRoute) string {
+	lines := make([]string, len(routes))
+	for i, route := range routes {
+		lines[i] = route.Format()
+	}
+	return strings.Join(lines, "\r\n")
+}
+
+// parseRoutes は1行1件のテキストから静的ルートを読み取ります
+func parseRoutes(text string) ([]models.StaticRoute, error) {
+	var routes []models.StaticRoute
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		route, err := models.ParseStaticRoute(line)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// addressConflictOptions はIPアドレスの重複時の動作の選択肢です（models.AddressConflictPolicies の順）
+var addressConflictOptions = []string{"適用を中止する", "警告して適用する", "確認しない"}
+
+// registerDNSOptions はDNS登録の選択肢です（Profile.RegisterDNS の nil / true / false に対応）
+var registerDNSOptions = []string{"変更しない", "登録する", "登録しない"}
+
+// registerDNSIndex は RegisterDNS の値に対応する選択肢のインデックスを返します
+func registerDNSIndex(register *bool) int {
+	switch {
+	case register == nil:
+		return 0
+	case *register:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// registerDNSValue は選択肢のインデックスに対応する RegisterDNS の値を返します
+func registerDNSValue(index int) *bool {
+	if index <= 0 {
+		return nil
+	}
+	register := index == 1
+	return &register
+}
+
+// splitList はカンマまたは空白区切りのテキストを値の一覧に分割します
+func splitList(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	})
+}
+
 func saveProfiles() error {
 	return saveConfig()
 }
@@ -426,5 +1060,38 @@ func saveConfig() error {
 	}
 	cfg.Settings.EnabledDHCPNICs = enabledNICs
 
-	return config.SaveConfig(cfg)
+	// 動作設定を保存
+	cfg.Settings.ConfirmBeforeApply = confirmCheck.Checked()
+	cfg.Settings.AutoSwitch = autoSwitchCheck.Checked()
+	if i := conflictCombo.CurrentIndex(); i >= 0 {
+		cfg.Settings.AddressConflict = models.AddressConflictPolicies[i]
+	}
+
+	// 設定全体を検証（プロファイル名の重複など）
+	if issues := cfg.Validate(); issues.HasErrors() {
+		return fmt.Errorf("設定にエラーがあるため保存できません\n\n%s", formatIssues(issues))
+	}
+
+	// 画面を開いた後に他のプロセスが保存した変更を上書きしないよう、読み込んだ時点のリビジョンで保存する
+	cfg.Revision = configRevision
+	if err := config.SaveConfig(cfg); err != nil {
+		if errors.Is(err, config.ErrConflict) {
+			reloadProfiles()
+			return fmt.Errorf("%w\n最新の設定を読み込み直しました。変更をやり直してください", err)
+		}
+		return err
+	}
+	configRevision = cfg.Revision
+	return nil
+}
+
+// reloadProfiles は設定ファイルからプロファイル一覧を読み込み直します
+func reloadProfiles() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return
+	}
+	profileModel.items = cfg.Profiles
+	profileModel.PublishRowsReset()
+	configRevision = cfg.Revision
 }
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/config"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+	"github.com/lxn/walk"
+	. "github.com/lxn/walk/declarative"
+)
+
+// promptTemplateValues はテンプレートの変数の値を入力するダイアログを表示します
+// システムトレイからテンプレートを適用するときに「settings.exe -template <ID>」で起動され、
+// 入力された値を JSON（変数名と値の対応）で標準出力に書き込みます
+// 戻り値はプロセスの終了コードです（0: 入力完了、1: キャンセルまたはエラー）
+func promptTemplateValues(templateID string) int {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		walk.MsgBox(nil, "エラー", fmt.Sprintf("設定の読み込みに失敗: %v", err), walk.MsgBoxIconError)
+		return 1
+	}
+	template := cfg.FindTemplate(templateID)
+	if template == nil {
+		walk.MsgBox(nil, "エラー", fmt.Sprintf("テンプレートが見つかりません: %s", templateID), walk.MsgBoxIconError)
+		return 1
+	}
+
+	var (
+		dlg          *walk.Dialog
+		previewLabel *walk.Label
+		okBtn        *walk.PushButton
+	)
+	edits := make([]*walk.LineEdit, len(template.Variables))
+	fields := make(map[string]walk.Window, len(template.Variables))
+
+	values := func() map[string]string {
+		result := make(map[string]string, len(edits))
+		for i, edit := range edits {
+			if edit != nil {
+				result[template.Variables[i].Name] = edit.Text()
+			}
+		}
+		return result
+	}
+
+	// 入力中の値で作成されるプロファイルを表示する
+	updatePreview := func() {
+		if previewLabel == nil {
+			return
+		}
+		profile, err := template.Expand(values())
+		if err != nil {
+			previewLabel.SetText(err.Error())
+			return
+		}
+		previewLabel.SetText(fmt.Sprintf("%s: %s / %s [%s]", profile.Name, profile.IPAddress, models.FormatSubnetMask(profile.SubnetMask), profile.NICName))
+	}
+
+	children := []Widget{}
+	for i := range template.Variables {
+		v := &template.Variables[i]
+		children = append(children,
+			Label{Text: v.DisplayName() + ":"},
+			LineEdit{
+				AssignTo:      &edits[i],
+				Text:          v.Default,
+				OnTextChanged: func() { updatePreview() },
+			},
+			VSpacer{Size: 5},
+		)
+	}
+	children = append(children,
+		Label{AssignTo: &previewLabel},
+		VSpacer{},
+		Composite{
+			Layout: HBox{},
+			Children: []Widget{
+				HSpacer{},
+				PushButton{
+					AssignTo: &okBtn,
+					Text:     "適用",
+					OnClicked: func() {
+						if _, err := template.Expand(values()); err != nil {
+							walk.MsgBox(dlg, "入力エラー", err.Error(), walk.MsgBoxIconError)
+							focusErrorField(fields, err)
+							return
+						}
+						dlg.Accept()
+					},
+				},
+				PushButton{
+					Text:      "キャンセル",
+					OnClicked: func() { dlg.Cancel() },
+				},
+			},
+		},
+	)
+
+	err = Dialog{
+		AssignTo:      &dlg,
+		Title:         fmt.Sprintf("テンプレートの適用 - %s", template.Name),
+		DefaultButton: &okBtn,
+		MinSize:       Size{Width: 360, Height: 200},
+		Layout:        VBox{Margins: Margins{Top: 10, Left: 10, Right: 10, Bottom: 10}},
+		Children:      children,
+	}.Create(nil)
+	if err != nil {
+		walk.MsgBox(nil, "エラー", fmt.Sprintf("ダイアログの作成に失敗: %v", err), walk.MsgBoxIconError)
+		return 1
+	}
+	for i, edit := range edits {
+		fields[template.Variables[i].Name] = edit
+	}
+	updatePreview()
+
+	if dlg.Run() != walk.DlgCmdOK {
+		return 1
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(values()); err != nil {
+		return 1
+	}
+	return 0
+}
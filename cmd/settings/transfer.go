@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/config"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+	"github.com/lxn/walk"
+	. "github.com/lxn/walk/declarative"
+)
+
+// transferFileFilter はエクスポート・インポートのファイル選択ダイアログのフィルタです
+const transferFileFilter = "JSON ファイル (*.json)|*.json|CSV ファイル (*.csv)|*.csv"
+
+// conflictPolicyLabels は重複時の扱いの選択肢です（config.ConflictPolicies と同じ順序）
+var conflictPolicyLabels = []string{"スキップする", "上書きする", "名前を変えて追加する"}
+
+// exportProfiles は選択したプロファイル、または設定全体をファイルに出力します
+func exportProfiles(tableView *walk.TableView) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		walk.MsgBox(settingsWindow, "エラー", fmt.Sprintf("設定の読み込みに失敗: %v", err), walk.MsgBoxIconError)
+		return
+	}
+
+	// 一部のプロファイルを選択している場合は、選択したものだけを出力するか確認する
+	var keys []string
+	includeSettings := true
+	if indexes := tableView.SelectedIndexes(); len(indexes) > 0 && len(indexes) < len(profileModel.items) {
+		message := fmt.Sprintf("選択した %d 件のプロファイルのみをエクスポートしますか？\n「いいえ」を選ぶと設定全体をエクスポートします。", len(indexes))
+		switch walk.MsgBox(settingsWindow, "エクスポート", message, walk.MsgBoxYesNoCancel|walk.MsgBoxIconQuestion) {
+		case walk.DlgCmdCancel:
+			return
+		case walk.DlgCmdYes:
+			for _, idx := range indexes {
+				keys = append(keys, profileModel.items[idx].ID)
+			}
+			includeSettings = false
+		}
+	}
+
+	fd := &walk.FileDialog{
+		Title:    "プロファイルのエクスポート",
+		Filter:   transferFileFilter,
+		FilePath: "fast-ip-change-profiles.json",
+	}
+	ok, err := fd.ShowSave(settingsWindow)
+	if err != nil || !ok {
+		return
+	}
+	path := fd.FilePath
+	if filepath.Ext(path) == "" {
+		// 拡張子が入力されていない場合は選択した種類の拡張子を付ける
+		if fd.FilterIndex == 2 {
+			path += ".csv"
+		} else {
+			path += ".json"
+		}
+	}
+
+	doc, err := config.NewExportDocument(cfg, keys, includeSettings)
+	if err == nil {
+		err = config.WriteExportFile(path, doc)
+	}
+	if err != nil {
+		walk.MsgBox(settingsWindow, "エラー", fmt.Sprintf("エクスポートに失敗しました: %v", err), walk.MsgBoxIconError)
+		return
+	}
+
+	message := fmt.Sprintf("%d 件のプロファイルを %s に出力しました。", len(doc.Profiles), path)
+	if includeSettings && config.IsCSVPath(path) {
+		message += "\nCSVには動作設定は含まれません。"
+	}
+	walk.MsgBox(settingsWindow, "情報", message, walk.MsgBoxIconInformation)
+}
+
+// importProfiles はファイルを選択し、取り込む内容を確認するダイアログを表示します
+func importProfiles() {
+	fd := &walk.FileDialog{
+		Title:  "プロファイルのインポート",
+		Filter: transferFileFilter,
+	}
+	ok, err := fd.ShowOpen(settingsWindow)
+	if err != nil || !ok {
+		return
+	}
+
+	doc, err := config.ReadImportFile(fd.FilePath)
+	if err != nil {
+		walk.MsgBox(settingsWindow, "エラー", fmt.Sprintf("ファイルの読み込みに失敗しました: %v", err), walk.MsgBoxIconError)
+		return
+	}
+	importDialog(fd.FilePath, doc)
+}
+
+// importDialog は重複時の扱いを選択し、変更内容を確認してからインポートするダイアログです
+func importDialog(path string, doc *config.ExportDocument) {
+	var (
+		dlg           *walk.Dialog
+		conflictCombo *walk.ComboBox
+		newIDsCheck   *walk.CheckBox
+		settingsCheck *walk.CheckBox
+		previewEdit   *walk.TextEdit
+		importBtn     *walk.PushButton
+	)
+
+	options := func() config.ImportOptions {
+		opts := config.ImportOptions{
+			OnConflict:     config.ConflictSkip,
+			RegenerateIDs:  newIDsCheck.Checked(),
+			ImportSettings: settingsCheck.Checked(),
+		}
+		if idx := conflictCombo.CurrentIndex(); idx >= 0 && idx < len(config.ConflictPolicies) {
+			opts.OnConflict = config.ConflictPolicies[idx]
+		}
+		return opts
+	}
+
+	// 選択に応じて変更内容を表示し、エラーがある場合はインポートできないようにする
+	updatePreview := func() {
+		if previewEdit == nil || importBtn == nil {
+			return
+		}
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			previewEdit.SetText(fmt.Sprintf("設定の読み込みに失敗: %v", err))
+			importBtn.SetEnabled(false)
+			return
+		}
+		plan, err := config.PlanImport(cfg, doc, options())
+		if err != nil {
+			previewEdit.SetText(err.Error())
+			importBtn.SetEnabled(false)
+			return
+		}
+		previewEdit.SetText(strings.ReplaceAll(plan.String(), "\n", "\r\n"))
+		importBtn.SetEnabled(!plan.Issues.HasErrors())
+	}
+
+	err := Dialog{
+		AssignTo: &dlg,
+		Title:    "プロファイルのインポート",
+		Size:     Size{Width: 520, Height: 480},
+		MinSize:  Size{Width: 400, Height: 360},
+		Layout:   VBox{Margins: Margins{Top: 10, Left: 10, Right: 10, Bottom: 10}},
+		Children: []Widget{
+			Label{Text: fmt.Sprintf("%s（%d 件）", filepath.Base(path), len(doc.Profiles))},
+			VSpacer{Size: 5},
+			Label{Text: "同じIDまたは名前のプロファイルがある場合:"},
+			ComboBox{
+				AssignTo:              &conflictCombo,
+				Model:                 conflictPolicyLabels,
+				CurrentIndex:          0,
+				OnCurrentIndexChanged: func() { updatePreview() },
+			},
+			CheckBox{
+				AssignTo:         &newIDsCheck,
+				Text:             "すべてのプロファイルに新しいIDを発行する（重複は名前のみで判定）",
+				OnCheckedChanged: func() { updatePreview() },
+			},
+			CheckBox{
+				AssignTo:         &settingsCheck,
+				Text:             "ファイルに含まれる動作設定も取り込む",
+				Enabled:          doc.Settings != nil,
+				OnCheckedChanged: func() { updatePreview() },
+			},
+			VSpacer{Size: 5},
+			Label{Text: "変更内容:"},
+			TextEdit{
+				AssignTo: &previewEdit,
+				ReadOnly: true,
+				VScroll:  true,
+			},
+			Composite{
+				Layout: HBox{},
+				Children: []Widget{
+					HSpacer{},
+					PushButton{
+						AssignTo: &importBtn,
+						Text:     "インポート",
+						OnClicked: func() {
+							plan, err := config.Import(doc, options())
+							var validationErr *models.ValidationError
+							if errors.As(err, &validationErr) {
+								// 確認後に他のプロセスが設定を変更した場合など
+								updatePreview()
+								walk.MsgBox(dlg, "エラー", "エラーがあるためインポートできません。変更内容を確認してください。", walk.MsgBoxIconError)
+								return
+							}
+							if err != nil {
+								walk.MsgBox(dlg, "エラー", fmt.Sprintf("インポートに失敗しました: %v", err), walk.MsgBoxIconError)
+								return
+							}
+
+							reloadProfiles()
+							walk.MsgBox(dlg, "成功", fmt.Sprintf("インポートしました。\n追加 %d 件、上書き %d 件、スキップ %d 件",
+								plan.Count(config.ImportAdd)+plan.Count(config.ImportRename), plan.Count(config.ImportOverwrite), plan.Count(config.ImportSkip)),
+								walk.MsgBoxIconInformation)
+							dlg.Accept()
+						},
+					},
+					PushButton{
+						Text: "キャンセル",
+						OnClicked: func() {
+							dlg.Cancel()
+						},
+					},
+				},
+			},
+		},
+	}.Create(settingsWindow)
+	if err != nil {
+		walk.MsgBox(settingsWindow, "エラー", fmt.Sprintf("ダイアログの作成に失敗: %v", err), walk.MsgBoxIconError)
+		return
+	}
+
+	updatePreview()
+	dlg.Run()
+}
@@ -6,6 +6,7 @@ require (
 	github.com/getlantern/systray v1.2.2
 	github.com/go-toast/toast v0.0.0-20190211030409-01e6764cf0a4
 	github.com/google/uuid v1.6.0
+	golang.org/x/sys v0.20.0
 )
 
 require (
@@ -20,7 +21,6 @@ require (
 	github.com/lxn/win v0.0.0-20210218163916-a377121e959e // indirect
 	github.com/nu7hatch/gouuid v0.0.0-20131221200532-179d4d0c4d8d // indirect
 	github.com/oxtoacart/bpool v0.0.0-20190530202638-03653db5a59c // indirect
-	golang.org/x/sys v0.20.0 // indirect
 	golang.org/x/text v0.33.0 // indirect
 	gopkg.in/Knetic/govaluate.v3 v3.0.0 // indirect
 )
@@ -0,0 +1,242 @@
+// Package autoswitch はネットワーク環境を監視し、条件（models.AutoSwitchRule）に一致したプロファイルを自動で適用します
+package autoswitch
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/internal/network"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// PollInterval はネットワーク環境を確認する間隔です
+const PollInterval = 5 * time.Second
+
+// Match は条件に一致したプロファイルです
+type Match struct {
+	Profile *models.Profile
+	// Reason は一致した条件を表示用の文字列にしたものです
+	Reason string
+}
+
+// Watcher はネットワーク環境を定期的に確認し、条件に一致したプロファイルを適用します
+//
+//   - 同じプロファイルが Settings.AutoSwitchDebounce の間続けて一致した場合に適用します（接続直後の揺らぎを無視）
+//   - 切り替えた後（手動の切り替えを含む）は Settings.AutoSwitchCooldown の間、自動で切り替えません
+//   - 適用したプロファイルや、一致中に手動で切り替えた場合は、ネットワーク環境が変わるまで再び適用しません
+type Watcher struct {
+	client *network.Client
+	config func() *models.Config
+	apply  func(Match) error
+
+	mu         sync.Mutex
+	candidate  string    // 一致しているプロファイルのID
+	since      time.Time // candidate が一致し始めた時刻
+	lastSwitch time.Time // 最後に切り替えた時刻
+	ignored    string    // ネットワーク環境が変わるまで適用しないプロファイルのID
+}
+
+// NewWatcher は Watcher を作成します
+// config は現在の設定を返す関数、apply はプロファイルを適用する関数です（通知と履歴の記録を含む）
+func NewWatcher(client *network.Client, config func() *models.Config, apply func(Match) error) *Watcher {
+	return &Watcher{client: client, config: config, apply: apply}
+}
+
+// Run は stop が閉じられるまで PollInterval ごとに Check を呼び出します
+func (w *Watcher) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			w.Check(now)
+		}
+	}
+}
+
+// Check はネットワーク環境を確認し、必要な場合はプロファイルを適用します
+func (w *Watcher) Check(now time.Time) {
+	cfg := w.config()
+	if cfg == nil || !cfg.Settings.AutoSwitch {
+		w.mu.Lock()
+		w.candidate, w.ignored = "", ""
+		w.mu.Unlock()
+		return
+	}
+
+	match := Evaluate(w.client, cfg)
+	if !w.ready(match, now, cfg.Settings) {
+		return
+	}
+
+	// 既に同じ設定の場合は適用しない（手動で適用済みの場合など）
+	if plan, err := w.client.PlanProfile(match.Profile); err == nil && !plan.HasChanges() {
+		logger.Debug("自動切り替えの対象は適用済みです", "profile", match.Profile.Name)
+		w.mu.Lock()
+		w.ignored = match.Profile.ID
+		w.mu.Unlock()
+		return
+	}
+
+	logger.Info("ネットワーク環境が条件に一致したため、プロファイルを自動で適用します", "profile", match.Profile.Name, "reason", match.Reason)
+	w.mu.Lock()
+	w.lastSwitch = now
+	w.mu.Unlock()
+	if err := w.apply(*match); err != nil {
+		// 失敗した場合は間隔（AutoSwitchCooldown）の後に再試行する
+		logger.Error("プロファイルの自動適用に失敗", err, "profile", match.Profile.Name)
+		return
+	}
+
+	w.mu.Lock()
+	w.ignored = match.Profile.ID
+	w.mu.Unlock()
+}
+
+// ready は一致したプロファイルを適用する条件（待ち時間・間隔）を満たしたかどうかを返します
+func (w *Watcher) ready(match *Match, now time.Time, settings models.Settings) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if match == nil {
+		// ネットワーク環境が変わったため、適用済み・手動で切り替えたプロファイルも再び適用できるようにする
+		w.candidate, w.ignored = "", ""
+		return false
+	}
+
+	id := match.Profile.ID
+	if id != w.candidate {
+		w.candidate, w.since = id, now
+		if w.ignored != id {
+			w.ignored = ""
+		}
+		return false
+	}
+
+	switch {
+	case id == w.ignored:
+		return false
+	case now.Sub(w.since) < settings.AutoSwitchDebounce():
+		return false
+	case !w.lastSwitch.IsZero() && now.Sub(w.lastSwitch) < settings.AutoSwitchCooldown():
+		return false
+	}
+	return true
+}
+
+// NotifyManualChange は手動で設定を切り替えたことを通知します
+// 手動の切り替えを優先し、ネットワーク環境が変わるまで現在一致しているプロファイルを自動で適用しません
+func (w *Watcher) NotifyManualChange(now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastSwitch = now
+	w.ignored = w.candidate
+}
+
+// Evaluate は自動切り替えの条件に一致する最初のプロファイル（設定ファイルの順序）を返します
+// 一致するプロファイルがない場合は nil を返します
+func Evaluate(client *network.Client, cfg *models.Config) *Match {
+	obs := newObserver(client)
+	for i := range cfg.Profiles {
+		p := &cfg.Profiles[i]
+		if p.AutoSwitch == nil || p.AutoSwitch.IsEmpty() {
+			continue
+		}
+		if obs.matches(p.NICName, p.AutoSwitch) {
+			profile := *p // コピーを作成
+			return &Match{Profile: &profile, Reason: p.AutoSwitch.String()}
+		}
+	}
+	return nil
+}
+
+// observer は1回の確認で取得したネットワーク環境を保持します（同じ情報を何度も取得しないようにする）
+type observer struct {
+	client       *network.Client
+	environments map[string]*network.Environment
+	ssids        map[string]string
+	ssidsLoaded  bool
+	probes       map[string]bool
+}
+
+func newObserver(client *network.Client) *observer {
+	return &observer{
+		client:       client,
+		environments: make(map[string]*network.Environment),
+		probes:       make(map[string]bool),
+	}
+}
+
+// matches はNICのネットワーク環境が条件をすべて満たすかどうかを返します
+// 時間のかかる ping は他の条件を満たした場合のみ実行します
+func (o *observer) matches(nicName string, rule *models.AutoSwitchRule) bool {
+	if rule.LinkUpNIC != "" {
+		env := o.environment(rule.LinkUpNIC)
+		if env == nil || !env.LinkUp {
+			return false
+		}
+	}
+	if rule.SSID != "" && !o.connectedTo(rule.SSID) {
+		return false
+	}
+	if rule.GatewayMAC != "" || rule.DNSSuffix != "" {
+		env := o.environment(nicName)
+		if env == nil || !env.LinkUp {
+			return false
+		}
+		if rule.GatewayMAC != "" && env.GatewayMAC != rule.GatewayMAC {
+			return false
+		}
+		if rule.DNSSuffix != "" && !strings.EqualFold(env.DNSSuffix, rule.DNSSuffix) {
+			return false
+		}
+	}
+	if rule.ProbeHost != "" && !o.probe(rule.ProbeHost) {
+		return false
+	}
+	return true
+}
+
+// environment はNICのネットワーク環境を返します（取得できない場合は nil）
+func (o *observer) environment(nicName string) *network.Environment {
+	if env, ok := o.environments[nicName]; ok {
+		return env
+	}
+	env, err := o.client.GetEnvironment(nicName)
+	if err != nil {
+		logger.Debug("自動切り替え: NICの接続先を取得できません", "nic", nicName, "error", err)
+	}
+	o.environments[nicName] = env
+	return env
+}
+
+// connectedTo はいずれかの無線NICがSSIDに接続しているかどうかを返します
+func (o *observer) connectedTo(ssid string) bool {
+	if !o.ssidsLoaded {
+		ssids, err := o.client.GetWirelessSSIDs()
+		if err != nil {
+			logger.Debug("自動切り替え: 無線NICの接続先を取得できません", "error", err)
+		}
+		o.ssids, o.ssidsLoaded = ssids, true
+	}
+	for _, connected := range o.ssids {
+		if connected == ssid {
+			return true
+		}
+	}
+	return false
+}
+
+// probe はホストが ping に応答するかどうかを返します
+func (o *observer) probe(host string) bool {
+	if ok, checked := o.probes[host]; checked {
+		return ok
+	}
+	ok := o.client.Probe(host)
+	o.probes[host] = ok
+	return ok
+}
@@ -0,0 +1,193 @@
+package autoswitch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/network"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// linkRunner は Ethernet のリンクの状態だけを返す Runner です
+// 現在の設定の取得などその他のコマンドは失敗するため、適用前の確認では常に変更ありになります
+type linkRunner struct {
+	up bool
+}
+
+func (r *linkRunner) Output(name string, args ...string) ([]byte, error) {
+	if name == "powershell" && r.up {
+		return []byte(`{"status":"Up","gateway":"","gatewayMac":"","dnsSuffix":""}`), nil
+	}
+	return nil, errors.New("not connected")
+}
+
+func (r *linkRunner) CombinedOutput(name string, args ...string) ([]byte, error) {
+	return r.Output(name, args...)
+}
+
+// watcherTest は固定の時計で Watcher を操作するテスト用の環境です
+type watcherTest struct {
+	t       *testing.T
+	runner  *linkRunner
+	watcher *Watcher
+	start   time.Time
+	applied int
+	fail    bool
+}
+
+// newWatcherTest は Ethernet のリンクアップで office を適用する Watcher を作成します
+// 待ち時間は 10 秒、間隔は 60 秒です
+func newWatcherTest(t *testing.T) *watcherTest {
+	wt := &watcherTest{
+		t:      t,
+		runner: &linkRunner{up: true},
+		start:  time.Date(2026, 4, 1, 9, 0, 0, 0, time.Local),
+	}
+	cfg := &models.Config{
+		Profiles: []models.Profile{{
+			ID:         "office",
+			Name:       "Office",
+			NICName:    "Ethernet",
+			IPAddress:  "192.168.1.10",
+			SubnetMask: "255.255.255.0",
+			AutoSwitch: &models.AutoSwitchRule{LinkUpNIC: "Ethernet"},
+		}},
+		Settings: models.Settings{AutoSwitch: true, AutoSwitchDebounceSeconds: 10, AutoSwitchCooldownSeconds: 60},
+	}
+	apply := func(match Match) error {
+		wt.applied++
+		if wt.fail {
+			return errors.New("apply failed")
+		}
+		return nil
+	}
+	wt.watcher = NewWatcher(network.NewClient(wt.runner), func() *models.Config { return cfg }, apply)
+	return wt
+}
+
+// check は開始からの経過秒数の時刻で Check を呼び出し、適用した回数の合計が want かを確認します
+func (wt *watcherTest) check(seconds int, want int) {
+	wt.t.Helper()
+	wt.watcher.Check(wt.at(seconds))
+	if wt.applied != want {
+		wt.t.Fatalf("%ds: applied = %d, want %d", seconds, wt.applied, want)
+	}
+}
+
+// at は開始からの経過秒数の時刻を返します
+func (wt *watcherTest) at(seconds int) time.Time {
+	return wt.start.Add(time.Duration(seconds) * time.Second)
+}
+
+func TestWatcherDebounce(t *testing.T) {
+	wt := newWatcherTest(t)
+	wt.check(0, 0) // 一致し始めた
+	wt.check(5, 0) // 待ち時間が経過していない
+	wt.check(9, 0)
+	wt.check(10, 1)
+	// 適用したプロファイルは、ネットワーク環境が変わるまで再び適用しない
+	wt.check(100, 1)
+
+	// 一致しなくなった場合は待ち時間を最初から数える
+	wt.runner.up = false
+	wt.check(105, 1)
+	wt.runner.up = true
+	wt.check(110, 1)
+	wt.check(115, 1)
+	wt.check(120, 2)
+}
+
+func TestWatcherCooldownAfterAutomaticSwitch(t *testing.T) {
+	wt := newWatcherTest(t)
+	wt.check(0, 0)
+	wt.check(10, 1)
+
+	// 自動で切り替えた後は、ネットワーク環境が変わっても間隔が経過するまで切り替えない
+	wt.runner.up = false
+	wt.check(20, 1)
+	wt.runner.up = true
+	wt.check(30, 1)
+	wt.check(45, 1)
+	wt.check(69, 1)
+	wt.check(70, 2)
+}
+
+func TestWatcherCooldownAfterManualSwitch(t *testing.T) {
+	wt := newWatcherTest(t)
+	wt.watcher.NotifyManualChange(wt.at(0))
+	wt.check(1, 0)
+	wt.check(11, 0) // 待ち時間は経過したが、手動で切り替えてから間隔が経過していない
+	wt.check(59, 0)
+	wt.check(60, 1)
+}
+
+func TestWatcherManualSwitchWhileMatching(t *testing.T) {
+	wt := newWatcherTest(t)
+	wt.check(0, 0)
+	// 一致中に手動で切り替えた場合は、間隔が経過してもネットワーク環境が変わるまで適用しない
+	wt.watcher.NotifyManualChange(wt.at(5))
+	wt.check(10, 0)
+	wt.check(100, 0)
+
+	// 一致しなくなると手動で切り替えたプロファイルも再び適用できる
+	wt.runner.up = false
+	wt.check(105, 0)
+	wt.runner.up = true
+	wt.check(110, 0)
+	wt.check(120, 1)
+}
+
+func TestWatcherRetryAfterFailure(t *testing.T) {
+	wt := newWatcherTest(t)
+	wt.fail = true
+	wt.check(0, 0)
+	wt.check(10, 1)
+	// 失敗した場合は間隔が経過するまで再試行しない
+	wt.check(15, 1)
+	wt.check(69, 1)
+	wt.check(70, 2)
+
+	wt.fail = false
+	wt.check(129, 2)
+	wt.check(130, 3)
+	// 成功した後は再び適用しない
+	wt.check(300, 3)
+}
+
+func TestWatcherDisabled(t *testing.T) {
+	wt := newWatcherTest(t)
+	wt.check(0, 0)
+	wt.watcher.config = func() *models.Config { return &models.Config{} }
+	wt.check(10, 0)
+	if wt.watcher.candidate != "" || wt.watcher.ignored != "" {
+		t.Errorf("candidate = %q, ignored = %q, want both cleared", wt.watcher.candidate, wt.watcher.ignored)
+	}
+}
+
+func TestWatcherReady(t *testing.T) {
+	settings := models.Settings{AutoSwitchDebounceSeconds: 10, AutoSwitchCooldownSeconds: 60}
+	start := time.Date(2026, 4, 1, 9, 0, 0, 0, time.Local)
+	office := &Match{Profile: &models.Profile{ID: "office"}}
+	home := &Match{Profile: &models.Profile{ID: "home"}}
+
+	w := NewWatcher(nil, nil, nil)
+	w.ignored = "office"
+	// 別のプロファイルが一致した場合は、適用しないプロファイルの記録を消す
+	if w.ready(home, start, settings) || w.candidate != "home" || w.ignored != "" {
+		t.Errorf("ready(home) candidate = %q, ignored = %q", w.candidate, w.ignored)
+	}
+
+	w.ignored = "home"
+	// 一致しなくなった場合も消す
+	if w.ready(nil, start.Add(time.Second), settings) || w.candidate != "" || w.ignored != "" {
+		t.Errorf("ready(nil) candidate = %q, ignored = %q", w.candidate, w.ignored)
+	}
+
+	if w.ready(office, start.Add(2*time.Second), settings) {
+		t.Error("ready(office) = true on first match")
+	}
+	if !w.ready(office, start.Add(12*time.Second), settings) {
+		t.Error("ready(office) = false after debounce")
+	}
+}
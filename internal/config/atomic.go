@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic はファイルを一時ファイルに書き込んでから置き換えます
+// 書き込み中にプロセスが終了しても、元のファイルが途中まで書かれた状態で残ることはありません
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("一時ファイルの作成に失敗: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	// 置き換えに成功するまでは一時ファイルを削除する
+	renamed := false
+	defer func() {
+		if !renamed {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("一時ファイルへの書き込みに失敗: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("一時ファイルの同期に失敗: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("一時ファイルのクローズに失敗: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("一時ファイルの権限設定に失敗: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("ファイルの置き換えに失敗: %w", err)
+	}
+	renamed = true
+	return nil
+}
+
+// WithFileLock はファイルに対応するロックファイル（<path>.lock）を排他ロックした状態で fn を実行します
+// 別プロセスとの読み込み・変更・書き込みの競合を防ぐために使用します
+func WithFileLock(path string, fn func() error) error {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("ロックファイルのオープンに失敗: %w", err)
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("ファイルのロックに失敗: %w", err)
+	}
+	defer unlockFile(f)
+
+	return fn()
+}
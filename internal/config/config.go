@@ -2,10 +2,12 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
 	"github.com/fast-ip-change/fast-ip-change/pkg/models"
 )
 
@@ -14,6 +16,10 @@ const (
 	configFileName = "settings.json"
 )
 
+// ErrConflict は読み込んだ後に他のプロセスが設定ファイルを更新していた場合のエラーです
+// 設定を読み込み直してから変更をやり直してください
+var ErrConflict = errors.New("設定ファイルが他のプロセスによって更新されています")
+
 // GetConfigPath は設定ファイルのパスを返します
 func GetConfigPath() (string, error) {
 	appData, err := os.UserConfigDir()
@@ -29,59 +35,159 @@ func GetConfigPath() (string, error) {
 	return filepath.Join(configDir, configFileName), nil
 }
 
-// LoadConfig は設定ファイルを読み込みます
+// LoadConfig は設定ファイルを読み込み、集中管理されたプロファイルを追加します
 func LoadConfig() (*models.Config, error) {
 	configPath, err := GetConfigPath()
 	if err != nil {
 		return nil, err
 	}
 
-	// ファイルが存在しない場合はデフォルト設定を返す
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return GetDefaultConfig(), nil
+	config, migrated, err := loadConfigFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	mergeManagedProfiles(config)
+	logValidationIssues(config)
+	if !migrated {
+		return config, nil
 	}
 
+	// 移行した設定を保存（失敗しても次回の読み込み時に再度移行するため、読み込み結果は返す）
+	err = WithFileLock(configPath, func() error {
+		current, _, err := loadConfigFile(configPath)
+		if err != nil {
+			return err
+		}
+		if current.Revision != config.Revision {
+			// 他のプロセスが先に保存した
+			return nil
+		}
+		return writeConfigFile(configPath, config)
+	})
+	if err != nil {
+		logger.Warn("移行した設定ファイルの保存に失敗", "error", err)
+	}
+	return config, nil
+}
+
+// logValidationIssues は設定の問題をログに記録します
+// 手で編集した設定ファイルに誤りがあっても起動できるよう、読み込みは止めません
+func logValidationIssues(config *models.Config) {
+	for _, issue := range config.Validate() {
+		logger.Warn("設定ファイルに問題があります", "path", issue.Path, "severity", issue.Severity, "message", issue.Message)
+	}
+}
+
+// loadConfigFile は指定されたパスの設定ファイルを読み込み、現在のバージョンに移行します
+// 移行した場合は元のファイルをバックアップし、migrated に true を返します（ファイルへの保存は呼び出し側で行う）
+func loadConfigFile(configPath string) (config *models.Config, migrated bool, err error) {
+	// ファイルが存在しない場合はデフォルト設定を返す
 	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return GetDefaultConfig(), false, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("設定ファイルの読み込みに失敗: %w", err)
+		return nil, false, fmt.Errorf("設定ファイルの読み込みに失敗: %w", err)
 	}
 
-	var config models.Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("設定ファイルの解析に失敗: %w", err)
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, false, fmt.Errorf("設定ファイルの解析に失敗: %w", err)
 	}
 
-	// バージョンが設定されていない場合はデフォルト値を設定
-	if config.Version == "" {
-		config.Version = "1.0"
+	from, err := migrateDocument(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	if from != "" {
+		if err := backupConfigFile(configPath, from, data); err != nil {
+			return nil, false, err
+		}
+		logger.Info("設定ファイルを移行しました", "from", from, "to", CurrentVersion)
+
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, false, fmt.Errorf("移行した設定のシリアライズに失敗: %w", err)
+		}
 	}
 
-	return &config, nil
+	config = &models.Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, false, fmt.Errorf("設定ファイルの解析に失敗: %w", err)
+	}
+	return config, from != "", nil
 }
 
 // SaveConfig は設定ファイルを保存します
+// config.Revision がファイルのリビジョンと一致しない場合（読み込み後に他のプロセスが保存した場合）は
+// ErrConflict を返し、ファイルは変更しません。保存に成功すると config.Revision を更新します
 func SaveConfig(config *models.Config) error {
 	configPath, err := GetConfigPath()
 	if err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(config, "", "  ")
+	return WithFileLock(configPath, func() error {
+		current, _, err := loadConfigFile(configPath)
+		if err != nil {
+			return err
+		}
+		if current.Revision != config.Revision {
+			return fmt.Errorf("%w（読み込み時: %d, 現在: %d）", ErrConflict, config.Revision, current.Revision)
+		}
+		return writeConfigFile(configPath, config)
+	})
+}
+
+// UpdateConfig は設定ファイルをロックした状態で読み込み、fn で変更して保存します
+// fn には集中管理されたプロファイルを含む設定を渡します。fn がエラーを返した場合は保存しません
+func UpdateConfig(fn func(config *models.Config) error) (*models.Config, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var config *models.Config
+	err = WithFileLock(configPath, func() error {
+		config, _, err = loadConfigFile(configPath)
+		if err != nil {
+			return err
+		}
+		mergeManagedProfiles(config)
+		if err := fn(config); err != nil {
+			return err
+		}
+		return writeConfigFile(configPath, config)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// writeConfigFile はリビジョンを進めて設定ファイルを書き込みます（ロックは呼び出し側で取得）
+// 集中管理されたプロファイルは書き込みません
+func writeConfigFile(configPath string, config *models.Config) error {
+	next := *config
+	next.Profiles = storedProfiles(config)
+	next.Revision++
+
+	data, err := json.MarshalIndent(&next, "", "  ")
 	if err != nil {
 		return fmt.Errorf("設定のシリアライズに失敗: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
+	if err := WriteFileAtomic(configPath, data, 0600); err != nil {
 		return fmt.Errorf("設定ファイルの書き込みに失敗: %w", err)
 	}
 
+	config.Revision = next.Revision
 	return nil
 }
 
 // GetDefaultConfig はデフォルト設定を返します
 func GetDefaultConfig() *models.Config {
 	return &models.Config{
-		Version:   "1.0",
+		Version:   CurrentVersion,
 		AutoStart: false,
 		Profiles:  []models.Profile{},
 		Settings: models.Settings{
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// setupConfigDir は設定ファイルのディレクトリを一時ディレクトリに変更します
+func setupConfigDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	// os.UserConfigDir が返すディレクトリ（Windows では AppData、それ以外では XDG_CONFIG_HOME）
+	t.Setenv("AppData", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	// 集中管理されたプロファイルを読み込まない
+	t.Setenv("ProgramData", "")
+	return filepath.Join(dir, configDirName)
+}
+
+func TestSaveConfigConflict(t *testing.T) {
+	setupConfigDir(t)
+
+	first, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 同じリビジョンから2回保存すると、2回目は他のプロセスの保存と競合する
+	first.Profiles = append(first.Profiles, models.Profile{ID: "a", Name: "A"})
+	if err := SaveConfig(first); err != nil {
+		t.Fatalf("SaveConfig(first) error = %v", err)
+	}
+	if first.Revision != second.Revision+1 {
+		t.Errorf("Revision = %d, want %d", first.Revision, second.Revision+1)
+	}
+	second.Profiles = append(second.Profiles, models.Profile{ID: "b", Name: "B"})
+	if err := SaveConfig(second); !errors.Is(err, ErrConflict) {
+		t.Fatalf("SaveConfig(second) error = %v, want ErrConflict", err)
+	}
+
+	// 競合した場合はファイルを変更しない
+	current, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(current.Profiles) != 1 || current.Profiles[0].ID != "a" || current.Revision != first.Revision {
+		t.Errorf("config = %+v (revision %d), want only profile a", current.Profiles, current.Revision)
+	}
+
+	// 読み込み直せば保存できる
+	current.Profiles = append(current.Profiles, models.Profile{ID: "b", Name: "B"})
+	if err := SaveConfig(current); err != nil {
+		t.Errorf("SaveConfig(reloaded) error = %v", err)
+	}
+}
+
+func TestUpdateConfigConcurrent(t *testing.T) {
+	setupConfigDir(t)
+
+	// 同時に更新しても、ロックにより変更が失われない
+	const n = 10
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := UpdateConfig(func(cfg *models.Config) error {
+				cfg.Profiles = append(cfg.Profiles, models.Profile{ID: string(rune('a' + i)), Name: string(rune('A' + i))})
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Profiles) != n || cfg.Revision != n {
+		t.Errorf("profiles = %d, revision = %d, want %d, %d", len(cfg.Profiles), cfg.Revision, n, n)
+	}
+}
+
+func TestUpdateConfigError(t *testing.T) {
+	dir := setupConfigDir(t)
+	if _, err := UpdateConfig(func(cfg *models.Config) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(filepath.Join(dir, configFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fn がエラーを返した場合は保存しない
+	errAbort := errors.New("abort")
+	_, err = UpdateConfig(func(cfg *models.Config) error {
+		cfg.Profiles = append(cfg.Profiles, models.Profile{ID: "a", Name: "A"})
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Errorf("UpdateConfig() error = %v, want %v", err, errAbort)
+	}
+	if after, _ := os.ReadFile(filepath.Join(dir, configFileName)); string(after) != string(before) {
+		t.Errorf("settings.json changed:\n%s", after)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	for _, data := range []string{`{"a": 1}`, `{}`} {
+		if err := WriteFileAtomic(path, []byte(data), 0600); err != nil {
+			t.Fatalf("WriteFileAtomic() error = %v", err)
+		}
+		if got, err := os.ReadFile(path); err != nil || string(got) != data {
+			t.Errorf("file = %q, %v, want %q", got, err, data)
+		}
+	}
+
+	// 一時ファイルを残さない
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("files = %v, want only state.json", entries)
+	}
+}
+
+func TestWriteFileAtomicFailureKeepsOriginal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	if err := os.WriteFile(path, []byte("original"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// ディレクトリは置き換えられないため、置き換えに失敗する
+	target := filepath.Join(dir, "target")
+	if err := os.Mkdir(target, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(target, "child"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(target, []byte("new"), 0600); err == nil {
+		t.Fatal("WriteFileAtomic() error = nil, want rename failure")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			t.Errorf("temporary file %s was not removed", e.Name())
+		}
+	}
+	if got, _ := os.ReadFile(path); string(got) != "original" {
+		t.Errorf("state.json = %q, want original", got)
+	}
+}
+
+func TestWithFileLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+
+	// ロック中の処理は同時に実行されない
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		running int
+		maxRun  int
+	)
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := WithFileLock(path, func() error {
+				mu.Lock()
+				running++
+				maxRun = max(maxRun, running)
+				mu.Unlock()
+
+				data, _ := os.ReadFile(path)
+				if err := os.WriteFile(path, append(data, 'x'), 0600); err != nil {
+					return err
+				}
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxRun != 1 {
+		t.Errorf("concurrent runs = %d, want 1", maxRun)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "xxxxx" {
+		t.Errorf("file = %q, want 5 appends", data)
+	}
+
+	errFn := errors.New("fn failed")
+	if err := WithFileLock(path, func() error { return errFn }); !errors.Is(err, errFn) {
+		t.Errorf("WithFileLock() error = %v, want %v", err, errFn)
+	}
+}
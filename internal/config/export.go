@@ -0,0 +1,393 @@
+package config
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// exportFormatName はエクスポートファイルの format の値です
+const exportFormatName = "fast-ip-change-profiles"
+
+// utf8BOM は Excel でUTF-8のCSVを正しく開くために先頭に付けるバイト列です
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ExportDocument はプロファイルのエクスポートファイル（JSON）の形式です
+// 別のPCにインポートできるよう、スキーマのバージョンを含めます
+type ExportDocument struct {
+	Format     string           `json:"format"`
+	Version    string           `json:"version"`
+	ExportedAt time.Time        `json:"exportedAt"`
+	Profiles   []models.Profile `json:"profiles"`
+	// Settings は設定全体をエクスポートした場合のみ含まれます
+	Settings *models.Settings `json:"settings,omitempty"`
+}
+
+// NewExportDocument は設定からエクスポートする内容を作成します
+// keys（プロファイル名またはID）が空の場合はすべてのプロファイルを対象にします
+// includeSettings が true の場合は動作設定も含めます
+func NewExportDocument(cfg *models.Config, keys []string, includeSettings bool) (*ExportDocument, error) {
+	doc := &ExportDocument{
+		Format:     exportFormatName,
+		Version:    CurrentVersion,
+		ExportedAt: time.Now(),
+		Profiles:   []models.Profile{},
+	}
+
+	if len(keys) == 0 {
+		doc.Profiles = append(doc.Profiles, cfg.Profiles...)
+	}
+	for _, key := range keys {
+		profile := cfg.FindProfile(key)
+		if profile == nil {
+			return nil, fmt.Errorf("プロファイルが見つかりません: %s", key)
+		}
+		doc.Profiles = append(doc.Profiles, *profile)
+	}
+
+	if includeSettings {
+		settings := cfg.Settings
+		doc.Settings = &settings
+	}
+	return doc, nil
+}
+
+// IsCSVPath はファイル名の拡張子が .csv かどうかを判定します
+func IsCSVPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".csv")
+}
+
+// WriteExportFile はエクスポートファイルを書き込みます（拡張子が .csv の場合はCSV、それ以外はJSON）
+// CSVにはプロファイルのみを出力します
+func WriteExportFile(path string, doc *ExportDocument) error {
+	var buf bytes.Buffer
+	var err error
+	if IsCSVPath(path) {
+		err = WriteProfilesCSV(&buf, doc.Profiles)
+	} else {
+		err = WriteExportJSON(&buf, doc)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := WriteFileAtomic(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("ファイルの書き込みに失敗: %w", err)
+	}
+	return nil
+}
+
+// WriteExportJSON はエクスポートファイルをJSON形式で書き込みます
+func WriteExportJSON(w io.Writer, doc *ExportDocument) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("エクスポートのシリアライズに失敗: %w", err)
+	}
+	return nil
+}
+
+// csvColumns はCSVの列名です（JSON の項目名と同じ）
+// 複数の値を持つ項目は ";" 区切りで1つのセルに格納します（疎通確認は "tcp intranet:443 10" の形式）
+// 自動切り替えの条件は JSON と同じ形式のオブジェクトを1つのセルに格納します
+var csvColumns = []string{
+	"id", "name", "nicName", "ipAddress", "subnetMask", "gateway",
+	"dnsServers", "dnsSuffix", "registerDns",
+	"additionalAddresses", "routes", "interfaceMetric", "mtu",
+	"ipv6Address", "ipv6Gateway", "ipv6DnsServers",
+	"group", "pinned", "autoSwitch",
+	"healthChecks", "rollbackOnHealthCheckFailure",
+}
+
+// csvListSeparator はCSVのセル内で複数の値を区切る文字です
+const csvListSeparator = ";"
+
+// WriteProfilesCSV はプロファイルをCSV形式（UTF-8、BOM付き）で書き込みます
+func WriteProfilesCSV(w io.Writer, profiles []models.Profile) error {
+	if _, err := w.Write(utf8BOM); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+	for i := range profiles {
+		record, err := profileToRecord(&profiles[i])
+		if err != nil {
+			return err
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// profileToRecord はプロファイルをCSVの1行に変換します（列の順序は csvColumns）
+func profileToRecord(p *models.Profile) ([]string, error) {
+	register := ""
+	if p.RegisterDNS != nil {
+		register = strconv.FormatBool(*p.RegisterDNS)
+	}
+	addresses := make([]string, len(p.AdditionalAddresses))
+	for i, entry := range p.AdditionalAddresses {
+		addresses[i] = entry.String()
+	}
+	routes := make([]string, len(p.Routes))
+	for i, route := range p.Routes {
+		routes[i] = route.Format()
+	}
+	mtu := ""
+	if p.MTU != 0 {
+		mtu = strconv.Itoa(p.MTU)
+	}
+	pinned := ""
+	if p.Pinned {
+		pinned = "true"
+	}
+	checks := make([]string, len(p.HealthChecks))
+	for i, check := range p.HealthChecks {
+		checks[i] = check.Format()
+	}
+	rollback := ""
+	if p.RollbackOnHealthCheckFailure {
+		rollback = "true"
+	}
+	autoSwitch := ""
+	if p.AutoSwitch != nil {
+		data, err := json.Marshal(p.AutoSwitch)
+		if err != nil {
+			return nil, fmt.Errorf("自動切り替えの条件のシリアライズに失敗: %w", err)
+		}
+		autoSwitch = string(data)
+	}
+
+	return []string{
+		p.ID, p.Name, p.NICName, p.IPAddress, p.SubnetMask, p.Gateway,
+		strings.Join(p.DNSServers, csvListSeparator), p.DNSSuffix, register,
+		strings.Join(addresses, csvListSeparator), strings.Join(routes, csvListSeparator), p.InterfaceMetric, mtu,
+		p.IPv6CIDR(), p.IPv6Gateway, strings.Join(p.IPv6DNSServers, csvListSeparator),
+		p.Group, pinned, autoSwitch,
+		strings.Join(checks, csvListSeparator), rollback,
+	}, nil
+}
+
+// ReadImportFile はインポートするファイルを読み込みます（拡張子が .csv の場合はCSV、それ以外はJSON）
+func ReadImportFile(path string) (*ExportDocument, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ファイルの読み込みに失敗: %w", err)
+	}
+	if IsCSVPath(path) {
+		profiles, err := ParseProfilesCSV(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return &ExportDocument{Format: exportFormatName, Version: CurrentVersion, Profiles: profiles}, nil
+	}
+	return ParseImportJSON(data)
+}
+
+// ParseImportJSON はインポートするJSONを解析し、現在のバージョンに移行します
+// エクスポートファイルのほか、settings.json と以前の export コマンドの出力（プロファイルの配列）も受け付けます
+func ParseImportJSON(data []byte) (*ExportDocument, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	var doc document
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		// バージョンを含まない以前の形式
+		var profiles []any
+		if err := json.Unmarshal(data, &profiles); err != nil {
+			return nil, fmt.Errorf("インポートファイルの解析に失敗: %w", err)
+		}
+		doc = document{"version": initialVersion, "profiles": profiles}
+	} else if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("インポートファイルの解析に失敗: %w", err)
+	}
+	if _, ok := doc["profiles"]; !ok {
+		return nil, fmt.Errorf("インポートファイルにプロファイルが含まれていません")
+	}
+
+	if _, err := migrateDocument(doc); err != nil {
+		return nil, err
+	}
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("インポートファイルの移行に失敗: %w", err)
+	}
+
+	var result ExportDocument
+	if err := json.Unmarshal(migrated, &result); err != nil {
+		return nil, fmt.Errorf("インポートファイルの解析に失敗: %w", err)
+	}
+	return &result, nil
+}
+
+// ParseProfilesCSV はCSVからプロファイルを読み込みます
+// 1行目は列名です（列の順序は任意、name・nicName・ipAddress 以外の列は省略可）
+func ParseProfilesCSV(r io.Reader) ([]models.Profile, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("CSVの読み込みに失敗: %w", err)
+	}
+	cr := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, utf8BOM)))
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("CSVの列名の読み込みに失敗: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if !containsString(csvColumns, name) {
+			return nil, fmt.Errorf("CSVの列名が不正です: %s", name)
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"name", "nicName", "ipAddress"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSVに %s 列がありません", required)
+		}
+	}
+
+	var profiles []models.Profile
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("CSVの読み込みに失敗: %w", err)
+		}
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		profile, err := recordToProfile(value)
+		if err != nil {
+			return nil, fmt.Errorf("CSVの %d 行目: %w", line, err)
+		}
+		profiles = append(profiles, *profile)
+	}
+	return profiles, nil
+}
+
+// recordToProfile はCSVの1行をプロファイルに変換します
+// 形式の検証はインポート時にプロファイルの検証で行います
+func recordToProfile(value func(column string) string) (*models.Profile, error) {
+	p := &models.Profile{
+		ID:              value("id"),
+		Name:            value("name"),
+		NICName:         value("nicName"),
+		IPAddress:       value("ipAddress"),
+		SubnetMask:      value("subnetMask"),
+		Gateway:         value("gateway"),
+		DNSServers:      splitCSVList(value("dnsServers")),
+		DNSSuffix:       value("dnsSuffix"),
+		InterfaceMetric: strings.ToLower(value("interfaceMetric")),
+		IPv6Gateway:     value("ipv6Gateway"),
+		IPv6DNSServers:  splitCSVList(value("ipv6DnsServers")),
+		Group:           value("group"),
+	}
+
+	if text := value("registerDns"); text != "" {
+		register, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("registerDns は true または false で指定してください: %s", text)
+		}
+		p.RegisterDNS = &register
+	}
+	if text := value("pinned"); text != "" {
+		pinned, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("pinned は true または false で指定してください: %s", text)
+		}
+		p.Pinned = pinned
+	}
+	if text := value("rollbackOnHealthCheckFailure"); text != "" {
+		rollback, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("rollbackOnHealthCheckFailure は true または false で指定してください: %s", text)
+		}
+		p.RollbackOnHealthCheckFailure = rollback
+	}
+	for _, item := range splitCSVList(value("additionalAddresses")) {
+		entry, err := models.ParseIPAddressEntry(item)
+		if err != nil {
+			return nil, err
+		}
+		p.AdditionalAddresses = append(p.AdditionalAddresses, entry)
+	}
+	for _, item := range splitCSVList(value("routes")) {
+		route, err := models.ParseStaticRoute(item)
+		if err != nil {
+			return nil, err
+		}
+		p.Routes = append(p.Routes, route)
+	}
+	for _, item := range splitCSVList(value("healthChecks")) {
+		check, err := models.ParseHealthCheck(item)
+		if err != nil {
+			return nil, err
+		}
+		p.HealthChecks = append(p.HealthChecks, check)
+	}
+	if text := value("mtu"); text != "" {
+		mtu, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", models.ErrInvalidMTU, text)
+		}
+		p.MTU = mtu
+	}
+	if text := value("autoSwitch"); text != "" {
+		var rule models.AutoSwitchRule
+		if err := json.Unmarshal([]byte(text), &rule); err != nil {
+			return nil, fmt.Errorf("autoSwitch は JSON のオブジェクトで指定してください（例: {\"ssid\":\"office\"}）: %w", err)
+		}
+		p.AutoSwitch = &rule
+	}
+	if text := value("ipv6Address"); text != "" {
+		addr, length, err := models.ParseIPv6CIDR(text)
+		if err != nil {
+			return nil, err
+		}
+		p.IPv6Address, p.IPv6PrefixLength = addr, length
+	}
+	return p, nil
+}
+
+// splitCSVList はセル内の ";" 区切りの値を分割します
+func splitCSVList(text string) []string {
+	var values []string
+	for _, v := range strings.Split(text, csvListSeparator) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// containsString はスライスに文字列が含まれるかどうかを判定します
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+func TestParseImportJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantNames    []string
+		wantDNS      []string
+		wantSettings bool
+		wantErr      bool
+	}{
+		{
+			name: "export document",
+			input: `{"format": "fast-ip-change-profiles", "version": "1.1", "profiles": [
+				{"id": "a", "name": "A", "nicName": "Ethernet", "ipAddress": "10.0.0.10", "dnsServers": ["10.0.0.53"]}]}`,
+			wantNames: []string{"A"},
+			wantDNS:   []string{"10.0.0.53"},
+		},
+		{
+			// 以前の export コマンドの出力（プロファイルの配列、旧形式のDNS）
+			name:      "array",
+			input:     "\xef\xbb\xbf" + `[{"name": "A", "dnsPrimary": "10.0.0.53", "dnsSecondary": "8.8.8.8"}, {"name": "B"}]`,
+			wantNames: []string{"A", "B"},
+			wantDNS:   []string{"10.0.0.53", "8.8.8.8"},
+		},
+		{
+			name:         "settings.json",
+			input:        `{"version": "1.0", "profiles": [{"name": "A", "dnsPrimary": "10.0.0.53"}], "settings": {"logLevel": "DEBUG"}}`,
+			wantNames:    []string{"A"},
+			wantDNS:      []string{"10.0.0.53"},
+			wantSettings: true,
+		},
+		{name: "no profiles", input: `{"version": "1.1", "settings": {}}`, wantErr: true},
+		{name: "newer version", input: `{"version": "9.0", "profiles": []}`, wantErr: true},
+		{name: "invalid", input: `{"profiles": [`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseImportJSON([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseImportJSON() error = %v, want error: %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var names []string
+			for _, p := range doc.Profiles {
+				names = append(names, p.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("profiles = %q, want %q", names, tt.wantNames)
+			}
+			if !reflect.DeepEqual(doc.Profiles[0].DNSServers, tt.wantDNS) {
+				t.Errorf("DNSServers = %q, want %q", doc.Profiles[0].DNSServers, tt.wantDNS)
+			}
+			if (doc.Settings != nil) != tt.wantSettings {
+				t.Errorf("Settings = %+v, want present: %t", doc.Settings, tt.wantSettings)
+			}
+		})
+	}
+}
+
+func TestParseProfilesCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []models.Profile
+		wantErr string
+	}{
+		{
+			// 列の順序は任意、省略した列は空
+			name:  "minimal",
+			input: "\xef\xbb\xbfipAddress,name,nicName,dnsServers\r\n10.0.0.10,A,Ethernet, 10.0.0.53 ; 8.8.8.8\r\n",
+			want: []models.Profile{
+				{Name: "A", NICName: "Ethernet", IPAddress: "10.0.0.10", DNSServers: []string{"10.0.0.53", "8.8.8.8"}},
+			},
+		},
+		{
+			name:  "optional columns",
+			input: "name,nicName,ipAddress,registerDns,pinned,mtu,interfaceMetric,ipv6Address\nA,Ethernet,dhcp,false,true,1400,AUTO,fd00::10/64\n",
+			want: []models.Profile{
+				{Name: "A", NICName: "Ethernet", IPAddress: "dhcp", RegisterDNS: new(bool), Pinned: true, MTU: 1400,
+					InterfaceMetric: "auto", IPv6Address: "fd00::10", IPv6PrefixLength: 64},
+			},
+		},
+		{name: "unknown column", input: "name,nicName,ipAddress,comment\n", wantErr: "CSVの列名が不正です: comment"},
+		{name: "missing column", input: "name,ipAddress\n", wantErr: "CSVに nicName 列がありません"},
+		{name: "invalid registerDns", input: "name,nicName,ipAddress,registerDns\nA,Ethernet,dhcp,yes\n", wantErr: "CSVの 2 行目: registerDns"},
+		{name: "invalid autoSwitch", input: "name,nicName,ipAddress,autoSwitch\nA,Ethernet,dhcp,ssid=office\n", wantErr: "CSVの 2 行目: autoSwitch"},
+		{name: "invalid healthChecks", input: "name,nicName,ipAddress,healthChecks\nA,Ethernet,dhcp,tcp a:1 x\n", wantErr: "CSVの 2 行目: 疎通確認が無効です"},
+		{name: "invalid mtu", input: "name,nicName,ipAddress,mtu\nA,Ethernet,dhcp,x\nB,Ethernet,dhcp,x\n", wantErr: "CSVの 2 行目"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProfilesCSV(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseProfilesCSV() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseProfilesCSV() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseProfilesCSV() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfilesCSVRoundTrip(t *testing.T) {
+	register := true
+	profiles := []models.Profile{
+		{
+			ID: "a", Name: "Office, 3F", NICName: "Ethernet", IPAddress: "10.0.0.10", SubnetMask: "255.255.255.0",
+			Gateway: "10.0.0.1", DNSServers: []string{"10.0.0.53", "8.8.8.8"}, DNSSuffix: "corp.example", RegisterDNS: &register,
+			AdditionalAddresses: []models.IPAddressEntry{{IPAddress: "10.0.1.10", SubnetMask: "255.255.255.0"}},
+			Routes:              []models.StaticRoute{{Destination: "172.16.0.0", SubnetMask: "255.255.0.0", Gateway: "10.0.0.254", Metric: 10}},
+			InterfaceMetric:     "5", MTU: 1400,
+			IPv6Address: "fd00::10", IPv6PrefixLength: 64, IPv6Gateway: "fd00::1", IPv6DNSServers: []string{"fd00::53"},
+			Group: "会社/本社", Pinned: true,
+			AutoSwitch: &models.AutoSwitchRule{GatewayMAC: "00-11-22-33-44-55", SSID: "office, 3F"},
+			HealthChecks: []models.HealthCheck{
+				{Type: models.HealthCheckGateway, TimeoutSeconds: 10},
+				{Type: models.HealthCheckDNS, Target: "intranet.corp.example"},
+				{Type: models.HealthCheckTCP, Target: "fileserver:445", TimeoutSeconds: 3},
+			},
+			RollbackOnHealthCheckFailure: true,
+		},
+		{ID: "b", Name: "Home", NICName: "Wi-Fi", IPAddress: "dhcp"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteProfilesCSV(&buf, profiles); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), utf8BOM) {
+		t.Error("CSV does not start with a BOM")
+	}
+	got, err := ParseProfilesCSV(&buf)
+	if err != nil {
+		t.Fatalf("ParseProfilesCSV() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, profiles) {
+		t.Errorf("round trip =\n%+v\nwant\n%+v", got, profiles)
+	}
+}
+
+func TestWriteExportFile(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &models.Config{
+		Profiles: []models.Profile{{ID: "a", Name: "A", NICName: "Ethernet", IPAddress: "dhcp"}},
+		Settings: models.Settings{LogLevel: "DEBUG"},
+	}
+	doc, err := NewExportDocument(cfg, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"profiles.json", "profiles.CSV"} {
+		path := filepath.Join(dir, name)
+		if err := WriteExportFile(path, doc); err != nil {
+			t.Fatalf("WriteExportFile(%s) error = %v", name, err)
+		}
+		got, err := ReadImportFile(path)
+		if err != nil {
+			t.Fatalf("ReadImportFile(%s) error = %v", name, err)
+		}
+		if len(got.Profiles) != 1 || got.Profiles[0].ID != "a" {
+			t.Errorf("%s: profiles = %+v", name, got.Profiles)
+		}
+		// CSVにはプロファイルのみを出力する
+		if (got.Settings != nil) != !IsCSVPath(path) {
+			t.Errorf("%s: Settings = %+v", name, got.Settings)
+		}
+	}
+
+	if _, err := NewExportDocument(cfg, []string{"missing"}, false); err == nil {
+		t.Error("NewExportDocument() error = nil, want not found")
+	}
+}
@@ -0,0 +1,297 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+	"github.com/google/uuid"
+)
+
+// ConflictPolicy はインポートするプロファイルが既存のプロファイルとIDまたは名前で重複した場合の扱いです
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"      // インポートしない
+	ConflictOverwrite ConflictPolicy = "overwrite" // 既存のプロファイルを置き換える（IDは既存のものを維持）
+	ConflictRename    ConflictPolicy = "rename"    // 名前を変えて追加する（IDが重複する場合は新しいIDを発行）
+)
+
+// ConflictPolicies は指定できる ConflictPolicy の一覧です
+var ConflictPolicies = []ConflictPolicy{ConflictSkip, ConflictOverwrite, ConflictRename}
+
+// ParseConflictPolicy は文字列を ConflictPolicy に変換します
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	for _, policy := range ConflictPolicies {
+		if string(policy) == s {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("重複時の扱いは skip / overwrite / rename のいずれかで指定してください: %s", s)
+}
+
+// ImportOptions はインポートの設定です
+type ImportOptions struct {
+	OnConflict ConflictPolicy
+	// RegenerateIDs はインポートするすべてのプロファイルに新しいIDを発行します（重複は名前のみで判定）
+	RegenerateIDs bool
+	// ImportSettings はファイルに動作設定が含まれる場合に、現在の動作設定を置き換えます
+	ImportSettings bool
+}
+
+// ImportAction はインポートによるプロファイルの変更の種類です
+type ImportAction string
+
+const (
+	ImportAdd       ImportAction = "add"
+	ImportOverwrite ImportAction = "overwrite"
+	ImportRename    ImportAction = "rename"
+	ImportSkip      ImportAction = "skip"
+)
+
+// ImportChange はインポートするプロファイル1件の変更内容です
+type ImportChange struct {
+	Action ImportAction `json:"action"`
+	// Name はインポート後の名前です
+	Name string `json:"name"`
+	// SourceName はファイル上の名前です（名前を変えて追加する場合のみ）
+	SourceName string `json:"sourceName,omitempty"`
+	ID         string `json:"id"`
+	// Fields は置き換えで変更される項目です（JSON の名前）
+	Fields []string `json:"fields,omitempty"`
+}
+
+// ImportPlan はインポートの事前確認の結果です
+type ImportPlan struct {
+	Changes         []ImportChange          `json:"changes"`
+	SettingsChanged bool                    `json:"settingsChanged"`
+	Issues          models.ValidationIssues `json:"issues,omitempty"`
+	// Result はインポート後の設定です
+	Result *models.Config `json:"-"`
+}
+
+// Count は指定された種類の変更の件数を返します
+func (p *ImportPlan) Count(action ImportAction) int {
+	n := 0
+	for _, change := range p.Changes {
+		if change.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// String は変更内容を表示用の文字列で返します
+func (p *ImportPlan) String() string {
+	var b strings.Builder
+	for _, change := range p.Changes {
+		switch change.Action {
+		case ImportAdd:
+			fmt.Fprintf(&b, "追加: %s\n", change.Name)
+		case ImportOverwrite:
+			if len(change.Fields) == 0 {
+				fmt.Fprintf(&b, "上書き: %s（変更なし）\n", change.Name)
+			} else {
+				fmt.Fprintf(&b, "上書き: %s（%s）\n", change.Name, strings.Join(change.Fields, ", "))
+			}
+		case ImportRename:
+			fmt.Fprintf(&b, "名前を変えて追加: %s → %s\n", change.SourceName, change.Name)
+		case ImportSkip:
+			fmt.Fprintf(&b, "スキップ: %s（既存のプロファイルと重複）\n", change.Name)
+		}
+	}
+	if p.SettingsChanged {
+		fmt.Fprintln(&b, "動作設定を置き換え")
+	}
+	for _, issue := range p.Issues {
+		fmt.Fprintln(&b, issue.String())
+	}
+	fmt.Fprintf(&b, "追加 %d 件、上書き %d 件、名前を変えて追加 %d 件、スキップ %d 件",
+		p.Count(ImportAdd), p.Count(ImportOverwrite), p.Count(ImportRename), p.Count(ImportSkip))
+	return b.String()
+}
+
+// PlanImport はインポートした場合の変更内容と、インポート後の設定の検証結果を返します
+// cfg は変更しません。Issues は「import[ファイル上の番号].項目」の位置で、インポートするプロファイルと動作設定の問題のみを含みます
+func PlanImport(cfg *models.Config, doc *ExportDocument, opts ImportOptions) (*ImportPlan, error) {
+	if opts.OnConflict == "" {
+		opts.OnConflict = ConflictSkip
+	}
+	result, err := cloneConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	plan := &ImportPlan{Changes: []ImportChange{}, Result: result}
+
+	// インポート後の設定上の位置（profiles[N]）から、ファイル上の番号への対応
+	touched := make(map[string]int)
+	for i := range doc.Profiles {
+		profile := doc.Profiles[i]
+		if err := profile.Normalize(); err != nil {
+			plan.Issues = append(plan.Issues, models.ValidationIssue{
+				Path: fmt.Sprintf("import[%d]", i), Severity: models.SeverityError, Message: err.Error(), Err: err,
+			})
+		}
+		if opts.RegenerateIDs || profile.ID == "" {
+			profile.ID = uuid.New().String()
+		}
+
+		existing := findConflict(result, &profile)
+		change := ImportChange{Action: ImportAdd, Name: profile.Name, ID: profile.ID}
+		switch {
+		case existing < 0:
+			result.Profiles = append(result.Profiles, profile)
+			touched[fmt.Sprintf("profiles[%d]", len(result.Profiles)-1)] = i
+		case opts.OnConflict == ConflictSkip, opts.OnConflict == ConflictOverwrite && result.Profiles[existing].IsManaged():
+			// 集中管理されたプロファイルは置き換えられない
+			change.Action = ImportSkip
+		case opts.OnConflict == ConflictOverwrite:
+			change.Action = ImportOverwrite
+			profile.ID = result.Profiles[existing].ID
+			change.ID = profile.ID
+			change.Fields = changedFields(&result.Profiles[existing], &profile)
+			result.Profiles[existing] = profile
+			touched[fmt.Sprintf("profiles[%d]", existing)] = i
+		case opts.OnConflict == ConflictRename:
+			change.Action = ImportRename
+			change.SourceName = profile.Name
+			if result.FindProfile(profile.ID) != nil {
+				profile.ID = uuid.New().String()
+			}
+			profile.Name = uniqueProfileName(result, profile.Name)
+			change.Name, change.ID = profile.Name, profile.ID
+			result.Profiles = append(result.Profiles, profile)
+			touched[fmt.Sprintf("profiles[%d]", len(result.Profiles)-1)] = i
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	if opts.ImportSettings && doc.Settings != nil {
+		plan.SettingsChanged = !reflect.DeepEqual(result.Settings, *doc.Settings)
+		result.Settings = *doc.Settings
+	}
+
+	// インポート後の設定全体を検証し、インポートした部分の問題のみを取り出す
+	for _, issue := range result.Validate() {
+		prefix, field, _ := strings.Cut(issue.Path, ".")
+		if i, ok := touched[prefix]; ok {
+			issue.Path = fmt.Sprintf("import[%d].%s", i, field)
+			plan.Issues = append(plan.Issues, issue)
+		} else if plan.SettingsChanged && prefix == "settings" {
+			plan.Issues = append(plan.Issues, issue)
+		}
+	}
+	return plan, nil
+}
+
+// Import はファイルの内容を設定に取り込み、保存します
+// 事前確認と同じ内容を、設定ファイルをロックした状態で再計算して適用します
+// インポートする内容にエラーがある場合は保存せず、*models.ValidationError を返します
+func Import(doc *ExportDocument, opts ImportOptions) (*ImportPlan, error) {
+	var plan *ImportPlan
+	_, err := UpdateConfig(func(cfg *models.Config) error {
+		var err error
+		plan, err = PlanImport(cfg, doc, opts)
+		if err != nil {
+			return err
+		}
+		if err := plan.Issues.Err(); err != nil {
+			return err
+		}
+		*cfg = *plan.Result
+		return nil
+	})
+	return plan, err
+}
+
+// findConflict はIDまたは名前が重複する既存のプロファイルの位置を返します（IDの一致を優先、ない場合は -1）
+func findConflict(cfg *models.Config, profile *models.Profile) int {
+	for i := range cfg.Profiles {
+		if cfg.Profiles[i].ID == profile.ID {
+			return i
+		}
+	}
+	for i := range cfg.Profiles {
+		if cfg.Profiles[i].Name == profile.Name {
+			return i
+		}
+	}
+	return -1
+}
+
+// uniqueProfileName は既存のプロファイルと重複しない名前（"名前 (2)" など）を返します
+func uniqueProfileName(cfg *models.Config, name string) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", name, n)
+		if !hasProfileName(cfg, candidate) {
+			return candidate
+		}
+	}
+}
+
+// hasProfileName は同じ名前のプロファイルがあるかどうかを判定します
+func hasProfileName(cfg *models.Config, name string) bool {
+	for i := range cfg.Profiles {
+		if cfg.Profiles[i].Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// changedFields は2つのプロファイルで値が異なる項目（JSON の名前）を返します
+func changedFields(current, next *models.Profile) []string {
+	a, errA := profileFields(current)
+	b, errB := profileFields(next)
+	if errA != nil || errB != nil {
+		return nil
+	}
+
+	var fields []string
+	for key := range a {
+		if !reflect.DeepEqual(a[key], b[key]) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// profileFields はプロファイルを項目名と値のマップに変換します
+func profileFields(profile *models.Profile) (map[string]any, error) {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// cloneConfig は設定の複製を作成します（スライスも複製）
+// 設定ファイルに保存しない項目（集中管理の情報）も引き継ぎます
+func cloneConfig(cfg *models.Config) (*models.Config, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("設定の複製に失敗: %w", err)
+	}
+	var clone models.Config
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, fmt.Errorf("設定の複製に失敗: %w", err)
+	}
+	for i := range clone.Profiles {
+		clone.Profiles[i].ManagedSource = cfg.Profiles[i].ManagedSource
+	}
+	clone.ShadowedProfiles = append([]models.Profile(nil), cfg.ShadowedProfiles...)
+	return &clone, nil
+}
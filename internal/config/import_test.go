@@ -0,0 +1,212 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// importProfile はインポートのテストで使用する、検証に通るプロファイルです
+func importProfile(id, name, ip string) models.Profile {
+	return models.Profile{ID: id, Name: name, NICName: "Ethernet", IPAddress: ip, SubnetMask: "255.255.255.0"}
+}
+
+// importBaseConfig はインポート先の設定です（B は集中管理されたプロファイル）
+func importBaseConfig() *models.Config {
+	managed := importProfile("b", "B", "10.0.0.20")
+	managed.ManagedSource = `C:\ProgramData\fast-ip-change\profiles.json`
+	return &models.Config{
+		Version:  CurrentVersion,
+		Profiles: []models.Profile{importProfile("a", "A", "10.0.0.10"), managed},
+	}
+}
+
+func TestPlanImport(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles []models.Profile
+		opts     ImportOptions
+		// want は変更内容です（ID が空の場合は新しいIDが発行されたことのみ確認）
+		want []ImportChange
+		// wantNames はインポート後のプロファイル名です
+		wantNames []string
+	}{
+		{
+			name:      "add",
+			profiles:  []models.Profile{importProfile("c", "C", "10.0.0.30")},
+			want:      []ImportChange{{Action: ImportAdd, Name: "C", ID: "c"}},
+			wantNames: []string{"A", "B", "C"},
+		},
+		{
+			// 重複時の扱いの既定値はスキップ
+			name:      "skip by default",
+			profiles:  []models.Profile{importProfile("x", "A", "10.0.0.11")},
+			want:      []ImportChange{{Action: ImportSkip, Name: "A", ID: "x"}},
+			wantNames: []string{"A", "B"},
+		},
+		{
+			// IDが一致する場合は名前が異なっても重複とする
+			name:      "overwrite by ID",
+			profiles:  []models.Profile{importProfile("a", "A2", "10.0.0.11")},
+			opts:      ImportOptions{OnConflict: ConflictOverwrite},
+			want:      []ImportChange{{Action: ImportOverwrite, Name: "A2", ID: "a", Fields: []string{"ipAddress", "name"}}},
+			wantNames: []string{"A2", "B"},
+		},
+		{
+			// 名前で重複した場合は既存のIDを維持する
+			name:      "overwrite by name",
+			profiles:  []models.Profile{importProfile("x", "A", "10.0.0.10")},
+			opts:      ImportOptions{OnConflict: ConflictOverwrite},
+			want:      []ImportChange{{Action: ImportOverwrite, Name: "A", ID: "a"}},
+			wantNames: []string{"A", "B"},
+		},
+		{
+			// 集中管理されたプロファイルは置き換えない
+			name:      "overwrite managed",
+			profiles:  []models.Profile{importProfile("b", "B", "10.0.0.21")},
+			opts:      ImportOptions{OnConflict: ConflictOverwrite},
+			want:      []ImportChange{{Action: ImportSkip, Name: "B", ID: "b"}},
+			wantNames: []string{"A", "B"},
+		},
+		{
+			name:      "rename",
+			profiles:  []models.Profile{importProfile("x", "A", "10.0.0.11")},
+			opts:      ImportOptions{OnConflict: ConflictRename},
+			want:      []ImportChange{{Action: ImportRename, Name: "A (2)", SourceName: "A", ID: "x"}},
+			wantNames: []string{"A", "B", "A (2)"},
+		},
+		{
+			// IDが重複する場合は新しいIDを発行する
+			name:      "rename with ID conflict",
+			profiles:  []models.Profile{importProfile("a", "A", "10.0.0.11"), importProfile("a", "A", "10.0.0.12")},
+			opts:      ImportOptions{OnConflict: ConflictRename},
+			want:      []ImportChange{{Action: ImportRename, Name: "A (2)", SourceName: "A"}, {Action: ImportRename, Name: "A (3)", SourceName: "A"}},
+			wantNames: []string{"A", "B", "A (2)", "A (3)"},
+		},
+		{
+			// 新しいIDを発行する場合は名前のみで重複を判定する
+			name:      "regenerate IDs",
+			profiles:  []models.Profile{importProfile("a", "C", "10.0.0.30"), importProfile("", "A", "10.0.0.11")},
+			opts:      ImportOptions{RegenerateIDs: true},
+			want:      []ImportChange{{Action: ImportAdd, Name: "C"}, {Action: ImportSkip, Name: "A"}},
+			wantNames: []string{"A", "B", "C"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := importBaseConfig()
+			plan, err := PlanImport(cfg, &ExportDocument{Profiles: tt.profiles}, tt.opts)
+			if err != nil {
+				t.Fatalf("PlanImport() error = %v", err)
+			}
+			if len(plan.Issues) != 0 {
+				t.Errorf("Issues = %v, want none", plan.Issues)
+			}
+
+			if len(plan.Changes) != len(tt.want) {
+				t.Fatalf("Changes = %+v, want %+v", plan.Changes, tt.want)
+			}
+			ids := map[string]bool{}
+			for _, p := range plan.Result.Profiles {
+				if ids[p.ID] {
+					t.Errorf("duplicate ID %s in %+v", p.ID, plan.Result.Profiles)
+				}
+				ids[p.ID] = true
+			}
+			for i, change := range plan.Changes {
+				want := tt.want[i]
+				if want.ID == "" {
+					if change.ID == "" || change.ID == tt.profiles[i].ID {
+						t.Errorf("Changes[%d].ID = %q, want a new ID", i, change.ID)
+					}
+					want.ID = change.ID
+				}
+				if !reflect.DeepEqual(change, want) {
+					t.Errorf("Changes[%d] = %+v, want %+v", i, change, want)
+				}
+			}
+
+			var names []string
+			for _, p := range plan.Result.Profiles {
+				names = append(names, p.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("profiles = %q, want %q", names, tt.wantNames)
+			}
+			// 元の設定は変更しない
+			if !reflect.DeepEqual(cfg, importBaseConfig()) {
+				t.Errorf("cfg was modified: %+v", cfg.Profiles)
+			}
+			if !plan.Result.Profiles[1].IsManaged() {
+				t.Errorf("managed profile lost its source: %+v", plan.Result.Profiles[1])
+			}
+		})
+	}
+}
+
+func TestPlanImportIssues(t *testing.T) {
+	profiles := []models.Profile{
+		importProfile("c", "C", "10.0.0.30"),
+		importProfile("d", "D", "10.0.0.999"),
+	}
+	plan, err := PlanImport(importBaseConfig(), &ExportDocument{Profiles: profiles}, ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ファイル上の番号で問題を報告する
+	if len(plan.Issues) == 0 || plan.Issues.Err() == nil {
+		t.Fatalf("Issues = %v, want an error", plan.Issues)
+	}
+	for _, issue := range plan.Issues {
+		if issue.Path != "import[1].ipAddress" {
+			t.Errorf("issue = %s, want import[1].ipAddress", issue)
+		}
+	}
+}
+
+func TestPlanImportSettings(t *testing.T) {
+	settings := importBaseConfig().Settings
+	settings.LogLevel = "DEBUG"
+	doc := &ExportDocument{Profiles: []models.Profile{}, Settings: &settings}
+
+	for _, importSettings := range []bool{false, true} {
+		plan, err := PlanImport(importBaseConfig(), doc, ImportOptions{ImportSettings: importSettings})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plan.SettingsChanged != importSettings || (plan.Result.Settings.LogLevel == "DEBUG") != importSettings {
+			t.Errorf("ImportSettings = %t: SettingsChanged = %t, LogLevel = %q", importSettings, plan.SettingsChanged, plan.Result.Settings.LogLevel)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	setupConfigDir(t)
+	if _, err := UpdateConfig(func(cfg *models.Config) error {
+		cfg.Profiles = []models.Profile{importProfile("a", "A", "10.0.0.10")}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// エラーがある場合は保存しない
+	bad := &ExportDocument{Profiles: []models.Profile{importProfile("c", "C", "10.0.0.999")}}
+	var validationErr *models.ValidationError
+	if _, err := Import(bad, ImportOptions{}); !errors.As(err, &validationErr) {
+		t.Errorf("Import() error = %v, want *models.ValidationError", err)
+	}
+
+	doc := &ExportDocument{Profiles: []models.Profile{importProfile("c", "C", "10.0.0.30")}}
+	if _, err := Import(doc, ImportOptions{}); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Profiles) != 2 || cfg.Profiles[1].ID != "c" {
+		t.Errorf("profiles = %+v, want A and C", cfg.Profiles)
+	}
+}
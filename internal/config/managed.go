@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// managedProfilesDirName は集中管理されたプロファイルを置くディレクトリの名前です
+const managedProfilesDirName = "profiles"
+
+// ManagedProfilesDir はコンピューター全体で共有する、集中管理されたプロファイルのディレクトリを返します
+// （%ProgramData%\FastIPChange\profiles）。ProgramData が取得できない場合は空文字列を返します
+func ManagedProfilesDir() string {
+	programData := os.Getenv("ProgramData")
+	if programData == "" {
+		return ""
+	}
+	return filepath.Join(programData, configDirName, managedProfilesDirName)
+}
+
+// managedProfileFiles は集中管理されたプロファイルを読み込むファイルの一覧を返します
+// ManagedProfilesDir のファイル（名前順）の後に、settings.managedProfilesPath のファイルを読み込みます
+func managedProfileFiles(settings *models.Settings) []string {
+	files := profileFilesIn(ManagedProfilesDir())
+	if path := settings.ManagedProfilesPath; path != "" {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			files = append(files, profileFilesIn(path)...)
+		} else {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// profileFilesIn はディレクトリ内のJSONとCSVのファイルを名前順に返します（ディレクトリがない場合は空）
+func profileFilesIn(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("集中管理プロファイルのディレクトリを読み込めません", "dir", dir, "error", err)
+		}
+		return nil
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if strings.EqualFold(filepath.Ext(entry.Name()), ".json") || IsCSVPath(entry.Name()) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// loadManagedProfiles は集中管理されたプロファイルを読み込みます
+// ファイルの形式はエクスポートファイルと同じです。読み込めないファイルやプロファイルはログに記録して無視します
+func loadManagedProfiles(settings *models.Settings) []models.Profile {
+	var profiles []models.Profile
+	seen := make(map[string]string)
+	for _, path := range managedProfileFiles(settings) {
+		doc, err := ReadImportFile(path)
+		if err != nil {
+			logger.Warn("集中管理プロファイルを読み込めません", "path", path, "error", err)
+			continue
+		}
+		for _, profile := range doc.Profiles {
+			// 履歴や最後に適用したプロファイルの記録に使うため、IDは固定で指定する必要がある
+			if profile.ID == "" {
+				logger.Warn("IDのない集中管理プロファイルを無視しました", "path", path, "profile", profile.Name)
+				continue
+			}
+			if other, ok := seen[profile.ID]; ok {
+				logger.Warn("IDが重複する集中管理プロファイルを無視しました", "path", path, "profile", profile.Name, "id", profile.ID, "first", other)
+				continue
+			}
+			if err := profile.Normalize(); err != nil {
+				logger.Warn("集中管理プロファイルに問題があります", "path", path, "profile", profile.Name, "error", err)
+			}
+			seen[profile.ID] = path
+			profile.ManagedSource = path
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// mergeManagedProfiles は集中管理されたプロファイルを設定に追加します（ユーザーのプロファイルより前）
+// ユーザーのプロファイルで集中管理されたプロファイルを置き換えることはできません。
+// IDが重複するユーザーのプロファイルは ShadowedProfiles に移し、保存時にはそのまま書き戻します
+func mergeManagedProfiles(config *models.Config) {
+	managed := loadManagedProfiles(&config.Settings)
+	if len(managed) == 0 {
+		return
+	}
+
+	ids := make(map[string]bool, len(managed))
+	for _, profile := range managed {
+		ids[profile.ID] = true
+	}
+	profiles := managed
+	for _, profile := range config.Profiles {
+		if ids[profile.ID] {
+			logger.Warn("集中管理プロファイルとIDが重複するため、プロファイルを無効にしました", "profile", profile.Name, "id", profile.ID)
+			config.ShadowedProfiles = append(config.ShadowedProfiles, profile)
+			continue
+		}
+		profiles = append(profiles, profile)
+	}
+	config.Profiles = profiles
+}
+
+// storedProfiles は設定ファイルに保存するプロファイル（集中管理されたプロファイルを除く）を返します
+func storedProfiles(config *models.Config) []models.Profile {
+	profiles := make([]models.Profile, 0, len(config.Profiles)+len(config.ShadowedProfiles))
+	for _, profile := range config.Profiles {
+		if !profile.IsManaged() {
+			profiles = append(profiles, profile)
+		}
+	}
+	return append(profiles, config.ShadowedProfiles...)
+}
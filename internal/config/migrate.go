@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CurrentVersion は現在の設定ファイルのスキーマバージョンです
+const CurrentVersion = "1.1"
+
+// initialVersion はバージョンが記録されていない設定ファイルのバージョンです
+const initialVersion = "1.0"
+
+// ErrNewerVersion は設定ファイルがこのバージョンのアプリケーションより新しい形式の場合のエラーです
+var ErrNewerVersion = errors.New("設定ファイルが新しいバージョンのアプリケーションで保存されています")
+
+// document は移行処理で扱う設定ファイルの JSON 文書です
+// 構造体に存在しない旧形式のフィールドも扱えるよう、マップのまま変換します
+type document = map[string]any
+
+// migration は1つ前のバージョンから次のバージョンへの変換を表します
+type migration struct {
+	From    string
+	To      string
+	Migrate func(doc document) error
+}
+
+// migrations はバージョンごとの変換です（From の古い順）
+// フィールドを追加・変更する場合は CurrentVersion を上げ、ここに変換を追加してください
+var migrations = []migration{
+	{From: "1.0", To: "1.1", Migrate: migrateLegacyDNS},
+}
+
+// migrateDocument は設定ファイルの文書を CurrentVersion まで順に変換します
+// 変換前のバージョンを返します（変換しなかった場合は空文字列）
+func migrateDocument(doc document) (string, error) {
+	version, _ := doc["version"].(string)
+	if version == "" {
+		version = initialVersion
+	}
+	if version == CurrentVersion {
+		return "", nil
+	}
+
+	newer, err := isNewerVersion(version, CurrentVersion)
+	if err != nil {
+		return "", err
+	}
+	if newer {
+		return "", fmt.Errorf("%w（設定ファイル: %s, 対応: %s）", ErrNewerVersion, version, CurrentVersion)
+	}
+
+	original := version
+	for _, m := range migrations {
+		if m.From != version {
+			continue
+		}
+		if err := m.Migrate(doc); err != nil {
+			return "", fmt.Errorf("設定ファイルの移行に失敗（%s → %s）: %w", m.From, m.To, err)
+		}
+		version = m.To
+		doc["version"] = version
+	}
+	if version != CurrentVersion {
+		return "", fmt.Errorf("設定ファイルのバージョン %s から移行できません", original)
+	}
+	return original, nil
+}
+
+// isNewerVersion はバージョン a が b より新しいかどうかを判定します（"1.2" 形式）
+func isNewerVersion(a, b string) (bool, error) {
+	va, err := parseVersion(a)
+	if err != nil {
+		return false, err
+	}
+	vb, err := parseVersion(b)
+	if err != nil {
+		return false, err
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			return va[i] > vb[i], nil
+		}
+	}
+	return false, nil
+}
+
+// parseVersion は "メジャー.マイナー" 形式のバージョンを解析します
+func parseVersion(version string) ([2]int, error) {
+	var parsed [2]int
+	major, minor, found := strings.Cut(version, ".")
+	if !found {
+		return parsed, fmt.Errorf("設定ファイルのバージョンが不正です: %s", version)
+	}
+	for i, part := range []string{major, minor} {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return parsed, fmt.Errorf("設定ファイルのバージョンが不正です: %s", version)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
+// backupConfigFile は移行前の設定ファイルを <ファイル名>.v<バージョン>.bak として保存します
+// 同じバージョンのバックアップが既にある場合は、最初の状態を残すため上書きしません
+func backupConfigFile(configPath, version string, data []byte) error {
+	backupPath := fmt.Sprintf("%s.v%s.bak", configPath, version)
+	if _, err := os.Stat(backupPath); err == nil {
+		return nil
+	}
+	if err := WriteFileAtomic(backupPath, data, 0600); err != nil {
+		return fmt.Errorf("設定ファイルのバックアップに失敗: %w", err)
+	}
+	return nil
+}
+
+// migrateLegacyDNS は 1.0 の dnsPrimary / dnsSecondary を 1.1 の dnsServers へ移行します
+// dnsServers が既に設定されている場合は旧形式の値を破棄します
+func migrateLegacyDNS(doc document) error {
+	profiles, _ := doc["profiles"].([]any)
+	for _, item := range profiles {
+		profile, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		servers, _ := profile["dnsServers"].([]any)
+		if len(servers) == 0 {
+			for _, key := range []string{"dnsPrimary", "dnsSecondary"} {
+				if dns, _ := profile[key].(string); dns != "" {
+					servers = append(servers, dns)
+				}
+			}
+			if len(servers) > 0 {
+				profile["dnsServers"] = servers
+			}
+		}
+		delete(profile, "dnsPrimary")
+		delete(profile, "dnsSecondary")
+	}
+	return nil
+}
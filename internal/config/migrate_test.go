@@ -0,0 +1,205 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// parseDocument は JSON を移行処理の文書に変換します
+func parseDocument(t *testing.T, s string) document {
+	t.Helper()
+	var doc document
+	if err := json.Unmarshal([]byte(s), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestMigrateDocument(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantFrom string
+		want     string
+	}{
+		{
+			name: "1.0 legacy DNS",
+			input: `{"version": "1.0", "profiles": [
+				{"name": "A", "dnsPrimary": "10.0.0.53", "dnsSecondary": "8.8.8.8"},
+				{"name": "B", "dnsPrimary": "10.0.0.53"},
+				{"name": "C", "dnsPrimary": "", "dnsSecondary": ""}]}`,
+			wantFrom: "1.0",
+			want: `{"version": "1.1", "profiles": [
+				{"name": "A", "dnsServers": ["10.0.0.53", "8.8.8.8"]},
+				{"name": "B", "dnsServers": ["10.0.0.53"]},
+				{"name": "C"}]}`,
+		},
+		{
+			// dnsServers が設定済みの場合は旧形式の値を破棄する
+			name:     "1.0 with dnsServers",
+			input:    `{"version": "1.0", "profiles": [{"name": "A", "dnsServers": ["1.1.1.1"], "dnsPrimary": "10.0.0.53"}]}`,
+			wantFrom: "1.0",
+			want:     `{"version": "1.1", "profiles": [{"name": "A", "dnsServers": ["1.1.1.1"]}]}`,
+		},
+		{
+			// バージョンが記録されていない場合は 1.0 として扱う
+			name:     "no version",
+			input:    `{"profiles": [{"name": "A", "dnsSecondary": "8.8.8.8"}]}`,
+			wantFrom: "1.0",
+			want:     `{"version": "1.1", "profiles": [{"name": "A", "dnsServers": ["8.8.8.8"]}]}`,
+		},
+		{
+			name:     "no profiles",
+			input:    `{"version": "1.0", "settings": {"logLevel": "INFO"}}`,
+			wantFrom: "1.0",
+			want:     `{"version": "1.1", "settings": {"logLevel": "INFO"}}`,
+		},
+		{
+			// 現在のバージョンは変更しない（dnsPrimary のような未知の項目も残す）
+			name:  "current",
+			input: `{"version": "1.1", "profiles": [{"name": "A", "dnsServers": ["10.0.0.53"], "dnsPrimary": "8.8.8.8"}]}`,
+			want:  `{"version": "1.1", "profiles": [{"name": "A", "dnsServers": ["10.0.0.53"], "dnsPrimary": "8.8.8.8"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := parseDocument(t, tt.input)
+			from, err := migrateDocument(doc)
+			if err != nil {
+				t.Fatalf("migrateDocument() error = %v", err)
+			}
+			if from != tt.wantFrom {
+				t.Errorf("migrateDocument() = %q, want %q", from, tt.wantFrom)
+			}
+			if want := parseDocument(t, tt.want); !reflect.DeepEqual(doc, want) {
+				got, _ := json.Marshal(doc)
+				t.Errorf("document = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateDocumentErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantNewer bool
+	}{
+		{"newer minor", `{"version": "1.2"}`, true},
+		{"newer major", `{"version": "2.0"}`, true},
+		{"malformed", `{"version": "1.x"}`, false},
+		// 変換が登録されていない古いバージョン
+		{"unknown older", `{"version": "0.9"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := parseDocument(t, tt.input)
+			_, err := migrateDocument(doc)
+			if err == nil {
+				t.Fatal("migrateDocument() error = nil")
+			}
+			if errors.Is(err, ErrNewerVersion) != tt.wantNewer {
+				t.Errorf("migrateDocument() error = %v, ErrNewerVersion = %t", err, tt.wantNewer)
+			}
+		})
+	}
+}
+
+func TestIsNewerVersion(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		want    bool
+		wantErr bool
+	}{
+		{a: "1.1", b: "1.0", want: true},
+		{a: "1.0", b: "1.1", want: false},
+		{a: "1.1", b: "1.1", want: false},
+		// 数値として比較する
+		{a: "1.10", b: "1.9", want: true},
+		{a: "1.9", b: "1.10", want: false},
+		{a: "2.0", b: "1.99", want: true},
+		{a: "01.2", b: "1.1", want: true},
+		{a: "1", b: "1.0", wantErr: true},
+		{a: "1.0.1", b: "1.0", wantErr: true},
+		{a: "1.-1", b: "1.0", wantErr: true},
+		{a: "", b: "1.0", wantErr: true},
+		{a: "1.0", b: "v1.1", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := isNewerVersion(tt.a, tt.b)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("isNewerVersion(%q, %q) = %t, %v, want %t (error: %t)", tt.a, tt.b, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadConfigFileMigratesAndBacksUp(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "settings.json")
+	backupPath := configPath + ".v1.0.bak"
+	original := []byte(`{"version": "1.0", "profiles": [{"id": "a", "name": "A", "dnsPrimary": "10.0.0.53", "dnsSecondary": "8.8.8.8"}]}`)
+	if err := os.WriteFile(configPath, original, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, migrated, err := loadConfigFile(configPath)
+	if err != nil {
+		t.Fatalf("loadConfigFile() error = %v", err)
+	}
+	if !migrated || config.Version != CurrentVersion {
+		t.Errorf("loadConfigFile() migrated = %t, version = %s, want true, %s", migrated, config.Version, CurrentVersion)
+	}
+	if got := config.Profiles[0].DNSServers; !slices.Equal(got, []string{"10.0.0.53", "8.8.8.8"}) {
+		t.Errorf("DNSServers = %q", got)
+	}
+	if backup, err := os.ReadFile(backupPath); err != nil || string(backup) != string(original) {
+		t.Fatalf("backup = %q, %v, want the original file", backup, err)
+	}
+
+	// 2回目の移行では最初のバックアップを上書きしない
+	if err := os.WriteFile(configPath, []byte(`{"version": "1.0", "profiles": []}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := loadConfigFile(configPath); err != nil {
+		t.Fatalf("loadConfigFile() error = %v", err)
+	}
+	if backup, err := os.ReadFile(backupPath); err != nil || string(backup) != string(original) {
+		t.Errorf("backup = %q, %v, want the first original file", backup, err)
+	}
+}
+
+func TestLoadConfigFileCurrentVersion(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "settings.json")
+	if err := os.WriteFile(configPath, []byte(`{"version": "1.1", "profiles": [{"id": "a", "name": "A"}]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, migrated, err := loadConfigFile(configPath)
+	if err != nil || migrated {
+		t.Errorf("loadConfigFile() migrated = %t, error = %v, want false, nil", migrated, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("files = %d, want only settings.json (no backup)", len(entries))
+	}
+}
+
+func TestLoadConfigFileNewerVersion(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "settings.json")
+	data := []byte(`{"version": "1.2", "profiles": []}`)
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := loadConfigFile(configPath); !errors.Is(err, ErrNewerVersion) {
+		t.Errorf("loadConfigFile() error = %v, want ErrNewerVersion", err)
+	}
+	// 新しい形式のファイルは変更しない
+	if current, _ := os.ReadFile(configPath); string(current) != string(data) {
+		t.Errorf("settings.json = %q, want unchanged", current)
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+const stateFileName = "state.json"
+
+// State はアプリケーションが実行時に記録する状態を表します
+// 利用者が編集する settings.json とは別のファイルに保存します
+type State struct {
+	// Routes はNICごとにプロファイルが追加した静的ルートです
+	Routes map[string]InstalledRoutes `json:"routes,omitempty"`
+	// ScheduleRuns はスケジュールIDごとに最後に実行した切り替えの予定時刻です（再起動後に同じ切り替えを繰り返さないため）
+	ScheduleRuns map[string]time.Time `json:"scheduleRuns,omitempty"`
+}
+
+// InstalledRoutes はプロファイルがNICに追加した静的ルートを表します
+type InstalledRoutes struct {
+	Profile string               `json:"profile"`
+	Routes  []models.StaticRoute `json:"routes"`
+}
+
+// GetStatePath は状態ファイルのパスを返します
+func GetStatePath() (string, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), stateFileName), nil
+}
+
+// LoadState は状態ファイルを読み込みます
+// ファイルが存在しない場合は空の状態を返します
+func LoadState() (*State, error) {
+	statePath, err := GetStatePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("状態ファイルの読み込みに失敗: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("状態ファイルの解析に失敗: %w", err)
+	}
+	return &state, nil
+}
+
+// SaveState は状態ファイルを保存します
+func SaveState(state *State) error {
+	statePath, err := GetStatePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("状態のシリアライズに失敗: %w", err)
+	}
+
+	if err := WriteFileAtomic(statePath, data, 0600); err != nil {
+		return fmt.Errorf("状態ファイルの書き込みに失敗: %w", err)
+	}
+	return nil
+}
+
+// UpdateState は状態ファイルをロックした状態で読み込み、fn で変更して保存します
+func UpdateState(fn func(state *State) error) error {
+	statePath, err := GetStatePath()
+	if err != nil {
+		return err
+	}
+
+	return WithFileLock(statePath, func() error {
+		state, err := LoadState()
+		if err != nil {
+			return err
+		}
+		if err := fn(state); err != nil {
+			return err
+		}
+		return SaveState(state)
+	})
+}
+
+// SetScheduleRun はスケジュールを実行した予定時刻を記録します
+func SetScheduleRun(scheduleID string, at time.Time) error {
+	return UpdateState(func(state *State) error {
+		if state.ScheduleRuns == nil {
+			state.ScheduleRuns = make(map[string]time.Time)
+		}
+		state.ScheduleRuns[scheduleID] = at
+		return nil
+	})
+}
+
+// RouteStore は状態ファイルにプロファイルが追加したルートを記録します（network.RouteStore の実装）
+type RouteStore struct{}
+
+// InstalledRoutes は指定されたNICに追加済みのルートと、追加したプロファイル名を返します
+func (RouteStore) InstalledRoutes(nicName string) (string, []models.StaticRoute, error) {
+	state, err := LoadState()
+	if err != nil {
+		return "", nil, err
+	}
+	installed := state.Routes[nicName]
+	return installed.Profile, installed.Routes, nil
+}
+
+// SetInstalledRoutes は指定されたNICに追加したルートを記録します
+func (RouteStore) SetInstalledRoutes(nicName, owner string, routes []models.StaticRoute) error {
+	return UpdateState(func(state *State) error {
+		if len(routes) == 0 {
+			delete(state.Routes, nicName)
+			return nil
+		}
+		if state.Routes == nil {
+			state.Routes = make(map[string]InstalledRoutes)
+		}
+		state.Routes[nicName] = InstalledRoutes{Profile: owner, Routes: routes}
+		return nil
+	})
+}
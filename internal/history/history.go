@@ -0,0 +1,349 @@
+// Package history はプロファイル・DHCPの切り替え履歴を記録し、直前の設定への復元を提供します
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/config"
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/internal/network"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+	"github.com/google/uuid"
+)
+
+const historyFileName = "history.json"
+
+// MaxEntries は保持する履歴の最大件数です（古いものから削除）
+const MaxEntries = 100
+
+// Action は履歴に記録する操作の種類です
+type Action string
+
+const (
+	ActionProfile Action = "profile" // プロファイルの適用
+	ActionDHCP    Action = "dhcp"    // DHCPへの切り替え
+	ActionUndo    Action = "undo"    // 直前の設定への復元
+)
+
+// ErrNothingToUndo は復元できる履歴がない場合のエラーです
+var ErrNothingToUndo = errors.New("復元できる履歴がありません")
+
+// applyMu は切り替えを1つずつ実行するためのロックです
+// 手動・自動切り替え・スケジュールの切り替えが同時に実行されると、変更前の設定の記録や
+// 失敗時の復元が他の切り替えと混ざるため、ApplyProfile・ApplyDHCP・ApplyScene・Undo はすべてこのロックを取得します
+var applyMu sync.Mutex
+
+// client は切り替えと設定の取得に使用する Client です（テストでは FakeRunner を使用する Client に差し替えます）
+var client = network.DefaultClient()
+
+// Entry は1回の切り替えの記録を表します
+type Entry struct {
+	ID          string            `json:"id"`
+	Time        time.Time         `json:"time"`
+	Action      Action            `json:"action"`
+	NICName     string            `json:"nicName"`
+	ProfileID   string            `json:"profileId,omitempty"`
+	ProfileName string            `json:"profileName,omitempty"`
+	UndoOf      string            `json:"undoOf,omitempty"` // 復元の場合、元に戻した履歴の ID
+	SceneName   string            `json:"sceneName,omitempty"`
+	Batch       string            `json:"batch,omitempty"` // シーンで同時に切り替えた履歴に共通の ID
+	Before      *network.Snapshot `json:"before,omitempty"`
+	After       *network.Snapshot `json:"after,omitempty"`
+	// Health は適用後の疎通確認の結果です（疎通確認がない場合は省略）
+	Health    *network.HealthReport `json:"health,omitempty"`
+	Success   bool                  `json:"success"`
+	ErrorCode string                `json:"errorCode,omitempty"`
+	Error     string                `json:"error,omitempty"`
+}
+
+// Description は履歴の内容を表示用の文字列で返します
+func (e *Entry) Description() string {
+	var description string
+	switch e.Action {
+	case ActionProfile:
+		description = fmt.Sprintf("%s に %s を適用", e.NICName, e.ProfileName)
+	case ActionDHCP:
+		description = fmt.Sprintf("%s をDHCPに切り替え", e.NICName)
+	case ActionUndo:
+		description = fmt.Sprintf("%s を直前の設定に復元", e.NICName)
+	default:
+		description = string(e.Action)
+	}
+	if e.SceneName != "" {
+		return fmt.Sprintf("シーン「%s」: %s", e.SceneName, description)
+	}
+	return description
+}
+
+// InBatch はシーンで他のNICと同時に切り替えた履歴かどうかを返します
+// Undo では同じ Batch の履歴をまとめて元に戻します
+func (e *Entry) InBatch() bool {
+	return e.Batch != ""
+}
+
+// Undoable は変更前の設定に戻せる履歴かどうかを返します
+func (e *Entry) Undoable() bool {
+	return e.Success && e.Before != nil
+}
+
+// GetHistoryPath は履歴ファイルのパスを返します（settings.json と同じディレクトリ）
+func GetHistoryPath() (string, error) {
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), historyFileName), nil
+}
+
+// Load は履歴を古い順に読み込みます
+// ファイルが存在しない場合は空の履歴を返します
+func Load() ([]Entry, error) {
+	historyPath, err := GetHistoryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(historyPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("履歴ファイルの読み込みに失敗: %w", err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("履歴ファイルの解析に失敗: %w", err)
+	}
+	return entries, nil
+}
+
+// save は履歴を保存します
+func save(entries []Entry) error {
+	historyPath, err := GetHistoryPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("履歴のシリアライズに失敗: %w", err)
+	}
+
+	if err := config.WriteFileAtomic(historyPath, data, 0600); err != nil {
+		return fmt.Errorf("履歴ファイルの書き込みに失敗: %w", err)
+	}
+	return nil
+}
+
+// Append は履歴を追加します（MaxEntries を超えた分は古いものから削除）
+func Append(added ...Entry) error {
+	historyPath, err := GetHistoryPath()
+	if err != nil {
+		return err
+	}
+
+	// トレイとコマンドラインから同時に追加されても履歴が失われないようにロックする
+	return config.WithFileLock(historyPath, func() error {
+		entries, err := Load()
+		if err != nil {
+			// 壊れた履歴で切り替え操作を止めないよう、作り直す
+			logger.Warn("履歴の読み込みに失敗したため、新しく作成します", "error", err)
+			entries = nil
+		}
+
+		entries = append(entries, added...)
+		if len(entries) > MaxEntries {
+			entries = entries[len(entries)-MaxEntries:]
+		}
+		return save(entries)
+	})
+}
+
+// LastUndoable は復元対象となる最新の履歴を返します（ない場合は nil）
+func LastUndoable(entries []Entry) *Entry {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Undoable() {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+// ApplyProfile はプロファイルを適用し、変更前後の設定と結果を履歴に記録します
+// プロファイルに疎通確認がある場合はその結果も記録して返します（疎通確認がない場合は nil）
+func ApplyProfile(profile *models.Profile) (*network.HealthReport, error) {
+	applyMu.Lock()
+	defer applyMu.Unlock()
+
+	entry := newEntry(ActionProfile, profile.NICName)
+	entry.ProfileID = profile.ID
+	entry.ProfileName = profile.Name
+
+	entry.Before = takeSnapshot(profile.NICName)
+	report, err := client.ApplyProfileWithSnapshot(profile, entry.Before)
+	entry.Health = report
+	record(entry, err)
+	return report, err
+}
+
+// ApplyDHCP はNICをDHCPに切り替え、変更前後の設定と結果を履歴に記録します
+func ApplyDHCP(nicName string) error {
+	applyMu.Lock()
+	defer applyMu.Unlock()
+
+	entry := newEntry(ActionDHCP, nicName)
+
+	entry.Before = takeSnapshot(nicName)
+	err := client.ApplyDHCP(nicName)
+	record(entry, err)
+	return err
+}
+
+// ApplyScene はシーンを適用し、NICごとに変更前後の設定と結果を履歴に記録します
+// 同じシーンの履歴には共通の Batch を設定し、Undo でまとめて元に戻せるようにします。
+// シーンが失敗した場合は、適用後に元に戻したNICも含めてすべて失敗として記録します
+func ApplyScene(scene *models.Scene, targets []models.SceneTarget) ([]network.SceneStepResult, error) {
+	applyMu.Lock()
+	defer applyMu.Unlock()
+
+	results, err := client.ApplyScene(targets)
+
+	batch := uuid.New().String()
+	entries := make([]Entry, 0, len(results))
+	for _, result := range results {
+		entry := newEntry(ActionDHCP, result.Target.NICName)
+		if profile := result.Target.Profile; profile != nil {
+			entry.Action = ActionProfile
+			entry.ProfileID = profile.ID
+			entry.ProfileName = profile.Name
+		}
+		entry.SceneName = scene.Name
+		entry.Batch = batch
+		entry.Before = result.Before
+		entry.Health = result.Health
+		setResult(entry, err)
+		entries = append(entries, *entry)
+	}
+	appendEntries(entries...)
+	return results, err
+}
+
+// Undo は最新の切り替えの前に記録した設定を再適用します
+// シーンの履歴の場合は、同じシーンで切り替えたすべてのNICを逆の順序で元に戻します。
+// 復元も履歴に記録されるため、続けて実行すると復元前の設定に戻ります
+func Undo() (*Entry, error) {
+	applyMu.Lock()
+	defer applyMu.Unlock()
+
+	entries, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	target := LastUndoable(entries)
+	if target == nil {
+		return nil, ErrNothingToUndo
+	}
+
+	targets := []*Entry{target}
+	var batch string
+	if target.InBatch() {
+		targets = batchEntries(entries, target.Batch)
+		batch = uuid.New().String()
+	}
+
+	var (
+		errs []error
+		undo []Entry
+	)
+	for i := len(targets) - 1; i >= 0; i-- {
+		t := targets[i]
+		logger.Info("直前の設定に戻します", "nic", t.NICName, "history", t.ID, "action", t.Action)
+
+		entry := newEntry(ActionUndo, t.NICName)
+		entry.UndoOf = t.ID
+		entry.SceneName = t.SceneName
+		entry.Batch = batch
+		entry.Before = takeSnapshot(t.NICName)
+		err := client.RestoreSnapshot(t.Before)
+		setResult(entry, err)
+		undo = append(undo, *entry)
+
+		if err != nil {
+			if len(targets) == 1 {
+				errs = append(errs, err)
+			} else {
+				errs = append(errs, fmt.Errorf("%s: %w", t.NICName, err))
+			}
+		}
+	}
+	appendEntries(undo...)
+	return target, errors.Join(errs...)
+}
+
+// batchEntries は Batch が一致する、元に戻せる履歴を古い順に返します
+func batchEntries(entries []Entry, batch string) []*Entry {
+	var batchEntries []*Entry
+	for i := range entries {
+		if entries[i].Batch == batch && entries[i].Undoable() {
+			batchEntries = append(batchEntries, &entries[i])
+		}
+	}
+	return batchEntries
+}
+
+// newEntry は現在時刻で新しい履歴を作成します
+func newEntry(action Action, nicName string) *Entry {
+	return &Entry{
+		ID:      uuid.New().String(),
+		Time:    time.Now(),
+		Action:  action,
+		NICName: nicName,
+	}
+}
+
+// takeSnapshot は履歴用に現在の設定を取得します（失敗した場合は nil）
+func takeSnapshot(nicName string) *network.Snapshot {
+	snapshot, err := client.TakeSnapshot(nicName)
+	if err != nil {
+		logger.Warn("履歴用の設定取得に失敗", "nic", nicName, "error", err)
+		return nil
+	}
+	return snapshot
+}
+
+// record は操作の結果と変更後の設定を履歴に追加します
+func record(entry *Entry, err error) {
+	setResult(entry, err)
+	appendEntries(*entry)
+}
+
+// setResult は操作の結果と変更後の設定を履歴に設定します
+func setResult(entry *Entry, err error) {
+	entry.Success = err == nil
+	if err != nil {
+		entry.Error = err.Error()
+		var netErr *network.NetworkError
+		if errors.As(err, &netErr) {
+			entry.ErrorCode = netErr.Code
+		}
+	}
+	entry.After = takeSnapshot(entry.NICName)
+}
+
+// appendEntries は履歴を追加します
+// 履歴の保存に失敗しても切り替えの結果には影響させません
+func appendEntries(entries ...Entry) {
+	if len(entries) == 0 {
+		return
+	}
+	if err := Append(entries...); err != nil {
+		logger.Warn("履歴の保存に失敗", "error", err)
+	}
+}
@@ -0,0 +1,177 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/network"
+	"github.com/fast-ip-change/fast-ip-change/internal/network/networktest"
+)
+
+// setupHistory は履歴ファイルを一時ディレクトリに作成し、runner を使用する Client に差し替えます
+func setupHistory(t *testing.T, runner *networktest.FakeRunner) {
+	t.Helper()
+	dir := t.TempDir()
+	// os.UserConfigDir が返すディレクトリ（Windows では AppData、それ以外では XDG_CONFIG_HOME）
+	t.Setenv("AppData", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	saved := client
+	client = network.NewClient(runner)
+	t.Cleanup(func() { client = saved })
+}
+
+// dhcpEntry は DHCP から切り替えた、元に戻せる履歴を作成します
+func dhcpEntry(id, nicName, batch string) Entry {
+	return Entry{
+		ID:      id,
+		Time:    time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC),
+		Action:  ActionProfile,
+		NICName: nicName,
+		Batch:   batch,
+		Before:  &network.Snapshot{NICName: nicName, DHCPEnabled: true},
+		Success: true,
+	}
+}
+
+// dhcpCommand は NIC を DHCP に切り替えるコマンドです
+func dhcpCommand(nicName string) string {
+	return "netsh interface ipv4 set address name=" + nicName + " source=dhcp"
+}
+
+// restoreCommands は記録されたコマンドのうち、DHCP への切り替えを返します
+func restoreCommands(runner *networktest.FakeRunner) []string {
+	var cmds []string
+	for _, cmd := range runner.Commands() {
+		if strings.HasSuffix(cmd, "source=dhcp") && strings.Contains(cmd, "set address") {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds
+}
+
+func TestUndoBatch(t *testing.T) {
+	runner := networktest.NewFakeRunner().
+		On("netsh interface ipv4 set", "")
+	setupHistory(t, runner)
+
+	// シーンで Ethernet、Wi-Fi、Ethernet 2 の順に切り替えた（Ethernet 2 は失敗）
+	failed := dhcpEntry("s3", "Ethernet 2", "scene")
+	failed.Success = false
+	if err := Append(dhcpEntry("old", "Ethernet", ""), dhcpEntry("s1", "Ethernet", "scene"), dhcpEntry("s2", "Wi-Fi", "scene"), failed); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := Undo()
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if target.ID != "s2" {
+		t.Errorf("Undo() target = %s, want s2", target.ID)
+	}
+	// 切り替えと逆の順序で元に戻し、失敗した履歴と別のバッチは対象にしない
+	want := []string{dhcpCommand("Wi-Fi"), dhcpCommand("Ethernet")}
+	if got := restoreCommands(runner); !slices.Equal(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	undo := entries[len(entries)-2:]
+	if undo[0].UndoOf != "s2" || undo[1].UndoOf != "s1" {
+		t.Errorf("UndoOf = %s, %s, want s2, s1", undo[0].UndoOf, undo[1].UndoOf)
+	}
+	for _, e := range undo {
+		if e.Action != ActionUndo || !e.Success || e.SceneName != "" {
+			t.Errorf("undo entry = %+v", e)
+		}
+	}
+	// 復元の履歴には新しい共通のバッチを設定する
+	if undo[0].Batch == "" || undo[0].Batch == "scene" || undo[0].Batch != undo[1].Batch {
+		t.Errorf("Batch = %q, %q, want a new shared batch", undo[0].Batch, undo[1].Batch)
+	}
+}
+
+func TestUndoBatchPartialFailure(t *testing.T) {
+	restoreErr := errors.New("exit status 1")
+	runner := networktest.NewFakeRunner().
+		OnError(dhcpCommand("Ethernet"), "", restoreErr).
+		On("netsh interface ipv4 set", "")
+	setupHistory(t, runner)
+
+	if err := Append(dhcpEntry("s1", "Ethernet", "scene"), dhcpEntry("s2", "Wi-Fi", "scene")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Undo()
+	// 失敗したNICの名前をつけてエラーをまとめ、他のNICは元に戻す
+	if !errors.Is(err, restoreErr) || !strings.HasPrefix(err.Error(), "Ethernet: ") {
+		t.Fatalf("Undo() error = %v, want Ethernet: ...", err)
+	}
+	want := []string{dhcpCommand("Wi-Fi"), dhcpCommand("Ethernet")}
+	if got := restoreCommands(runner); !slices.Equal(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	undo := entries[len(entries)-2:]
+	if !undo[0].Success || undo[1].Success || undo[1].ErrorCode != "APPLY_DHCP_FAILED" {
+		t.Errorf("undo entries = %+v", undo)
+	}
+}
+
+func TestUndoSingle(t *testing.T) {
+	runner := networktest.NewFakeRunner().
+		On("netsh interface ipv4 set", "")
+	setupHistory(t, runner)
+
+	if _, err := Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo() error = %v, want ErrNothingToUndo", err)
+	}
+
+	if err := Append(dhcpEntry("a", "Ethernet", ""), dhcpEntry("b", "Wi-Fi", "")); err != nil {
+		t.Fatal(err)
+	}
+	target, err := Undo()
+	if err != nil || target.ID != "b" {
+		t.Fatalf("Undo() = %+v, %v, want b", target, err)
+	}
+	if got, want := restoreCommands(runner), []string{dhcpCommand("Wi-Fi")}; !slices.Equal(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+
+	entries, _ := Load()
+	if last := entries[len(entries)-1]; last.UndoOf != "b" || last.Batch != "" {
+		t.Errorf("undo entry = %+v, want UndoOf b without batch", last)
+	}
+}
+
+func TestAppendTrimsOldEntries(t *testing.T) {
+	setupHistory(t, networktest.NewFakeRunner())
+
+	for i := range MaxEntries + 5 {
+		if err := Append(dhcpEntry(fmt.Sprint(i), "Ethernet", "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != MaxEntries {
+		t.Fatalf("entries = %d, want %d", len(entries), MaxEntries)
+	}
+	// 古いものから削除する
+	if entries[0].ID != "5" || entries[len(entries)-1].ID != fmt.Sprint(MaxEntries+4) {
+		t.Errorf("entries = %s .. %s, want 5 .. %d", entries[0].ID, entries[len(entries)-1].ID, MaxEntries+4)
+	}
+}
@@ -38,6 +38,16 @@ func ParseLogLevel(level string) slog.Level {
 // Init はロガーを初期化します
 // logLevel: ログレベル（"DEBUG", "INFO", "WARN", "ERROR"）。空の場合はINFOがデフォルト
 func Init(logLevel string) error {
+	return initLogger(logLevel, true)
+}
+
+// InitFileOnly はログファイルのみに出力するロガーを初期化します
+// コマンドラインの出力（JSONなど）にログが混ざらないようにする場合に使用します
+func InitFileOnly(logLevel string) error {
+	return initLogger(logLevel, false)
+}
+
+func initLogger(logLevel string, withStdout bool) error {
 	appData, err := os.UserConfigDir()
 	if err != nil {
 		return fmt.Errorf("設定ディレクトリの取得に失敗: %w", err)
@@ -58,13 +68,16 @@ func Init(logLevel string) error {
 	}
 
 	// 標準出力とファイルの両方に出力するMultiWriterを作成
-	multiWriter := io.MultiWriter(os.Stdout, logFile)
+	var writer io.Writer = logFile
+	if withStdout {
+		writer = io.MultiWriter(os.Stdout, logFile)
+	}
 
 	// ログレベルを解析
 	level := ParseLogLevel(logLevel)
 
-	// slogハンドラーを作成（標準出力とファイル両方に出力）
-	logger = slog.New(slog.NewTextHandler(multiWriter, &slog.HandlerOptions{
+	// slogハンドラーを作成
+	logger = slog.New(slog.NewTextHandler(writer, &slog.HandlerOptions{
 		Level: level,
 	}))
 
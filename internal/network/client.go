@@ -0,0 +1,97 @@
+package network
+
+import "github.com/fast-ip-change/fast-ip-change/pkg/models"
+
+// Client は Runner を介してNICの設定を取得・変更します
+type Client struct {
+	runner Runner
+	routes RouteStore
+	// prober は適用前にIPアドレスの使用状況を確認します（nil の場合は arp と ping で確認）
+	prober AddressProber
+	// conflictPolicy はIPアドレスの重複を検出した場合の動作です（空の場合は中止）
+	conflictPolicy string
+	onWarning      func(message string)
+}
+
+// NewClient は指定された Runner を使用する Client を作成します
+// runner が nil の場合は ExecRunner を使用します
+func NewClient(runner Runner) *Client {
+	if runner == nil {
+		runner = ExecRunner{}
+	}
+	return &Client{runner: runner}
+}
+
+// defaultClient はパッケージレベル関数が使用する Client です
+var defaultClient = NewClient(nil)
+
+// DefaultClient は実際のコマンドを実行する既定の Client を返します
+func DefaultClient() *Client {
+	return defaultClient
+}
+
+// GetNICList は既定の Client で利用可能なNICのリストを取得します
+func GetNICList() ([]NIC, error) {
+	return defaultClient.GetNICList()
+}
+
+// GetCurrentIPConfig は既定の Client で指定されたNICの現在のIP設定を取得します
+func GetCurrentIPConfig(nicName string) (*models.Profile, error) {
+	return defaultClient.GetCurrentIPConfig(nicName)
+}
+
+// ApplyProfile は既定の Client でプロファイルの設定をNICに適用します
+func ApplyProfile(profile *models.Profile) error {
+	return defaultClient.ApplyProfile(profile)
+}
+
+// ApplyProfileWithSnapshot は既定の Client で取得済みのスナップショットをロールバックに使用してプロファイルを適用します
+// プロファイルに疎通確認がある場合はその結果も返します
+func ApplyProfileWithSnapshot(profile *models.Profile, snapshot *Snapshot) (*HealthReport, error) {
+	return defaultClient.ApplyProfileWithSnapshot(profile, snapshot)
+}
+
+// ApplyDHCP は既定の Client で指定されたNICをDHCPに切り替えます
+func ApplyDHCP(nicName string) error {
+	return defaultClient.ApplyDHCP(nicName)
+}
+
+// TakeSnapshot は既定の Client で指定されたNICの現在の設定を取得します
+func TakeSnapshot(nicName string) (*Snapshot, error) {
+	return defaultClient.TakeSnapshot(nicName)
+}
+
+// RestoreSnapshot は既定の Client でスナップショットの設定をNICに適用します
+func RestoreSnapshot(snapshot *Snapshot) error {
+	return defaultClient.RestoreSnapshot(snapshot)
+}
+
+// ApplyScene は既定の Client で複数のNICに順に設定を適用します（失敗時はすべて元に戻します）
+func ApplyScene(targets []models.SceneTarget) ([]SceneStepResult, error) {
+	return defaultClient.ApplyScene(targets)
+}
+
+// PlanScene は既定の Client でシーン適用時の各NICのコマンドと差分を取得します
+func PlanScene(targets []models.SceneTarget) ([]*Plan, error) {
+	return defaultClient.PlanScene(targets)
+}
+
+// GetEnvironment は既定の Client でNICが接続しているネットワークの情報を取得します
+func GetEnvironment(nicName string) (*Environment, error) {
+	return defaultClient.GetEnvironment(nicName)
+}
+
+// GetWirelessSSIDs は既定の Client で無線NICの名前とSSIDの対応を取得します
+func GetWirelessSSIDs() (map[string]string, error) {
+	return defaultClient.GetWirelessSSIDs()
+}
+
+// PlanProfile は既定の Client でプロファイル適用時のコマンドと差分を取得します
+func PlanProfile(profile *models.Profile) (*Plan, error) {
+	return defaultClient.PlanProfile(profile)
+}
+
+// PlanDHCP は既定の Client でDHCP切り替え時のコマンドと差分を取得します
+func PlanDHCP(nicName string) (*Plan, error) {
+	return defaultClient.PlanDHCP(nicName)
+}
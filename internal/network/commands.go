@@ -0,0 +1,116 @@
+package network
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// Command はNICの設定を変更するために発行する外部コマンドを表します
+type Command struct {
+	Name string   `json:"name"`
+	Args []string `json:"args"`
+}
+
+// String はコマンドラインを空白区切りの文字列で返します
+func (cmd Command) String() string {
+	return strings.Join(append([]string{cmd.Name}, cmd.Args...), " ")
+}
+
+// netshCommand は netsh コマンドを作成します
+func netshCommand(args ...string) Command {
+	return Command{Name: "netsh", Args: args}
+}
+
+// powershellCommand は PowerShell のスクリプトを実行するコマンドを作成します
+func powershellCommand(script string) Command {
+	return Command{Name: "powershell", Args: []string{"-NoProfile", "-NonInteractive", "-Command", script}}
+}
+
+// run はコマンドを実行して標準出力と標準エラー出力をまとめて返します
+func (c *Client) run(cmd Command) ([]byte, error) {
+	return c.runner.CombinedOutput(cmd.Name, cmd.Args...)
+}
+
+// ipSettingsCommand はIPアドレス、サブネットマスク、ゲートウェイを設定するコマンドを返します
+func ipSettingsCommand(profile *models.Profile) Command {
+	args := []string{"interface", "ipv4", "set", "address",
+		"name=" + profile.NICName,
+		"static",
+		profile.IPAddress,
+		profile.SubnetMask}
+	if profile.Gateway != "" {
+		args = append(args, profile.Gateway)
+	}
+	return netshCommand(args...)
+}
+
+// additionalAddressCommands はプライマリ以外のアドレスを追加するコマンドを返します
+// set address static は既存のアドレスを置き換えるため、その後に実行する必要があります
+func additionalAddressCommands(profile *models.Profile) []Command {
+	var cmds []Command
+	for _, entry := range profile.AdditionalAddresses {
+		cmds = append(cmds, netshCommand("interface", "ipv4", "add", "address",
+			"name="+profile.NICName,
+			entry.IPAddress,
+			entry.SubnetMask))
+	}
+	return cmds
+}
+
+// dnsSettingsCommands はDNSサーバーを設定するコマンドを返します
+// 1台目を set dns で置き換え、2台目以降を index を増やしながら add dns で追加します
+func dnsSettingsCommands(profile *models.Profile) []Command {
+	var cmds []Command
+	for i, dns := range profile.DNSServers {
+		if i == 0 {
+			cmds = append(cmds, netshCommand("interface", "ipv4", "set", "dns",
+				"name="+profile.NICName,
+				"static",
+				dns))
+			continue
+		}
+		cmds = append(cmds, netshCommand("interface", "ipv4", "add", "dns",
+			"name="+profile.NICName,
+			dns,
+			"index="+strconv.Itoa(i+1)))
+	}
+	if cmd, ok := dnsClientCommand(profile); ok {
+		cmds = append(cmds, cmd)
+	}
+	return cmds
+}
+
+// dnsClientCommand は接続固有のDNSサフィックスとDNS登録を設定するコマンドを返します
+// netsh では設定できないため Set-DnsClient を使用します
+// NIC名とサフィックスは検証済みで引用符を含まないため、単一引用符で囲んで渡します
+func dnsClientCommand(profile *models.Profile) (Command, bool) {
+	if profile.DNSSuffix == "" && profile.RegisterDNS == nil {
+		return Command{}, false
+	}
+
+	script := fmt.Sprintf("Set-DnsClient -InterfaceAlias '%s'", profile.NICName)
+	if profile.DNSSuffix != "" {
+		script += fmt.Sprintf(" -ConnectionSpecificSuffix '%s'", profile.DNSSuffix)
+	}
+	if profile.RegisterDNS != nil {
+		script += fmt.Sprintf(" -RegisterThisConnectionsAddress $%t", *profile.RegisterDNS)
+	}
+	return powershellCommand(script), true
+}
+
+// dhcpAddressCommand はIPアドレスをDHCPに切り替えるコマンドを返します
+func dhcpAddressCommand(nicName string) Command {
+	return netshCommand("interface", "ipv4", "set", "address",
+		"name="+nicName,
+		"source=dhcp")
+}
+
+// dhcpDNSCommand はDNSをDHCPに切り替えるコマンドを返します
+func dhcpDNSCommand(nicName string) Command {
+	return netshCommand("interface", "ipv4", "set", "dns",
+		"name="+nicName,
+		"source=dhcp")
+}
@@ -0,0 +1,234 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+const (
+	// dadTimeout は適用後にアドレスの重複確認（DAD）が終わるのを待つ最大の時間です
+	dadTimeout = 3 * time.Second
+	// dadPollInterval はアドレスの状態を確認する間隔です
+	dadPollInterval = 500 * time.Millisecond
+)
+
+// AddressProber は適用するIPアドレスを他のホストが使用しているかを確認します
+// 既定では arp と ping で確認し、SetAddressProber で差し替えられます（ネットワークに接続しない検証用など）
+type AddressProber interface {
+	// Probe はNICの接続先で address に応答するホストを探し、見つかった場合はそのMACアドレスを返します（見つからない場合は空）
+	Probe(nicName, address string) (mac string, err error)
+}
+
+// AddressConflict は他のホストが使用しているIPアドレスです
+type AddressConflict struct {
+	Address string
+	// MAC は使用しているホストのMACアドレスです（適用後に Windows が重複を検出した場合は空）
+	MAC string
+}
+
+func (e *AddressConflict) Error() string {
+	if e.MAC == "" {
+		return fmt.Sprintf("IPアドレス %s は他のホストと重複しています", e.Address)
+	}
+	return fmt.Sprintf("IPアドレス %s は他のホスト（%s）が使用しています", e.Address, e.MAC)
+}
+
+// SetAddressProber はIPアドレスの使用状況の確認方法を設定します（nil の場合は arp と ping で確認）
+func (c *Client) SetAddressProber(prober AddressProber) {
+	c.prober = prober
+}
+
+// SetAddressConflictPolicy はIPアドレスの重複を検出した場合の動作を設定します（models.AddressConflictAbort など）
+func (c *Client) SetAddressConflictPolicy(policy string) {
+	c.conflictPolicy = policy
+}
+
+// SetWarningHandler は適用を続ける警告（重複の検出など）を受け取る関数を設定します
+func (c *Client) SetWarningHandler(handler func(message string)) {
+	c.onWarning = handler
+}
+
+// warn は警告をログに記録し、SetWarningHandler の関数に渡します
+func (c *Client) warn(message string) {
+	logger.Warn(message)
+	if c.onWarning != nil {
+		c.onWarning(message)
+	}
+}
+
+// checkAddressConflicts は適用前に、プロファイルのアドレスを他のホストが使用していないかを確認します
+// NICに既に設定されているアドレスは確認しません（重複は適用後の checkDuplicateAddresses で検出）
+func (c *Client) checkAddressConflicts(profile *models.Profile, snapshot *Snapshot) error {
+	if c.conflictPolicy == models.AddressConflictIgnore {
+		return nil
+	}
+
+	assigned := make(map[string]bool)
+	if snapshot != nil && snapshot.Config != nil {
+		assigned[snapshot.Config.IPAddress] = true
+		for _, entry := range snapshot.Config.AdditionalAddresses {
+			assigned[entry.IPAddress] = true
+		}
+	}
+
+	prober := c.prober
+	if prober == nil {
+		prober = arpProber{runner: c.runner}
+	}
+
+	addresses := []string{profile.IPAddress}
+	for _, entry := range profile.AdditionalAddresses {
+		addresses = append(addresses, entry.IPAddress)
+	}
+	var unverified []string
+	for _, address := range addresses {
+		if assigned[address] {
+			continue
+		}
+		// 既定の arp による確認は、NICが現在接続しているサブネットのアドレスしか解決できない
+		if c.prober == nil && !snapshot.onLink(address) {
+			unverified = append(unverified, address+"（現在のネットワークと別のサブネット）")
+			continue
+		}
+		mac, err := prober.Probe(profile.NICName, address)
+		if err != nil {
+			logger.Warn("IPアドレスの使用状況を確認できませんでした", "address", address, "error", err)
+			unverified = append(unverified, address)
+			continue
+		}
+		if mac == "" {
+			continue
+		}
+
+		conflict := &AddressConflict{Address: address, MAC: mac}
+		if c.conflictPolicy == models.AddressConflictWarn {
+			c.warn(conflict.Error() + "（適用を続けます）")
+			continue
+		}
+		logger.Error("IPアドレスの重複を検出したため適用を中止", conflict, "profile", profile.Name)
+		return &NetworkError{
+			Code:    "ADDRESS_CONFLICT",
+			Message: fmt.Sprintf("IPアドレス %s は他のホストが使用しているため、適用を中止しました", address),
+			Err:     conflict,
+		}
+	}
+
+	// 確認できなかったアドレスは使用されていないものとして適用を続ける（適用後の checkDuplicateAddresses では確認する）
+	if len(unverified) > 0 {
+		c.warn(fmt.Sprintf("IPアドレス %s を他のホストが使用していないか、適用前に確認できませんでした（適用後に Windows の重複の検出で確認します）", strings.Join(unverified, "、")))
+	}
+	return nil
+}
+
+// onLink は address がNICに設定されているいずれかのアドレスと同じサブネットにあるかどうかを返します
+// snapshot がない場合は判定できないため true を返します
+func (s *Snapshot) onLink(address string) bool {
+	if s == nil {
+		return true
+	}
+	if s.Config == nil {
+		return false
+	}
+	if models.SameSubnet(address, s.Config.IPAddress, s.Config.SubnetMask) {
+		return true
+	}
+	for _, entry := range s.Config.AdditionalAddresses {
+		if models.SameSubnet(address, entry.IPAddress, entry.SubnetMask) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDuplicateAddresses は適用後に、Windows がNICのアドレスの重複を検出していないかを確認します
+func (c *Client) checkDuplicateAddresses(profile *models.Profile) error {
+	if c.conflictPolicy == models.AddressConflictIgnore {
+		return nil
+	}
+
+	duplicates, err := c.duplicateAddresses(profile.NICName)
+	if err != nil {
+		logger.Warn("適用後のIPアドレスの状態を確認できませんでした", "error", err)
+		return nil // 確認失敗は警告のみで続行
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	conflict := &AddressConflict{Address: strings.Join(duplicates, ", ")}
+	if c.conflictPolicy == models.AddressConflictWarn {
+		c.warn(conflict.Error() + "（Windows が重複を検出しました）")
+		return nil
+	}
+	return &NetworkError{
+		Code:    "DUPLICATE_ADDRESS",
+		Message: "Windows がIPアドレスの重複を検出しました",
+		Err:     conflict,
+	}
+}
+
+// addressStateScript はNICのIPv4アドレスと状態（Preferred、Tentative、Duplicate など）を1行ずつ出力するスクリプトです
+const addressStateScript = `Get-NetIPAddress -InterfaceAlias '%s' -AddressFamily IPv4 -ErrorAction Stop | ForEach-Object { "$($_.IPAddress) $($_.AddressState)" }`
+
+// duplicateAddresses はNICで重複（Duplicate）の状態になっているIPv4アドレスを返します
+// 重複の確認中（Tentative）のアドレスがある場合は、確認が終わるまで dadTimeout を上限に待ちます
+func (c *Client) duplicateAddresses(nicName string) ([]string, error) {
+	deadline := time.Now().Add(dadTimeout)
+	for {
+		cmd := powershellCommand(fmt.Sprintf(addressStateScript, nicName))
+		output, err := c.runner.Output(cmd.Name, cmd.Args...)
+		if err != nil {
+			return nil, err
+		}
+		duplicates, tentative := parseAddressStates(string(output))
+		if !tentative || time.Now().After(deadline) {
+			return duplicates, nil
+		}
+		time.Sleep(dadPollInterval)
+	}
+}
+
+// parseAddressStates は addressStateScript の出力から重複しているアドレスと、確認中のアドレスがあるかを返します
+func parseAddressStates(output string) (duplicates []string, tentative bool) {
+	for _, line := range strings.Split(output, "\n") {
+		address, state, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(state) {
+		case "Duplicate":
+			duplicates = append(duplicates, address)
+		case "Tentative":
+			tentative = true
+		}
+	}
+	return duplicates, tentative
+}
+
+// arpProber は arp と ping で確認する AddressProber です
+// ARP はNICの接続先と同じサブネットのアドレスのみ解決するため、別のサブネットのアドレスは確認せずに警告します（checkAddressConflicts）
+type arpProber struct {
+	runner Runner
+}
+
+func (p arpProber) Probe(nicName, address string) (string, error) {
+	if strings.HasPrefix(address, "-") {
+		return "", fmt.Errorf("IPアドレスが不正です: %q", address)
+	}
+
+	// 以前に同じアドレスを使用していたホストのキャッシュで誤検出しないよう、先に削除する（エントリがない場合のエラーは無視）
+	p.runner.CombinedOutput("arp", "-d", address)
+	// ping の送信でARPの解決が行われる（ping に応答しないホストもMACアドレスは解決できる）
+	p.runner.Output("ping", "-n", "1", "-w", "1000", address)
+
+	output, err := p.runner.Output("arp", "-a", address)
+	if err != nil {
+		// エントリがない場合も arp はエラーを返す
+		return "", nil
+	}
+	return parseARPEntry(string(output), address), nil
+}
@@ -0,0 +1,179 @@
+package network
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/network/networktest"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// dhcpSnapshot は DHCP で 192.168.1.50/24 を取得している状態のスナップショットです
+func dhcpSnapshot() *Snapshot {
+	return &Snapshot{
+		NICName:     "Ethernet",
+		DHCPEnabled: true,
+		Config:      &models.Profile{NICName: "Ethernet", IPAddress: "192.168.1.50", SubnetMask: "255.255.255.0"},
+	}
+}
+
+func TestCheckAddressConflicts(t *testing.T) {
+	const otherMAC = "00-11-22-33-44-55"
+	tests := []struct {
+		name     string
+		prober   AddressProber
+		policy   string
+		snapshot *Snapshot
+		// wantCode は期待するエラーコードです（空の場合はエラーなし）
+		wantCode string
+		// wantWarning は警告に含まれる文字列です（空の場合は警告なし）
+		wantWarning string
+	}{
+		{name: "no conflict", prober: stubProber{}},
+		{
+			name:     "conflict abort",
+			prober:   stubProber{macs: map[string]string{"10.0.0.10": otherMAC}},
+			wantCode: "ADDRESS_CONFLICT",
+		},
+		{
+			name:        "conflict warn",
+			prober:      stubProber{macs: map[string]string{"10.0.0.10": otherMAC}},
+			policy:      models.AddressConflictWarn,
+			wantWarning: "他のホスト（" + otherMAC + "）が使用しています（適用を続けます）",
+		},
+		{
+			name:   "conflict ignore",
+			prober: stubProber{macs: map[string]string{"10.0.0.10": otherMAC}},
+			policy: models.AddressConflictIgnore,
+		},
+		{
+			// 確認できなかった場合は中止せず、確認できなかったことを警告する
+			name:        "probe error",
+			prober:      stubProber{err: errors.New("exit status 1")},
+			wantWarning: "IPアドレス 10.0.0.10 を他のホストが使用していないか、適用前に確認できませんでした",
+		},
+		{
+			// NICに設定済みのアドレスは確認しない
+			name:     "already assigned",
+			prober:   stubProber{macs: map[string]string{"10.0.0.10": otherMAC}},
+			snapshot: &Snapshot{Config: &models.Profile{IPAddress: "10.0.0.10", SubnetMask: "255.255.255.0"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := networktest.NewFakeRunner()
+			client := NewClient(runner)
+			client.SetAddressProber(tt.prober)
+			client.SetAddressConflictPolicy(tt.policy)
+			var warnings []string
+			client.SetWarningHandler(func(message string) { warnings = append(warnings, message) })
+
+			err := client.checkAddressConflicts(testProfile(), tt.snapshot)
+
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("checkAddressConflicts() error = %v, want nil", err)
+				}
+			} else {
+				var netErr *NetworkError
+				var conflict *AddressConflict
+				if !errors.As(err, &netErr) || netErr.Code != tt.wantCode || !errors.As(err, &conflict) {
+					t.Fatalf("checkAddressConflicts() error = %v, want %s", err, tt.wantCode)
+				}
+				if conflict.Address != "10.0.0.10" || conflict.MAC != otherMAC {
+					t.Errorf("AddressConflict = %+v", conflict)
+				}
+			}
+
+			if tt.wantWarning == "" {
+				if len(warnings) != 0 {
+					t.Errorf("warnings = %q, want none", warnings)
+				}
+			} else if len(warnings) != 1 || !strings.Contains(warnings[0], tt.wantWarning) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestCheckAddressConflictsARP(t *testing.T) {
+	const (
+		cmdDeleteARP = "arp -d 10.0.0.10"
+		cmdPing      = "ping -n 1 -w 1000 10.0.0.10"
+		cmdShowARP   = "arp -a 10.0.0.10"
+	)
+	onLinkSnapshot := &Snapshot{Config: &models.Profile{IPAddress: "192.168.1.50", SubnetMask: "255.255.255.0",
+		AdditionalAddresses: []models.IPAddressEntry{{IPAddress: "10.0.0.50", SubnetMask: "255.255.255.0"}}}}
+
+	t.Run("on-link conflict", func(t *testing.T) {
+		runner := networktest.NewFakeRunner().
+			On(cmdDeleteARP, "").
+			OnError(cmdPing, "", errors.New("exit status 1")).
+			On(cmdShowARP, "  10.0.0.10             00-11-22-33-44-55     dynamic\r\n")
+		client := NewClient(runner)
+
+		var netErr *NetworkError
+		if err := client.checkAddressConflicts(testProfile(), onLinkSnapshot); !errors.As(err, &netErr) || netErr.Code != "ADDRESS_CONFLICT" {
+			t.Errorf("checkAddressConflicts() error = %v, want ADDRESS_CONFLICT", err)
+		}
+		if got := runner.Commands(); len(got) != 3 || got[0] != cmdDeleteARP {
+			t.Errorf("commands = %q, want arp -d, ping, arp -a", got)
+		}
+	})
+
+	t.Run("on-link free", func(t *testing.T) {
+		runner := networktest.NewFakeRunner().
+			On(cmdDeleteARP, "").
+			On(cmdPing, "").
+			OnError(cmdShowARP, "No ARP Entries Found.", errors.New("exit status 1"))
+		client := NewClient(runner)
+		var warnings []string
+		client.SetWarningHandler(func(message string) { warnings = append(warnings, message) })
+
+		if err := client.checkAddressConflicts(testProfile(), onLinkSnapshot); err != nil || len(warnings) != 0 {
+			t.Errorf("checkAddressConflicts() error = %v, warnings = %q, want neither", err, warnings)
+		}
+	})
+
+	// 別のサブネットのアドレスは ARP で確認できないため、確認せずに警告する
+	for _, tt := range []struct {
+		name     string
+		snapshot *Snapshot
+	}{
+		{"off-link", dhcpSnapshot()},
+		{"no address", &Snapshot{DHCPEnabled: true}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := networktest.NewFakeRunner()
+			client := NewClient(runner)
+			var warnings []string
+			client.SetWarningHandler(func(message string) { warnings = append(warnings, message) })
+
+			if err := client.checkAddressConflicts(testProfile(), tt.snapshot); err != nil {
+				t.Errorf("checkAddressConflicts() error = %v, want nil", err)
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], "10.0.0.10（現在のネットワークと別のサブネット）") {
+				t.Errorf("warnings = %q, want the off-link address", warnings)
+			}
+			if calls := runner.Calls(); len(calls) != 0 {
+				t.Errorf("commands = %q, want none", runner.Commands())
+			}
+		})
+	}
+}
+
+func TestApplyProfileAddressConflict(t *testing.T) {
+	runner := newTestRunner(dhcpConfigOutput, staticConfigOutput)
+	client := newTestClient(runner)
+	client.SetAddressProber(stubProber{macs: map[string]string{"10.0.0.10": "00-11-22-33-44-55"}})
+
+	var netErr *NetworkError
+	if err := client.ApplyProfile(testProfile()); !errors.As(err, &netErr) || netErr.Code != "ADDRESS_CONFLICT" {
+		t.Fatalf("ApplyProfile() error = %v, want ADDRESS_CONFLICT", err)
+	}
+	// 何も変更せずに中止する
+	if got := changes(runner); len(got) != 0 {
+		t.Errorf("commands = %q, want none", got)
+	}
+}
@@ -0,0 +1,141 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// Environment はNICが接続しているネットワークを識別するための情報です（自動切り替えに使用）
+type Environment struct {
+	NICName string `json:"nicName"`
+	// LinkUp はNICがリンクアップしているかどうかです
+	LinkUp bool `json:"linkUp"`
+	// Gateway はIPv4のデフォルトゲートウェイです（DHCPの場合は割り当てられたもの）
+	Gateway string `json:"gateway,omitempty"`
+	// GatewayMAC はデフォルトゲートウェイのMACアドレスです（"00-11-22-AA-BB-CC" の形式。取得できない場合は空）
+	GatewayMAC string `json:"gatewayMac,omitempty"`
+	// DNSSuffix は接続固有のDNSサフィックスです（DHCPの場合は割り当てられたもの）
+	DNSSuffix string `json:"dnsSuffix,omitempty"`
+}
+
+// wirelessPatterns は netsh wlan show interfaces の項目名を日本語/英語両対応で定義します
+var wirelessPatterns = struct {
+	Name []string
+	SSID []string
+}{
+	Name: []string{"名前", "Name"},
+	SSID: []string{"SSID"},
+}
+
+// environmentScript はNICの状態・ゲートウェイとそのMACアドレス・DNSサフィックスを JSON で出力するスクリプトです
+// Win32_NetworkAdapterConfiguration の DNSDomain はDHCPで割り当てられたサフィックスを含みます
+const environmentScript = `$ErrorActionPreference = 'Stop'; ` +
+	`$a = Get-NetAdapter -Name '%s'; ` +
+	`$c = Get-CimInstance Win32_NetworkAdapterConfiguration -Filter "InterfaceIndex=$($a.ifIndex)"; ` +
+	`$gw = @($c.DefaultIPGateway | Where-Object { $_ -notmatch ':' })[0]; ` +
+	`$mac = ''; ` +
+	`if ($gw) { $n = Get-NetNeighbor -IPAddress $gw -ErrorAction SilentlyContinue | Select-Object -First 1; if ($n) { $mac = $n.LinkLayerAddress } }; ` +
+	`[pscustomobject]@{ status = [string]$a.Status; gateway = [string]$gw; gatewayMac = [string]$mac; dnsSuffix = [string]$c.DNSDomain } | ConvertTo-Json -Compress`
+
+// GetEnvironment はNICが接続しているネットワークの情報を取得します
+func (c *Client) GetEnvironment(nicName string) (*Environment, error) {
+	if !models.IsValidNICName(nicName) {
+		return nil, &NetworkError{
+			Code:    "INVALID_NIC_NAME",
+			Message: fmt.Sprintf("NIC名に不正な文字が含まれています: %s", nicName),
+		}
+	}
+
+	cmd := powershellCommand(fmt.Sprintf(environmentScript, nicName))
+	output, err := c.runner.Output(cmd.Name, cmd.Args...)
+	if err != nil {
+		return nil, &NetworkError{
+			Code:    "GET_ENVIRONMENT_FAILED",
+			Message: fmt.Sprintf("NIC '%s' の接続先の情報を取得できませんでした", nicName),
+			Err:     err,
+		}
+	}
+	return parseEnvironment(nicName, output)
+}
+
+// parseEnvironment は environmentScript の出力を解析します
+func parseEnvironment(nicName string, output []byte) (*Environment, error) {
+	var result struct {
+		Status     string `json:"status"`
+		Gateway    string `json:"gateway"`
+		GatewayMAC string `json:"gatewayMac"`
+		DNSSuffix  string `json:"dnsSuffix"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, &NetworkError{
+			Code:    "GET_ENVIRONMENT_FAILED",
+			Message: fmt.Sprintf("NIC '%s' の接続先の情報を解析できませんでした", nicName),
+			Err:     err,
+		}
+	}
+
+	env := &Environment{
+		NICName:   nicName,
+		LinkUp:    strings.EqualFold(result.Status, "Up"),
+		Gateway:   result.Gateway,
+		DNSSuffix: result.DNSSuffix,
+	}
+	// 到達できないゲートウェイは 00-00-00-00-00-00 になるため、取得できなかったものとして扱う
+	if mac, err := models.NormalizeMAC(result.GatewayMAC); err == nil && mac != "00-00-00-00-00-00" {
+		env.GatewayMAC = mac
+	}
+	return env, nil
+}
+
+// GetWirelessSSIDs は接続している無線NICの名前とSSIDの対応を返します
+// 無線NICがない場合（WLAN AutoConfig サービスが停止している場合を含む）は空を返します
+func (c *Client) GetWirelessSSIDs() (map[string]string, error) {
+	output, err := c.runner.Output("netsh", "wlan", "show", "interfaces")
+	if err != nil {
+		return nil, &NetworkError{
+			Code:    "GET_WIRELESS_FAILED",
+			Message: "無線NICの接続先を取得できませんでした",
+			Err:     err,
+		}
+	}
+	return parseWirelessSSIDs(string(output)), nil
+}
+
+// parseWirelessSSIDs は netsh wlan show interfaces の出力からNIC名とSSIDを抽出します
+// 例:
+//
+//	Name                   : Wi-Fi
+//	SSID                   : office
+//	BSSID                  : 00:11:22:33:44:55
+func parseWirelessSSIDs(output string) map[string]string {
+	ssids := make(map[string]string)
+	var nicName string
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch {
+		case equalsAny(key, wirelessPatterns.Name):
+			nicName = value
+		case equalsAny(key, wirelessPatterns.SSID) && nicName != "" && value != "":
+			ssids[nicName] = value
+		}
+	}
+	return ssids
+}
+
+// Probe はホストが ping に応答するかどうかを返します（1回、1秒で打ち切り）
+func (c *Client) Probe(host string) bool {
+	if strings.HasPrefix(host, "-") {
+		return false
+	}
+	output, err := c.runner.Output("ping", "-n", "1", "-w", "1000", host)
+	// 「宛先ホストに到達できません」でも終了コードが 0 になる場合があるため、応答の TTL を確認する
+	return err == nil && strings.Contains(strings.ToUpper(string(output)), "TTL=")
+}
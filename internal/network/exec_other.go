@@ -0,0 +1,11 @@
+//go:build !windows
+
+package network
+
+import "os/exec"
+
+// createHiddenCmd はコマンドを作成します
+// Windows 以外ではコンソールウィンドウの制御が不要なため、そのまま exec.Command を使用します
+func createHiddenCmd(name string, args ...string) *exec.Cmd {
+	return exec.Command(name, args...)
+}
@@ -0,0 +1,21 @@
+package network
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// Windows プロセス作成フラグ
+const (
+	createNoWindow = 0x08000000 // CREATE_NO_WINDOW: コンソールウィンドウを表示しない
+)
+
+// createHiddenCmd はコンソールウィンドウを表示しないコマンドを作成します
+func createHiddenCmd(name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: createNoWindow,
+	}
+	return cmd
+}
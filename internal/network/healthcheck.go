@@ -0,0 +1,194 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// healthCheckRetryInterval は疎通確認に失敗した場合に再試行するまでの間隔です
+const healthCheckRetryInterval = 500 * time.Millisecond
+
+// HealthCheckResult は1つの疎通確認の結果です
+type HealthCheckResult struct {
+	Check models.HealthCheck `json:"check"`
+	// Target は確認した対象です（gateway で対象を省略した場合はプロファイルのゲートウェイ）
+	Target string `json:"target"`
+	OK     bool   `json:"ok"`
+	// Detail は成功した場合の応答の内容、または失敗した理由です
+	Detail string `json:"detail,omitempty"`
+	// ElapsedMS は成功するか打ち切るまでにかかった時間（ミリ秒）です
+	ElapsedMS int64 `json:"elapsedMs"`
+}
+
+// String は結果を表示用の文字列で返します（例: "TCP intranet:443: 接続できません"）
+func (r *HealthCheckResult) String() string {
+	check := r.Check
+	check.Target = r.Target
+	if r.Detail == "" {
+		return check.String()
+	}
+	return fmt.Sprintf("%s: %s", check.String(), r.Detail)
+}
+
+// HealthReport はプロファイルの適用後に行った疎通確認の結果です
+type HealthReport struct {
+	NICName string              `json:"nicName"`
+	Results []HealthCheckResult `json:"results"`
+}
+
+// Passed はすべての疎通確認に成功したかどうかを返します
+func (r *HealthReport) Passed() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed は失敗した疎通確認の結果を返します
+func (r *HealthReport) Failed() []HealthCheckResult {
+	var failed []HealthCheckResult
+	for _, result := range r.Results {
+		if !result.OK {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// String は結果を通知向けの文字列で返します
+// 例: "疎通確認: 3件すべて成功"、"疎通確認: 3件中1件失敗（TCP intranet:443: 接続できません）"
+func (r *HealthReport) String() string {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return fmt.Sprintf("疎通確認: %d件すべて成功", len(r.Results))
+	}
+	details := make([]string, len(failed))
+	for i := range failed {
+		details[i] = failed[i].String()
+	}
+	return fmt.Sprintf("疎通確認: %d件中%d件失敗（%s）", len(r.Results), len(failed), strings.Join(details, "、"))
+}
+
+// HealthCheckError は疎通確認に失敗したため変更前の設定に戻した場合の原因のエラーです
+// NetworkError（Code: HEALTH_CHECK_FAILED）の Err に設定され、errors.As で結果を取得できます
+type HealthCheckError struct {
+	Report *HealthReport
+}
+
+func (e *HealthCheckError) Error() string {
+	return e.Report.String()
+}
+
+// RunHealthChecks はプロファイルの疎通確認を順に実行します（疎通確認がない場合は nil）
+// 各確認は成功するかタイムアウトするまで繰り返します
+func (c *Client) RunHealthChecks(profile *models.Profile) *HealthReport {
+	if len(profile.HealthChecks) == 0 {
+		return nil
+	}
+
+	report := &HealthReport{NICName: profile.NICName}
+	for _, check := range profile.HealthChecks {
+		result := c.runHealthCheck(profile, check)
+		if result.OK {
+			logger.Info("疎通確認に成功", "check", check.Type, "target", result.Target, "detail", result.Detail, "elapsed_ms", result.ElapsedMS)
+		} else {
+			logger.Warn("疎通確認に失敗", "check", check.Type, "target", result.Target, "detail", result.Detail, "elapsed_ms", result.ElapsedMS)
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// runHealthCheck は1つの疎通確認を成功するかタイムアウトするまで繰り返します
+func (c *Client) runHealthCheck(profile *models.Profile, check models.HealthCheck) HealthCheckResult {
+	result := HealthCheckResult{Check: check, Target: check.ResolvedTarget(profile)}
+	start := time.Now()
+	deadline := start.Add(check.Timeout())
+
+	for {
+		detail, err := c.probeHealth(check.Type, result.Target, time.Until(deadline))
+		if err == nil {
+			result.OK, result.Detail = true, detail
+			break
+		}
+		result.Detail = err.Error()
+		if time.Until(deadline) < healthCheckRetryInterval {
+			break
+		}
+		time.Sleep(healthCheckRetryInterval)
+	}
+
+	result.ElapsedMS = time.Since(start).Milliseconds()
+	return result
+}
+
+// probeHealth は疎通確認を1回実行し、成功した場合は応答の内容を返します
+func (c *Client) probeHealth(checkType, target string, timeout time.Duration) (string, error) {
+	if target == "" || strings.HasPrefix(target, "-") {
+		return "", fmt.Errorf("確認する対象が不正です: %q", target)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	switch checkType {
+	case models.HealthCheckGateway:
+		return c.probeGateway(target, timeout)
+	case models.HealthCheckDNS:
+		addrs, err := net.DefaultResolver.LookupHost(ctx, target)
+		if err != nil {
+			return "", fmt.Errorf("名前解決できません: %w", err)
+		}
+		return strings.Join(addrs, ", "), nil
+	case models.HealthCheckTCP:
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", target)
+		if err != nil {
+			return "", fmt.Errorf("接続できません: %w", err)
+		}
+		defer conn.Close()
+		return "接続先 " + conn.RemoteAddr().String(), nil
+	default:
+		return "", fmt.Errorf("疎通確認の種類が不正です: %q", checkType)
+	}
+}
+
+// probeGateway はゲートウェイが ping に応答するかを確認します
+// ping に応答しない機器もあるため、ARPテーブルに解決済みのMACアドレスがある場合も成功とします
+func (c *Client) probeGateway(gateway string, timeout time.Duration) (string, error) {
+	wait := min(timeout, time.Second)
+	output, err := c.runner.Output("ping", "-n", "1", "-w", fmt.Sprint(wait.Milliseconds()), gateway)
+	if err == nil && strings.Contains(strings.ToUpper(string(output)), "TTL=") {
+		return "ping に応答", nil
+	}
+
+	// ping の送信でARPの解決が行われるため、応答がなくてもMACアドレスを確認できる
+	output, err = c.runner.Output("arp", "-a", gateway)
+	if err == nil {
+		if mac := parseARPEntry(string(output), gateway); mac != "" {
+			return "ARP に応答（" + mac + "）", nil
+		}
+	}
+	return "", fmt.Errorf("ping と ARP に応答がありません")
+}
+
+// parseARPEntry は arp -a の出力からIPアドレスのMACアドレスを抽出します（解決できていない場合は空）
+// 例:
+//
+//	インターネット アドレス 物理アドレス           種類
+//	192.168.1.1           00-11-22-33-44-55     動的
+func parseARPEntry(output, ip string) string {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != ip {
+			continue
+		}
+		mac, err := models.NormalizeMAC(fields[1])
+		if err == nil && mac != "00-00-00-00-00-00" && mac != "FF-FF-FF-FF-FF-FF" {
+			return mac
+		}
+	}
+	return ""
+}
@@ -0,0 +1,261 @@
+package network
+
+import (
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/network/networktest"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+const (
+	cmdPingGateway = "ping -n 1 -w"
+	cmdARPGateway  = "arp -a 10.0.0.1"
+
+	pingReplyOutput = `
+Pinging 10.0.0.1 with 32 bytes of data:
+Reply from 10.0.0.1: bytes=32 time<1ms TTL=64
+`
+	// pingUnreachableOutput は終了コードが 0 でも応答がない場合の出力です
+	pingUnreachableOutput = `
+10.0.0.1 に ping を送信しています 32 バイトのデータ:
+10.0.0.10 からの応答: 宛先ホストに到達できません。
+`
+	arpResolvedOutput = `
+Interface: 10.0.0.10 --- 0xc
+  Internet Address      Physical Address      Type
+  10.0.0.1              00-11-22-33-44-55     dynamic
+`
+)
+
+// closedPort は接続を受け付けないローカルのアドレスを返します
+func closedPort(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	return addr
+}
+
+func TestRunHealthChecks(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	tests := []struct {
+		name       string
+		runner     *networktest.FakeRunner
+		check      models.HealthCheck
+		wantOK     bool
+		wantTarget string
+		wantDetail string
+	}{
+		{
+			name:       "gateway ping",
+			runner:     networktest.NewFakeRunner().On(cmdPingGateway, pingReplyOutput),
+			check:      models.HealthCheck{Type: models.HealthCheckGateway},
+			wantOK:     true,
+			wantTarget: "10.0.0.1",
+			wantDetail: "ping に応答",
+		},
+		{
+			// ping に応答しなくても ARP で解決できれば成功
+			name: "gateway ARP",
+			runner: networktest.NewFakeRunner().
+				On(cmdPingGateway, pingUnreachableOutput).
+				On(cmdARPGateway, arpResolvedOutput),
+			check:      models.HealthCheck{Type: models.HealthCheckGateway},
+			wantOK:     true,
+			wantTarget: "10.0.0.1",
+			wantDetail: "ARP に応答（00-11-22-33-44-55）",
+		},
+		{
+			name: "gateway failure",
+			runner: networktest.NewFakeRunner().
+				OnError(cmdPingGateway, "", errors.New("exit status 1")).
+				On(cmdARPGateway, "No ARP Entries Found."),
+			check:      models.HealthCheck{Type: models.HealthCheckGateway, TimeoutSeconds: 1},
+			wantTarget: "10.0.0.1",
+			wantDetail: "ping と ARP に応答がありません",
+		},
+		{
+			name:       "gateway target",
+			runner:     networktest.NewFakeRunner().On("ping -n 1 -w 1000 10.0.0.254", pingReplyOutput),
+			check:      models.HealthCheck{Type: models.HealthCheckGateway, Target: "10.0.0.254"},
+			wantOK:     true,
+			wantTarget: "10.0.0.254",
+			wantDetail: "ping に応答",
+		},
+		{
+			name:       "tcp",
+			runner:     networktest.NewFakeRunner(),
+			check:      models.HealthCheck{Type: models.HealthCheckTCP, Target: listener.Addr().String()},
+			wantOK:     true,
+			wantTarget: listener.Addr().String(),
+			wantDetail: "接続先 " + listener.Addr().String(),
+		},
+		{
+			name:       "tcp closed",
+			runner:     networktest.NewFakeRunner(),
+			check:      models.HealthCheck{Type: models.HealthCheckTCP, Target: closedPort(t), TimeoutSeconds: 1},
+			wantDetail: "接続できません",
+		},
+		{
+			name:       "dns",
+			runner:     networktest.NewFakeRunner(),
+			check:      models.HealthCheck{Type: models.HealthCheckDNS, Target: "localhost"},
+			wantOK:     true,
+			wantTarget: "localhost",
+		},
+		{
+			// オプションとして解釈される対象は実行しない
+			name:       "option target",
+			runner:     networktest.NewFakeRunner(),
+			check:      models.HealthCheck{Type: models.HealthCheckGateway, Target: "-t", TimeoutSeconds: 1},
+			wantTarget: "-t",
+			wantDetail: "確認する対象が不正です",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := testProfile()
+			profile.HealthChecks = []models.HealthCheck{tt.check}
+			report := NewClient(tt.runner).RunHealthChecks(profile)
+			if report == nil || len(report.Results) != 1 {
+				t.Fatalf("RunHealthChecks() = %+v, want 1 result", report)
+			}
+
+			result := report.Results[0]
+			if result.OK != tt.wantOK || report.Passed() != tt.wantOK {
+				t.Errorf("OK = %t, Passed() = %t, want %t (%s)", result.OK, report.Passed(), tt.wantOK, result.Detail)
+			}
+			if tt.wantTarget != "" && result.Target != tt.wantTarget {
+				t.Errorf("Target = %q, want %q", result.Target, tt.wantTarget)
+			}
+			if !strings.Contains(result.Detail, tt.wantDetail) {
+				t.Errorf("Detail = %q, want %q", result.Detail, tt.wantDetail)
+			}
+			if !tt.wantOK && result.ElapsedMS < 500 {
+				t.Errorf("ElapsedMS = %d, want retried until the timeout", result.ElapsedMS)
+			}
+			if strings.HasPrefix(tt.check.Target, "-") && len(tt.runner.Calls()) != 0 {
+				t.Errorf("commands = %q, want none", tt.runner.Commands())
+			}
+		})
+	}
+}
+
+func TestRunHealthChecksNone(t *testing.T) {
+	if report := NewClient(networktest.NewFakeRunner()).RunHealthChecks(testProfile()); report != nil {
+		t.Errorf("RunHealthChecks() = %+v, want nil", report)
+	}
+}
+
+func TestHealthReportString(t *testing.T) {
+	passed := &HealthReport{Results: []HealthCheckResult{
+		{Check: models.HealthCheck{Type: models.HealthCheckGateway}, Target: "10.0.0.1", OK: true},
+		{Check: models.HealthCheck{Type: models.HealthCheckDNS, Target: "intranet"}, Target: "intranet", OK: true},
+	}}
+	if !passed.Passed() || len(passed.Failed()) != 0 {
+		t.Errorf("Passed() = %t, Failed() = %v", passed.Passed(), passed.Failed())
+	}
+	if got, want := passed.String(), "疎通確認: 2件すべて成功"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	failed := &HealthReport{Results: append(passed.Results,
+		HealthCheckResult{Check: models.HealthCheck{Type: models.HealthCheckTCP, Target: "intranet:443"}, Target: "intranet:443", Detail: "接続できません"})}
+	if failed.Passed() || len(failed.Failed()) != 1 {
+		t.Errorf("Passed() = %t, Failed() = %v", failed.Passed(), failed.Failed())
+	}
+	if got, want := failed.String(), "疎通確認: 3件中1件失敗（TCP intranet:443: 接続できません）"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestParseARPEntry(t *testing.T) {
+	tests := []struct {
+		output string
+		ip     string
+		want   string
+	}{
+		{arpResolvedOutput, "10.0.0.1", "00-11-22-33-44-55"},
+		{"  192.168.1.1           00-aa-bb-cc-dd-ee     動的\r\n", "192.168.1.1", "00-AA-BB-CC-DD-EE"},
+		// 前方一致では一致しない
+		{arpResolvedOutput, "10.0.0.10", ""},
+		// 未解決・ブロードキャスト
+		{"  10.0.0.1              00-00-00-00-00-00     invalid\r\n", "10.0.0.1", ""},
+		{"  10.0.0.255            ff-ff-ff-ff-ff-ff     static\r\n", "10.0.0.255", ""},
+		{"No ARP Entries Found.", "10.0.0.1", ""},
+	}
+	for _, tt := range tests {
+		if got := parseARPEntry(tt.output, tt.ip); got != tt.want {
+			t.Errorf("parseARPEntry(%q) = %q, want %q", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestApplyProfileHealthCheckFailure(t *testing.T) {
+	for _, rollback := range []bool{true, false} {
+		name := "keep"
+		if rollback {
+			name = "rollback"
+		}
+		t.Run(name, func(t *testing.T) {
+			runner := newTestRunner(dhcpConfigOutput, staticConfigOutput).
+				OnError(cmdPingGateway, "", errors.New("exit status 1")).
+				OnError(cmdARPGateway, "", errors.New("exit status 1"))
+			client := newTestClient(runner)
+			snapshot, err := client.TakeSnapshot("Ethernet")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			profile := testProfile()
+			profile.HealthChecks = []models.HealthCheck{{Type: models.HealthCheckGateway, TimeoutSeconds: 1}}
+			profile.RollbackOnHealthCheckFailure = rollback
+			report, err := client.ApplyProfileWithSnapshot(profile, snapshot)
+
+			if report == nil || report.Passed() {
+				t.Fatalf("report = %+v, want failed", report)
+			}
+			want := []string{cmdSetAddress, cmdSetDNS, cmdAddDNS}
+			if !rollback {
+				// 変更前の設定に戻さず、結果だけを返す
+				if err != nil {
+					t.Errorf("ApplyProfileWithSnapshot() error = %v, want nil", err)
+				}
+			} else {
+				var netErr *NetworkError
+				var healthErr *HealthCheckError
+				if !errors.As(err, &netErr) || netErr.Code != "HEALTH_CHECK_FAILED" || !errors.As(err, &healthErr) {
+					t.Fatalf("ApplyProfileWithSnapshot() error = %v, want HEALTH_CHECK_FAILED", err)
+				}
+				if healthErr.Report != report {
+					t.Errorf("HealthCheckError.Report = %+v, want the returned report", healthErr.Report)
+				}
+				if netErr.Rollback == nil || !netErr.Rollback.Succeeded() {
+					t.Errorf("Rollback = %+v, want succeeded", netErr.Rollback)
+				}
+				want = append(want, cmdDHCPAddress, cmdDHCPDNS)
+			}
+			var got []string
+			for _, cmd := range changes(runner) {
+				if !strings.HasPrefix(cmd, "ping ") && !strings.HasPrefix(cmd, "arp ") {
+					got = append(got, cmd)
+				}
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("commands =\n%q\nwant\n%q", got, want)
+			}
+		})
+	}
+}
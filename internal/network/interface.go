@@ -0,0 +1,143 @@
+package network
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// interfacePatterns は netsh interface ipv4 show interface の項目名を日本語/英語両対応で定義します
+var interfacePatterns = struct {
+	Metric          []string
+	MTU             []string
+	AutomaticMetric []string
+	Enabled         []string
+}{
+	Metric:          []string{"メトリック", "Metric"},
+	MTU:             []string{"リンク MTU", "Link MTU"},
+	AutomaticMetric: []string{"自動メトリック", "Automatic Metric"},
+	Enabled:         []string{"有効", "enabled"},
+}
+
+// showInterface は netsh interface ipv4 show interface の出力を取得します
+func (c *Client) showInterface(nicName string) (string, error) {
+	output, err := c.runner.Output("netsh", "interface", "ipv4", "show", "interface", "interface="+nicName)
+	if err != nil {
+		return "", &NetworkError{
+			Code:    "GET_INTERFACE_FAILED",
+			Message: fmt.Sprintf("NIC '%s' のインターフェイス設定取得に失敗しました", nicName),
+			Err:     err,
+		}
+	}
+	return string(output), nil
+}
+
+// parseInterfaceSettings はインターフェイスメトリックとMTUを解析してプロファイルに設定します
+// 例:
+//
+//	Metric                             : 25
+//	Link MTU                           : 1500 bytes
+//	Use Automatic Metric               : enabled
+func parseInterfaceSettings(output string, profile *models.Profile) {
+	automatic := false
+	metric := ""
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case containsAny(key, interfacePatterns.AutomaticMetric):
+			automatic = containsAny(fields[0], interfacePatterns.Enabled)
+		case containsAny(key, interfacePatterns.MTU):
+			if mtu, err := strconv.Atoi(fields[0]); err == nil {
+				profile.MTU = mtu
+			}
+		case equalsAny(key, interfacePatterns.Metric):
+			if n, err := strconv.Atoi(fields[0]); err == nil && n >= 0 {
+				metric = strconv.Itoa(n)
+			}
+		}
+	}
+
+	profile.InterfaceMetric = metric
+	if automatic {
+		profile.InterfaceMetric = models.MetricAutomatic
+	}
+}
+
+// equalsAny は文字列がいずれかのパターンと一致するかどうかを判定します
+func equalsAny(s string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if s == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// interfaceCommands はインターフェイスメトリックとMTUを設定するコマンドを返します
+// netsh では自動メトリックに戻せないため、自動の場合は Set-NetIPInterface を使用します
+func interfaceCommands(profile *models.Profile) []Command {
+	var cmds []Command
+	args := []string{"interface", "ipv4", "set", "interface", "interface=" + profile.NICName}
+	if metric, ok := profile.FixedInterfaceMetric(); ok {
+		args = append(args, "metric="+strconv.Itoa(metric))
+	}
+	if profile.MTU > 0 {
+		args = append(args, "mtu="+strconv.Itoa(profile.MTU))
+	}
+	if len(args) > 5 {
+		cmds = append(cmds, netshCommand(append(args, "store=persistent")...))
+	}
+
+	if profile.InterfaceMetric == models.MetricAutomatic {
+		cmds = append(cmds, powershellCommand(fmt.Sprintf(
+			"Set-NetIPInterface -InterfaceAlias '%s' -AddressFamily IPv4 -AutomaticMetric Enabled", profile.NICName)))
+	}
+	return cmds
+}
+
+// applyInterfaceSettings はインターフェイスメトリックとMTUを設定します
+func (c *Client) applyInterfaceSettings(profile *models.Profile) error {
+	for _, cmd := range interfaceCommands(profile) {
+		if output, err := c.run(cmd); err != nil {
+			logger.Error("インターフェイス設定の適用に失敗", err, "output", string(output))
+			return &NetworkError{
+				Code:    "APPLY_INTERFACE_FAILED",
+				Message: fmt.Sprintf("インターフェイスメトリック/MTUの設定に失敗しました: %s", string(output)),
+				Err:     err,
+			}
+		}
+	}
+	return nil
+}
+
+// verifyInterfaceSettings は適用後のインターフェイスメトリックとMTUがプロファイルと一致するかを確認します
+// 取得できなかった項目（空または 0）は確認しません
+func verifyInterfaceSettings(applied, profile *models.Profile) error {
+	if profile.InterfaceMetric != "" && applied.InterfaceMetric != "" && applied.InterfaceMetric != profile.InterfaceMetric {
+		return &NetworkError{
+			Code:    "VERIFY_FAILED",
+			Message: "インターフェイスメトリックの適用が確認できませんでした",
+			Err:     fmt.Errorf("期待: %s, 実際: %s", profile.InterfaceMetric, applied.InterfaceMetric),
+		}
+	}
+	if profile.MTU != 0 && applied.MTU != 0 && applied.MTU != profile.MTU {
+		return &NetworkError{
+			Code:    "VERIFY_FAILED",
+			Message: "MTUの適用が確認できませんでした",
+			Err:     fmt.Errorf("期待: %d, 実際: %d", profile.MTU, applied.MTU),
+		}
+	}
+	return nil
+}
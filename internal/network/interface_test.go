@@ -0,0 +1,70 @@
+package network
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+func TestParseInterfaceSettings(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		output     string
+		wantMetric string
+		wantMTU    int
+	}{
+		{name: "automatic en", file: "ipv4_show_interface_auto_en.txt", wantMetric: models.MetricAutomatic, wantMTU: 1500},
+		{name: "automatic ja", file: "ipv4_show_interface_auto_ja.txt", wantMetric: models.MetricAutomatic, wantMTU: 1500},
+		{name: "fixed en", file: "ipv4_show_interface_fixed_en.txt", wantMetric: "35", wantMTU: 1400},
+		{name: "fixed ja", file: "ipv4_show_interface_fixed_ja.txt", wantMetric: "10", wantMTU: 9000},
+		// NIC が見つからない場合のエラーメッセージなど、項目がない出力
+		{name: "not found", output: "\r\nElement not found.\r\n\r\n"},
+		{name: "empty", output: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := tt.output
+			if tt.file != "" {
+				output = readTestdata(t, tt.file)
+			}
+			var profile models.Profile
+			parseInterfaceSettings(output, &profile)
+			if profile.InterfaceMetric != tt.wantMetric || profile.MTU != tt.wantMTU {
+				t.Errorf("parseInterfaceSettings() = metric %q, MTU %d, want %q, %d", profile.InterfaceMetric, profile.MTU, tt.wantMetric, tt.wantMTU)
+			}
+		})
+	}
+}
+
+func TestInterfaceCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile models.Profile
+		want    []string
+	}{
+		{"unchanged", models.Profile{NICName: "Ethernet"}, nil},
+		{
+			"fixed metric and MTU",
+			models.Profile{NICName: "Ethernet", InterfaceMetric: "10", MTU: 9000},
+			[]string{"netsh interface ipv4 set interface interface=Ethernet metric=10 mtu=9000 store=persistent"},
+		},
+		{
+			"automatic metric",
+			models.Profile{NICName: "Ethernet", InterfaceMetric: models.MetricAutomatic},
+			[]string{"powershell -NoProfile -NonInteractive -Command Set-NetIPInterface -InterfaceAlias 'Ethernet' -AddressFamily IPv4 -AutomaticMetric Enabled"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, cmd := range interfaceCommands(&tt.profile) {
+				got = append(got, cmd.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("interfaceCommands() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
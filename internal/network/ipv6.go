@@ -0,0 +1,353 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// ipv6Patterns は日本語/英語両対応のIPv6関連の出力パターンを定義します
+var ipv6Patterns = struct {
+	Interface []string
+	Manual    []string
+}{
+	Interface: []string{"インターフェイス", "Interface"},
+	Manual:    []string{"手動", "Manual"},
+}
+
+// defaultRoutePrefix はIPv6のデフォルトルートです
+const defaultRoutePrefix = "::/0"
+
+// ipv6Config はNICのIPv6設定を表します
+// netsh interface ipv6 には show config がないため、
+// show addresses / show route / show dnsservers の出力を組み合わせて取得します
+type ipv6Config struct {
+	// Index はインターフェイス番号です（IPv6が無効なNICでは 0）
+	Index int
+	// Manual は手動で設定されたアドレスです
+	Manual       []string
+	PrefixLength int
+	Gateway      string
+	DNSServers   []string
+}
+
+// address は代表のアドレス（最初の手動アドレス）を返します
+func (cfg *ipv6Config) address() string {
+	if len(cfg.Manual) == 0 {
+		return ""
+	}
+	return cfg.Manual[0]
+}
+
+// applyTo はIPv6設定をプロファイルに反映します
+func (cfg *ipv6Config) applyTo(profile *models.Profile) {
+	profile.IPv6Address = cfg.address()
+	if profile.IPv6Address != "" {
+		profile.IPv6PrefixLength = cfg.PrefixLength
+	}
+	profile.IPv6Gateway = cfg.Gateway
+	profile.IPv6DNSServers = cfg.DNSServers
+}
+
+// ipv6ConfigFromProfile はプロファイルに記録されたIPv6設定を ipv6Config に変換します
+func ipv6ConfigFromProfile(profile *models.Profile) *ipv6Config {
+	cfg := &ipv6Config{
+		PrefixLength: profile.IPv6PrefixLength,
+		Gateway:      profile.IPv6Gateway,
+		DNSServers:   profile.IPv6DNSServers,
+	}
+	if profile.IPv6Address != "" {
+		cfg.Manual = []string{profile.IPv6Address}
+	}
+	return cfg
+}
+
+// getIPv6Config は指定されたNICの現在のIPv6設定を取得します
+// ルートとDNSサーバーの取得に失敗した場合は警告を記録して続行します
+func (c *Client) getIPv6Config(nicName string) (*ipv6Config, error) {
+	output, err := c.runner.Output("netsh", "interface", "ipv6", "show", "addresses")
+	if err != nil {
+		return nil, &NetworkError{
+			Code:    "GET_IPV6_CONFIG_FAILED",
+			Message: fmt.Sprintf("NIC '%s' のIPv6設定取得に失敗しました", nicName),
+			Err:     err,
+		}
+	}
+
+	cfg := parseIPv6Addresses(nicName, string(output))
+	if cfg.Index == 0 {
+		return cfg, nil
+	}
+
+	if output, err := c.runner.Output("netsh", "interface", "ipv6", "show", "route"); err != nil {
+		logger.Warn("IPv6ルートの取得に失敗", "nic", nicName, "error", err)
+	} else {
+		cfg.PrefixLength, cfg.Gateway = parseIPv6Routes(cfg.Index, cfg.address(), string(output))
+	}
+
+	if output, err := c.runner.Output("netsh", "interface", "ipv6", "show", "dnsservers", "name="+nicName); err != nil {
+		logger.Warn("IPv6 DNSサーバーの取得に失敗", "nic", nicName, "error", err)
+	} else {
+		cfg.DNSServers = parseDNSServerList(string(output))
+	}
+	return cfg, nil
+}
+
+// parseIPv6Addresses は netsh interface ipv6 show addresses の出力から指定されたNICの手動アドレスを解析します
+// 例:
+//
+//	Interface 12: Ethernet
+//
+//	Addr Type  DAD State   Valid Life Pref. Life Address
+//	---------  ----------- ---------- ---------- ------------------------
+//	Manual     Preferred     infinite   infinite 2001:db8::10
+func parseIPv6Addresses(nicName, output string) *ipv6Config {
+	cfg := &ipv6Config{}
+	inTarget := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		if index, name, ok := parseInterfaceHeader(line); ok {
+			inTarget = name == nicName
+			if inTarget {
+				cfg.Index = index
+			}
+			continue
+		}
+		if !inTarget {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !containsAny(fields[0], ipv6Patterns.Manual) {
+			continue
+		}
+		if addr := stripZone(fields[len(fields)-1]); isIPv6(addr) {
+			cfg.Manual = append(cfg.Manual, addr)
+		}
+	}
+	return cfg
+}
+
+// parseInterfaceHeader は "Interface 12: Ethernet" 形式の見出し行を解析します
+func parseInterfaceHeader(line string) (int, string, bool) {
+	for _, prefix := range ipv6Patterns.Interface {
+		rest, found := strings.CutPrefix(line, prefix)
+		if !found {
+			continue
+		}
+		number, name, found := strings.Cut(rest, ":")
+		if !found {
+			return 0, "", false
+		}
+		index, err := strconv.Atoi(strings.TrimSpace(number))
+		if err != nil {
+			return 0, "", false
+		}
+		return index, strings.TrimSpace(name), true
+	}
+	return 0, "", false
+}
+
+// parseIPv6Routes は netsh interface ipv6 show route の出力から
+// 指定されたインターフェイスのアドレスのプレフィックス長とデフォルトゲートウェイを解析します
+// 例:
+//
+//	Publish  Type      Met  Prefix                    Idx  Gateway/Interface Name
+//	-------  --------  ---  ------------------------  ---  ------------------------
+//	No       Manual    256  ::/0                       12  fe80::1
+//	No       System    256  2001:db8::/64              12  Ethernet
+func parseIPv6Routes(index int, address, output string) (prefixLength int, gateway string) {
+	ip := net.ParseIP(address)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		}
+		if idx, err := strconv.Atoi(fields[4]); err != nil || idx != index {
+			continue
+		}
+		prefix := fields[3]
+		target := strings.Join(fields[5:], " ")
+
+		if prefix == defaultRoutePrefix {
+			if isIPv6(target) && gateway == "" {
+				gateway = target
+			}
+			continue
+		}
+
+		// アドレスを含む最も長いプレフィックス（/128 のホストルートを除く）を採用
+		_, network, err := net.ParseCIDR(prefix)
+		if err != nil || ip == nil || !network.Contains(ip) {
+			continue
+		}
+		if ones, _ := network.Mask.Size(); ones < 128 && ones > prefixLength {
+			prefixLength = ones
+		}
+	}
+	return prefixLength, gateway
+}
+
+// parseDNSServerList は show dns / show dnsservers の出力からDNSサーバーの一覧を解析します
+// 2つ目以降のサーバーは値のみの継続行として出力されます
+func parseDNSServerList(output string) []string {
+	var servers []string
+	inDNS := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		if containsAny(line, ipConfigPatterns.DNS) {
+			inDNS = true
+			if dns := extractDNSValue(line); dns != "" {
+				servers = append(servers, dns)
+			}
+			continue
+		}
+
+		// 継続行は値のみ（空白を含まない）
+		if inDNS && !strings.ContainsAny(line, " \t") && net.ParseIP(line) != nil {
+			servers = append(servers, line)
+			continue
+		}
+		inDNS = false
+	}
+	return servers
+}
+
+// stripZone はアドレスからゾーンインデックス（%12 など）を取り除きます
+func stripZone(addr string) string {
+	if i := strings.Index(addr, "%"); i >= 0 {
+		return addr[:i]
+	}
+	return addr
+}
+
+// isIPv6 は文字列がIPv6アドレスかどうかを判定します
+func isIPv6(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() == nil
+}
+
+// ipv6Commands は現在のIPv6設定をプロファイルの設定に合わせるコマンドを返します
+// exact が false の場合、プロファイルで指定されていない項目は変更しません
+// exact が true の場合（ロールバック時）は、指定されていない項目を削除または自動取得に戻します
+func ipv6Commands(current *ipv6Config, target *models.Profile, exact bool) []Command {
+	nic := target.NICName
+	var cmds []Command
+
+	// アドレス: 目的のアドレス以外の手動アドレスを削除してから追加
+	if target.IPv6Address != "" || exact {
+		present := false
+		for _, addr := range current.Manual {
+			if addr == target.IPv6Address && current.PrefixLength == target.IPv6PrefixLength {
+				present = true
+				continue
+			}
+			cmds = append(cmds, netshCommand("interface", "ipv6", "delete", "address",
+				"interface="+nic,
+				"address="+addr))
+		}
+		if target.IPv6Address != "" && !present {
+			cmds = append(cmds, netshCommand("interface", "ipv6", "add", "address",
+				"interface="+nic,
+				"address="+target.IPv6CIDR()))
+		}
+	}
+
+	// ゲートウェイ: デフォルトルートを置き換え
+	if (target.IPv6Gateway != "" || exact) && current.Gateway != target.IPv6Gateway {
+		if current.Gateway != "" {
+			cmds = append(cmds, netshCommand("interface", "ipv6", "delete", "route",
+				"prefix="+defaultRoutePrefix,
+				"interface="+nic,
+				"nexthop="+current.Gateway))
+		}
+		if target.IPv6Gateway != "" {
+			cmds = append(cmds, netshCommand("interface", "ipv6", "add", "route",
+				"prefix="+defaultRoutePrefix,
+				"interface="+nic,
+				"nexthop="+target.IPv6Gateway))
+		}
+	}
+
+	// DNSサーバー
+	if (len(target.IPv6DNSServers) > 0 || exact) && !equalStrings(current.DNSServers, target.IPv6DNSServers) {
+		if len(target.IPv6DNSServers) == 0 {
+			cmds = append(cmds, netshCommand("interface", "ipv6", "set", "dnsservers",
+				"name="+nic,
+				"source=dhcp"))
+		}
+		for i, dns := range target.IPv6DNSServers {
+			if i == 0 {
+				cmds = append(cmds, netshCommand("interface", "ipv6", "set", "dnsservers",
+					"name="+nic,
+					"source=static",
+					"address="+dns,
+					"register=primary"))
+				continue
+			}
+			cmds = append(cmds, netshCommand("interface", "ipv6", "add", "dnsservers",
+				"name="+nic,
+				"address="+dns,
+				"index="+strconv.Itoa(i+1)))
+		}
+	}
+	return cmds
+}
+
+// equalStrings は2つの文字列スライスが順序も含めて等しいかどうかを判定します
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// applyIPv6Settings は現在のIPv6設定を取得し、プロファイルの設定に合わせます
+func (c *Client) applyIPv6Settings(profile *models.Profile, exact bool) error {
+	current, err := c.getIPv6Config(profile.NICName)
+	if err != nil {
+		return err
+	}
+
+	for _, cmd := range ipv6Commands(current, profile, exact) {
+		if output, err := c.run(cmd); err != nil {
+			logger.Error("IPv6設定の適用に失敗", err, "output", string(output))
+			return &NetworkError{
+				Code:    "APPLY_IPV6_FAILED",
+				Message: fmt.Sprintf("IPv6設定の適用に失敗しました: %s", string(output)),
+				Err:     err,
+			}
+		}
+	}
+	return nil
+}
+
+// verifyIPv6 は適用後のIPv6設定がプロファイルと一致するかを確認します
+func verifyIPv6(applied, profile *models.Profile) error {
+	if profile.IPv6Address != "" && applied.IPv6Address != profile.IPv6Address {
+		return &NetworkError{
+			Code:    "VERIFY_FAILED",
+			Message: "IPv6アドレスの適用が確認できませんでした",
+			Err:     fmt.Errorf("期待: %s, 実際: %s", profile.IPv6CIDR(), applied.IPv6CIDR()),
+		}
+	}
+	if profile.IPv6Gateway != "" && applied.IPv6Gateway != profile.IPv6Gateway {
+		return &NetworkError{
+			Code:    "VERIFY_FAILED",
+			Message: "IPv6ゲートウェイの適用が確認できませんでした",
+			Err:     fmt.Errorf("期待: %s, 実際: %s", profile.IPv6Gateway, applied.IPv6Gateway),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// readTestdata は testdata のコマンドの出力を読み込みます
+// Windows のコマンドと同じく、改行は CRLF に変換します
+func readTestdata(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n", "\r\n")
+}
+
+func TestParseIPv6Addresses(t *testing.T) {
+	tests := []struct {
+		file      string
+		nic       string
+		wantIndex int
+		wantAddrs []string
+	}{
+		{"ipv6_show_addresses_en.txt", "Ethernet", 12, []string{"2001:db8::10"}},
+		{"ipv6_show_addresses_ja.txt", "イーサネット", 12, []string{"2001:db8::10", "2001:db8:1::20"}},
+		// 手動アドレスがない（リンクローカルのみ）
+		{"ipv6_show_addresses_en.txt", "Wi-Fi", 15, nil},
+		{"ipv6_show_addresses_ja.txt", "Wi-Fi", 18, nil},
+		// アドレスの一覧が空
+		{"ipv6_show_addresses_en.txt", "Ethernet 2", 23, nil},
+		// 見出しの名前の前方一致では一致しない
+		{"ipv6_show_addresses_en.txt", "Ether", 0, nil},
+		// IPv6 が無効（見出しがない）
+		{"ipv6_show_addresses_ja.txt", "Ethernet", 0, nil},
+		{"", "Ethernet", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file+"/"+tt.nic, func(t *testing.T) {
+			output := ""
+			if tt.file != "" {
+				output = readTestdata(t, tt.file)
+			}
+			cfg := parseIPv6Addresses(tt.nic, output)
+			if cfg.Index != tt.wantIndex {
+				t.Errorf("Index = %d, want %d", cfg.Index, tt.wantIndex)
+			}
+			if !slices.Equal(cfg.Manual, tt.wantAddrs) {
+				t.Errorf("Manual = %q, want %q", cfg.Manual, tt.wantAddrs)
+			}
+		})
+	}
+}
+
+func TestParseIPv6Routes(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		index       int
+		address     string
+		wantPrefix  int
+		wantGateway string
+	}{
+		// /48 と /64 のうち長いプレフィックス、/128 のホストルートは除く
+		{"en", "ipv6_show_route_en.txt", 12, "2001:db8::10", 64, "fe80::1"},
+		{"ja", "ipv6_show_route_ja.txt", 12, "2001:db8::10", 64, "fe80::1"},
+		// 他のインターフェイスのデフォルトルートは使用しない
+		{"other interface", "ipv6_show_route_en.txt", 15, "", 0, "fe80::9"},
+		{"no address", "ipv6_show_route_en.txt", 12, "", 0, "fe80::1"},
+		{"address outside routes", "ipv6_show_route_en.txt", 12, "2001:db9::1", 0, "fe80::1"},
+		{"no routes", "", 12, "2001:db8::10", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := ""
+			if tt.file != "" {
+				output = readTestdata(t, tt.file)
+			}
+			prefix, gateway := parseIPv6Routes(tt.index, tt.address, output)
+			if prefix != tt.wantPrefix || gateway != tt.wantGateway {
+				t.Errorf("parseIPv6Routes() = %d, %q, want %d, %q", prefix, gateway, tt.wantPrefix, tt.wantGateway)
+			}
+		})
+	}
+}
+
+func TestParseInterfaceHeader(t *testing.T) {
+	tests := []struct {
+		line      string
+		wantIndex int
+		wantName  string
+		wantOK    bool
+	}{
+		{"Interface 12: Ethernet", 12, "Ethernet", true},
+		{"インターフェイス 23: イーサネット 2", 23, "イーサネット 2", true},
+		{"Interface Metric: 25", 0, "", false},
+		{"Interface: Ethernet", 0, "", false},
+		{"Addr Type  DAD State   Valid Life Pref. Life Address", 0, "", false},
+	}
+	for _, tt := range tests {
+		index, name, ok := parseInterfaceHeader(tt.line)
+		if index != tt.wantIndex || name != tt.wantName || ok != tt.wantOK {
+			t.Errorf("parseInterfaceHeader(%q) = %d, %q, %t, want %d, %q, %t", tt.line, index, name, ok, tt.wantIndex, tt.wantName, tt.wantOK)
+		}
+	}
+}
+
+func TestParseDNSServerList(t *testing.T) {
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"ipv4_show_config_static_en.txt", []string{"10.0.0.53", "10.0.0.54", "8.8.8.8"}},
+		{"ipv4_show_config_static_ja.txt", []string{"10.0.0.53", "10.0.0.54", "8.8.8.8"}},
+		{"ipv4_show_config_dhcp_en.txt", []string{"192.168.1.1"}},
+		{"ipv4_show_config_dhcp_ja.txt", []string{"192.168.1.1", "192.168.1.2"}},
+		// 「なし」の場合は空
+		{"ipv4_show_config_nodns_ja.txt", nil},
+		{"ipv6_show_dnsservers_en.txt", []string{"2001:db8::53", "2001:4860:4860::8888"}},
+		{"ipv6_show_dnsservers_ja.txt", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := parseDNSServerList(readTestdata(t, tt.file)); !slices.Equal(got, tt.want) {
+				t.Errorf("parseDNSServerList() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
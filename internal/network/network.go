@@ -1,359 +1,424 @@
-package network
-
-import (
-	"fmt"
-	"os/exec"
-	"strings"
-	"syscall"
-
-	"github.com/fast-ip-change/fast-ip-change/internal/logger"
-	"github.com/fast-ip-change/fast-ip-change/pkg/models"
-)
-
-// Windows プロセス作成フラグ
-const (
-	createNoWindow = 0x08000000 // CREATE_NO_WINDOW: コンソールウィンドウを表示しない
-)
-
-// createHiddenCmd はコンソールウィンドウを表示しないコマンドを作成します
-func createHiddenCmd(name string, args ...string) *exec.Cmd {
-	cmd := exec.Command(name, args...)
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		HideWindow:    true,
-		CreationFlags: createNoWindow,
-	}
-	return cmd
-}
-
-// NetworkError はネットワーク関連のエラーを表します
-type NetworkError struct {
-	Code    string
-	Message string
-	Err     error
-}
-
-func (e *NetworkError) Error() string {
-	if e.Err != nil {
-		return fmt.Sprintf("%s: %s (%v)", e.Code, e.Message, e.Err)
-	}
-	return fmt.Sprintf("%s: %s", e.Code, e.Message)
-}
-
-// GetNICList は利用可能なNICのリストを取得します
-func GetNICList() ([]string, error) {
-	cmd := createHiddenCmd("netsh", "interface", "show", "interface")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, &NetworkError{
-			Code:    "GET_NIC_LIST_FAILED",
-			Message: "NICリストの取得に失敗しました",
-			Err:     err,
-		}
-	}
-
-	lines := strings.Split(string(output), "\n")
-	var nics []string
-	// ヘッダー行をスキップするためのパターン（日本語/英語両対応）
-	headerPatterns := []string{
-		"Admin State", "State", // 英語
-		"管理状態", "状態", // 日本語
-		"---", // セパレーター
-	}
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// ヘッダー行かどうかチェック
-		isHeader := false
-		for _, pattern := range headerPatterns {
-			if strings.HasPrefix(line, pattern) || strings.Contains(line, "-----") {
-				isHeader = true
-				break
-			}
-		}
-		if isHeader {
-			continue
-		}
-
-		// NIC名を抽出（フォーマット: "状態 タイプ NIC名"）
-		// 例: "接続済み    専用    イーサネット" または "Connected Dedicated Ethernet"
-		parts := strings.Fields(line)
-		if len(parts) >= 3 {
-			nicName := strings.Join(parts[3:], " ")
-			// 3列目以降がNIC名（4列フォーマット: 管理状態、状態、タイプ、NIC名）
-			if len(parts) >= 4 {
-				nicName = strings.Join(parts[3:], " ")
-			} else {
-				nicName = strings.Join(parts[2:], " ")
-			}
-			if nicName != "" {
-				nics = append(nics, nicName)
-			}
-		}
-	}
-
-	return nics, nil
-}
-
-// ipConfigPatterns は日本語/英語両対応のIP設定パターンを定義します
-var ipConfigPatterns = struct {
-	IP      []string
-	Subnet  []string
-	Gateway []string
-	DNS     []string
-}{
-	IP:      []string{"IP アドレス", "IP Address"},
-	Subnet:  []string{"サブネット マスク", "サブネット プレフィックス", "Subnet Mask", "Subnet Prefix"},
-	Gateway: []string{"デフォルト ゲートウェイ", "Default Gateway"},
-	DNS:     []string{"DNS サーバー", "DNS Servers"},
-}
-
-// GetCurrentIPConfig は指定されたNICの現在のIP設定を取得します
-func GetCurrentIPConfig(nicName string) (*models.Profile, error) {
-	cmd := createHiddenCmd("netsh", "interface", "ipv4", "show", "config", "name="+nicName)
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, &NetworkError{
-			Code:    "GET_IP_CONFIG_FAILED",
-			Message: fmt.Sprintf("NIC '%s' の設定取得に失敗しました", nicName),
-			Err:     err,
-		}
-	}
-
-	return parseIPConfig(nicName, string(output)), nil
-}
-
-// parseIPConfig はnetshの出力からIP設定を解析します
-func parseIPConfig(nicName, output string) *models.Profile {
-	profile := &models.Profile{
-		NICName: nicName,
-	}
-
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		if ip := extractValueByPrefixes(line, ipConfigPatterns.IP); ip != "" && profile.IPAddress == "" {
-			profile.IPAddress = ip
-		}
-
-		if subnet := extractSubnetValue(line); subnet != "" && profile.SubnetMask == "" {
-			profile.SubnetMask = subnet
-		}
-
-		if gw := extractValueByPrefixes(line, ipConfigPatterns.Gateway); gw != "" && profile.Gateway == "" {
-			profile.Gateway = gw
-		}
-
-		if dns := extractValueByPrefixes(line, ipConfigPatterns.DNS); dns != "" && profile.DNSPrimary == "" {
-			profile.DNSPrimary = dns
-		}
-	}
-
-	return profile
-}
-
-// extractValueByPrefixes は指定されたプレフィックスに一致する行から値を抽出します
-func extractValueByPrefixes(line string, prefixes []string) string {
-	for _, prefix := range prefixes {
-		if strings.HasPrefix(line, prefix) {
-			return extractLastValue(line)
-		}
-	}
-	return ""
-}
-
-// extractSubnetValue はサブネットマスク/プレフィックスの値を抽出します
-func extractSubnetValue(line string) string {
-	for _, prefix := range ipConfigPatterns.Subnet {
-		if strings.HasPrefix(line, prefix) {
-			value := extractLastValue(line)
-			// サブネットプレフィックス（例: /24）をマスク形式に変換
-			if strings.HasPrefix(value, "/") || isNumeric(value) {
-				return prefixToSubnetMask(value)
-			}
-			return value
-		}
-	}
-	return ""
-}
-
-// extractLastValue は行から最後の値を抽出します
-func extractLastValue(line string) string {
-	parts := strings.Fields(line)
-	if len(parts) >= 2 {
-		return parts[len(parts)-1]
-	}
-	return ""
-}
-
-// isNumeric は文字列が数値かどうかを判定します
-func isNumeric(s string) bool {
-	s = strings.TrimPrefix(s, "/")
-	for _, c := range s {
-		if c < '0' || c > '9' {
-			return false
-		}
-	}
-	return len(s) > 0
-}
-
-// prefixToSubnetMask はCIDRプレフィックスをサブネットマスクに変換します
-func prefixToSubnetMask(prefix string) string {
-	prefix = strings.TrimPrefix(prefix, "/")
-	prefixLen := 0
-	fmt.Sscanf(prefix, "%d", &prefixLen)
-
-	if prefixLen <= 0 || prefixLen > 32 {
-		return "255.255.255.0" // デフォルト
-	}
-
-	// プレフィックス長からマスクを計算
-	mask := uint32(0xFFFFFFFF) << (32 - prefixLen)
-	return fmt.Sprintf("%d.%d.%d.%d",
-		(mask>>24)&0xFF,
-		(mask>>16)&0xFF,
-		(mask>>8)&0xFF,
-		mask&0xFF)
-}
-
-// ApplyProfile はプロファイルの設定をNICに適用します
-func ApplyProfile(profile *models.Profile) error {
-	logger.Info("IPアドレス設定を適用中", "profile", profile.Name, "nic", profile.NICName)
-
-	// 現在の設定をバックアップ（将来のロールバック用）
-	currentConfig, err := GetCurrentIPConfig(profile.NICName)
-	if err != nil {
-		logger.Warn("現在の設定の取得に失敗（バックアップスキップ）", "error", err)
-	}
-
-	// IPアドレス設定を適用
-	if err := applyIPSettings(profile); err != nil {
-		return err
-	}
-
-	// DNS設定を適用
-	applyDNSSettings(profile)
-
-	// 設定が正しく適用されたか確認
-	if err := verifyProfileApplication(profile); err != nil {
-		return err
-	}
-
-	logger.Info("IPアドレス設定の適用が完了", "profile", profile.Name, "ip", profile.IPAddress)
-
-	// バックアップ情報をログに記録（将来のロールバック機能用）
-	if currentConfig != nil {
-		logger.Info("バックアップ設定", "previous_ip", currentConfig.IPAddress)
-	}
-
-	return nil
-}
-
-// applyIPSettings はIPアドレス、サブネットマスク、ゲートウェイを設定します
-func applyIPSettings(profile *models.Profile) error {
-	args := []string{"interface", "ipv4", "set", "address",
-		"name=" + profile.NICName,
-		"static",
-		profile.IPAddress,
-		profile.SubnetMask}
-	if profile.Gateway != "" {
-		args = append(args, profile.Gateway)
-	}
-
-	cmd := createHiddenCmd("netsh", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		logger.Error("IPアドレス設定の適用に失敗", err, "output", string(output))
-		return &NetworkError{
-			Code:    "APPLY_IP_FAILED",
-			Message: fmt.Sprintf("IPアドレス設定の適用に失敗しました: %s", string(output)),
-			Err:     err,
-		}
-	}
-	return nil
-}
-
-// applyDNSSettings はDNSサーバー設定を適用します
-// DNS設定の失敗は警告として記録し、処理は続行します
-func applyDNSSettings(profile *models.Profile) {
-	if profile.DNSPrimary != "" {
-		cmd := createHiddenCmd("netsh", "interface", "ipv4", "set", "dns",
-			"name="+profile.NICName,
-			"static",
-			profile.DNSPrimary)
-		if output, err := cmd.CombinedOutput(); err != nil {
-			logger.Error("DNS設定の適用に失敗", err, "output", string(output))
-		}
-	}
-
-	if profile.DNSSecondary != "" {
-		cmd := createHiddenCmd("netsh", "interface", "ipv4", "add", "dns",
-			"name="+profile.NICName,
-			profile.DNSSecondary,
-			"index=2")
-		if output, err := cmd.CombinedOutput(); err != nil {
-			logger.Error("代替DNS設定の適用に失敗", err, "output", string(output))
-		}
-	}
-}
-
-// verifyProfileApplication は設定が正しく適用されたかを確認します
-func verifyProfileApplication(profile *models.Profile) error {
-	appliedConfig, err := GetCurrentIPConfig(profile.NICName)
-	if err != nil {
-		logger.Warn("適用後の設定確認に失敗", "error", err)
-		return nil // 確認失敗は警告のみで続行
-	}
-
-	if appliedConfig.IPAddress != profile.IPAddress {
-		return &NetworkError{
-			Code:    "VERIFY_FAILED",
-			Message: "設定の適用が確認できませんでした",
-			Err:     fmt.Errorf("期待: %s, 実際: %s", profile.IPAddress, appliedConfig.IPAddress),
-		}
-	}
-	return nil
-}
-
-// ApplyDHCP は指定されたNICをDHCPに切り替えます
-func ApplyDHCP(nicName string) error {
-	// NIC名の検証（コマンドインジェクション対策）
-	if !models.IsValidNICName(nicName) {
-		return &NetworkError{
-			Code:    "INVALID_NIC_NAME",
-			Message: "NIC名に不正な文字が含まれています",
-		}
-	}
-
-	logger.Info("DHCPに切り替え中", "nic", nicName)
-
-	// IPアドレスをDHCPに設定
-	cmd := createHiddenCmd("netsh", "interface", "ipv4", "set", "address",
-		"name="+nicName,
-		"source=dhcp")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		logger.Error("DHCP設定の適用に失敗", err, "output", string(output))
-		return &NetworkError{
-			Code:    "APPLY_DHCP_FAILED",
-			Message: fmt.Sprintf("DHCP設定の適用に失敗しました: %s", string(output)),
-			Err:     err,
-		}
-	}
-
-	// DNSをDHCPに設定
-	dnsCmd := createHiddenCmd("netsh", "interface", "ipv4", "set", "dns",
-		"name="+nicName,
-		"source=dhcp")
-	if output, err := dnsCmd.CombinedOutput(); err != nil {
-		logger.Error("DNS DHCP設定の適用に失敗", err, "output", string(output))
-		// 警告として記録するが、処理は続行
-	}
-
-	logger.Info("DHCP設定の適用が完了", "nic", nicName)
-	return nil
-}
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// NetworkError はネットワーク関連のエラーを表します
+type NetworkError struct {
+	Code    string
+	Message string
+	Err     error
+	// Rollback は失敗後に実行したロールバックの結果です（ロールバック対象外の場合は nil）
+	Rollback *RollbackResult
+}
+
+func (e *NetworkError) Error() string {
+	msg := fmt.Sprintf("%s: %s", e.Code, e.Message)
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s (%v)", msg, e.Err)
+	}
+	if e.Rollback != nil {
+		msg += " " + e.Rollback.String()
+	}
+	return msg
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// ipConfigPatterns は日本語/英語両対応のIP設定パターンを定義します
+var ipConfigPatterns = struct {
+	IP      []string
+	Subnet  []string
+	Gateway []string
+	DNS     []string
+	DHCP    []string
+	Yes     []string
+}{
+	IP:      []string{"IP アドレス", "IP Address"},
+	Subnet:  []string{"サブネット マスク", "サブネット プレフィックス", "Subnet Mask", "Subnet Prefix"},
+	Gateway: []string{"デフォルト ゲートウェイ", "Default Gateway"},
+	DNS:     []string{"DNS サーバー", "DNS Servers", "DNS servers"},
+	DHCP:    []string{"DHCP 有効", "DHCP enabled"},
+	Yes:     []string{"はい", "Yes"},
+}
+
+// GetCurrentIPConfig は指定されたNICの現在のIP設定を取得します
+func (c *Client) GetCurrentIPConfig(nicName string) (*models.Profile, error) {
+	output, err := c.showIPConfig(nicName)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := parseIPConfig(nicName, output)
+	c.supplementConfig(profile)
+	return profile, nil
+}
+
+// supplementConfig は show config に含まれないIPv6とインターフェイスの設定を取得してプロファイルに追加します
+// 取得に失敗した項目は警告を記録して空のままにします（IPv6が利用できない環境でもIPv4の設定は返す）
+func (c *Client) supplementConfig(profile *models.Profile) (ipv6Captured bool) {
+	if output, err := c.showInterface(profile.NICName); err != nil {
+		logger.Warn("インターフェイス設定の取得に失敗", "nic", profile.NICName, "error", err)
+	} else {
+		parseInterfaceSettings(output, profile)
+	}
+
+	v6, err := c.getIPv6Config(profile.NICName)
+	if err != nil {
+		logger.Warn("IPv6設定の取得に失敗", "nic", profile.NICName, "error", err)
+		return false
+	}
+	v6.applyTo(profile)
+	return true
+}
+
+// showIPConfig は netsh interface ipv4 show config の出力を取得します
+func (c *Client) showIPConfig(nicName string) (string, error) {
+	output, err := c.runner.Output("netsh", "interface", "ipv4", "show", "config", "name="+nicName)
+	if err != nil {
+		return "", &NetworkError{
+			Code:    "GET_IP_CONFIG_FAILED",
+			Message: fmt.Sprintf("NIC '%s' の設定取得に失敗しました", nicName),
+			Err:     err,
+		}
+	}
+	return string(output), nil
+}
+
+// parseIPConfig はnetshの出力からIP設定を解析します
+func parseIPConfig(nicName, output string) *models.Profile {
+	profile := &models.Profile{
+		NICName: nicName,
+	}
+
+	lines := strings.Split(output, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+
+		// 2つ目以降のIPアドレスは追加アドレスとして扱う
+		if ip := extractValueByPrefixes(line, ipConfigPatterns.IP); ip != "" {
+			if profile.IPAddress == "" {
+				profile.IPAddress = ip
+			} else {
+				profile.AdditionalAddresses = append(profile.AdditionalAddresses, models.IPAddressEntry{IPAddress: ip})
+			}
+			continue
+		}
+
+		// サブネットは直前のIPアドレスに対応する
+		// 解析できないマスクは設定せず、ロールバック時に不正なマスクを netsh に渡さないようにする
+		subnet, err := extractSubnetValue(line)
+		if err != nil {
+			logger.Warn("サブネットマスクを解析できません", "nic", nicName, "line", line, "error", err)
+		} else if subnet != "" {
+			if n := len(profile.AdditionalAddresses); n > 0 {
+				if profile.AdditionalAddresses[n-1].SubnetMask == "" {
+					profile.AdditionalAddresses[n-1].SubnetMask = subnet
+				}
+			} else if profile.SubnetMask == "" {
+				profile.SubnetMask = subnet
+			}
+		}
+
+		if gw := extractValueByPrefixes(line, ipConfigPatterns.Gateway); gw != "" && profile.Gateway == "" {
+			profile.Gateway = gw
+		}
+	}
+
+	// DNSサーバーは継続行を含めてすべて取得
+	profile.DNSServers = parseDNSServerList(output)
+
+	return profile
+}
+
+// extractDNSValue はDNSサーバーの行から値を抽出します
+// 「静的に構成された DNS サーバー」「DNS servers configured through DHCP」のように
+// ラベルの前後に修飾語が付くため、前方一致ではなく部分一致で判定します
+func extractDNSValue(line string) string {
+	if !containsAny(line, ipConfigPatterns.DNS) {
+		return ""
+	}
+	value := extractLastValue(line)
+	// 「なし」「None」などの値は除外
+	if net.ParseIP(value) == nil {
+		return ""
+	}
+	return value
+}
+
+// containsAny は文字列にいずれかのパターンが含まれるかどうかを判定します
+func containsAny(s string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(s, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractValueByPrefixes は指定されたプレフィックスに一致する行から値を抽出します
+func extractValueByPrefixes(line string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(line, prefix) {
+			return extractLastValue(line)
+		}
+	}
+	return ""
+}
+
+// extractSubnetValue はサブネットマスク/プレフィックスの値をサブネットマスク形式で抽出します
+// サブネットの行でない場合は空文字列を返し、値がサブネットマスクとして不正な場合はエラーを返します
+// 例: "Subnet Prefix: 192.168.1.0/24 (mask 255.255.255.0)" → "255.255.255.0"
+func extractSubnetValue(line string) (string, error) {
+	for _, prefix := range ipConfigPatterns.Subnet {
+		if strings.HasPrefix(line, prefix) {
+			value := strings.TrimSuffix(extractLastValue(line), ")")
+			// "192.168.1.0/24" 形式の場合はプレフィックス部分を使用
+			if i := strings.LastIndex(value, "/"); i > 0 {
+				value = value[i:]
+			}
+			// サブネットプレフィックス（例: /24）はマスク形式に変換
+			return models.ParseSubnetMask(value)
+		}
+	}
+	return "", nil
+}
+
+// extractLastValue は行から最後の値を抽出します
+func extractLastValue(line string) string {
+	parts := strings.Fields(line)
+	if len(parts) >= 2 {
+		return parts[len(parts)-1]
+	}
+	return ""
+}
+
+// ApplyProfile はプロファイルの設定をNICに適用します
+func (c *Client) ApplyProfile(profile *models.Profile) error {
+	logger.Info("IPアドレス設定を適用中", "profile", profile.Name, "nic", profile.NICName)
+
+	// 現在の設定をバックアップ（失敗時のロールバック用）
+	snapshot, err := c.TakeSnapshot(profile.NICName)
+	if err != nil {
+		logger.Warn("現在の設定の取得に失敗（ロールバック不可）", "error", err)
+	}
+
+	_, err = c.applyProfile(profile, snapshot)
+	return err
+}
+
+// ApplyProfileWithSnapshot は取得済みのスナップショットをロールバックに使用してプロファイルを適用します
+// 呼び出し側で変更前の設定を記録する場合に、スナップショットの二重取得を避けるために使用します。
+// プロファイルに疎通確認がある場合はその結果も返します（疎通確認がない場合・適用に失敗した場合は nil）
+func (c *Client) ApplyProfileWithSnapshot(profile *models.Profile, snapshot *Snapshot) (*HealthReport, error) {
+	logger.Info("IPアドレス設定を適用中", "profile", profile.Name, "nic", profile.NICName)
+	return c.applyProfile(profile, snapshot)
+}
+
+// applyProfile はプロファイルを適用し、失敗時はスナップショットの設定に戻します
+// 適用後に疎通確認を行い、失敗した場合は RollbackOnHealthCheckFailure に応じて変更前の設定に戻します
+func (c *Client) applyProfile(profile *models.Profile, snapshot *Snapshot) (*HealthReport, error) {
+	if err := c.applySettings(profile, snapshot); err != nil {
+		return nil, err
+	}
+
+	report := c.RunHealthChecks(profile)
+	if report != nil && !report.Passed() && profile.RollbackOnHealthCheckFailure {
+		return report, c.rollback(snapshot, &NetworkError{
+			Code:    "HEALTH_CHECK_FAILED",
+			Message: "適用後の疎通確認に失敗しました",
+			Err:     &HealthCheckError{Report: report},
+		})
+	}
+	return report, nil
+}
+
+// applySettings はプロファイルの各設定を適用して確認し、失敗時はスナップショットの設定に戻します
+func (c *Client) applySettings(profile *models.Profile, snapshot *Snapshot) error {
+	// 他のホストが使用しているアドレスの場合は、何も変更せずに中止
+	if err := c.checkAddressConflicts(profile, snapshot); err != nil {
+		return err
+	}
+
+	// IPアドレス設定を適用
+	if err := c.applyIPSettings(profile); err != nil {
+		return err
+	}
+
+	// 追加アドレスを設定
+	if err := c.applyAdditionalAddresses(profile); err != nil {
+		return c.rollback(snapshot, err)
+	}
+
+	// 前のプロファイルのルートを削除し、このプロファイルのルートを追加
+	if err := c.replaceRoutes(profile.NICName, profile.Name, profile.Routes); err != nil {
+		return c.rollback(snapshot, err)
+	}
+
+	// インターフェイスメトリックとMTUを設定
+	if err := c.applyInterfaceSettings(profile); err != nil {
+		return c.rollback(snapshot, err)
+	}
+
+	// DNS設定を適用
+	if err := c.applyDNSSettings(profile); err != nil {
+		return c.rollback(snapshot, err)
+	}
+
+	// IPv6設定を適用（指定されている場合のみ）
+	if profile.HasIPv6() {
+		if err := c.applyIPv6Settings(profile, false); err != nil {
+			return c.rollback(snapshot, err)
+		}
+	}
+
+	// 設定が正しく適用されたか確認
+	if err := c.verifyProfileApplication(profile); err != nil {
+		return c.rollback(snapshot, err)
+	}
+
+	// Windows がアドレスの重複を検出していないか確認
+	if err := c.checkDuplicateAddresses(profile); err != nil {
+		return c.rollback(snapshot, err)
+	}
+
+	logger.Info("IPアドレス設定の適用が完了", "profile", profile.Name, "ip", profile.IPAddress)
+
+	if snapshot != nil && snapshot.Config != nil {
+		logger.Info("バックアップ設定", "previous_ip", snapshot.Config.IPAddress, "previous_dhcp", snapshot.DHCPEnabled)
+	}
+
+	return nil
+}
+
+// applyIPSettings はIPアドレス、サブネットマスク、ゲートウェイを設定します
+func (c *Client) applyIPSettings(profile *models.Profile) error {
+	output, err := c.run(ipSettingsCommand(profile))
+	if err != nil {
+		logger.Error("IPアドレス設定の適用に失敗", err, "output", string(output))
+		return &NetworkError{
+			Code:    "APPLY_IP_FAILED",
+			Message: fmt.Sprintf("IPアドレス設定の適用に失敗しました: %s", string(output)),
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// applyAdditionalAddresses はプライマリ以外のアドレスを追加します
+func (c *Client) applyAdditionalAddresses(profile *models.Profile) error {
+	for _, cmd := range additionalAddressCommands(profile) {
+		if output, err := c.run(cmd); err != nil {
+			logger.Error("追加アドレスの設定に失敗", err, "output", string(output))
+			return &NetworkError{
+				Code:    "APPLY_ADDRESS_FAILED",
+				Message: fmt.Sprintf("追加アドレスの設定に失敗しました: %s", string(output)),
+				Err:     err,
+			}
+		}
+	}
+	return nil
+}
+
+// applyDNSSettings はDNSサーバー設定を適用します
+func (c *Client) applyDNSSettings(profile *models.Profile) error {
+	for _, cmd := range dnsSettingsCommands(profile) {
+		if output, err := c.run(cmd); err != nil {
+			label := "DNS設定"
+			if cmd.Name != "netsh" {
+				label = "DNSサフィックス設定"
+			}
+			logger.Error(label+"の適用に失敗", err, "output", string(output))
+			return &NetworkError{
+				Code:    "APPLY_DNS_FAILED",
+				Message: fmt.Sprintf("%sの適用に失敗しました: %s", label, string(output)),
+				Err:     err,
+			}
+		}
+	}
+	return nil
+}
+
+// verifyProfileApplication は設定が正しく適用されたかを確認します
+func (c *Client) verifyProfileApplication(profile *models.Profile) error {
+	appliedConfig, err := c.GetCurrentIPConfig(profile.NICName)
+	if err != nil {
+		logger.Warn("適用後の設定確認に失敗", "error", err)
+		return nil // 確認失敗は警告のみで続行
+	}
+
+	if appliedConfig.IPAddress != profile.IPAddress {
+		return &NetworkError{
+			Code:    "VERIFY_FAILED",
+			Message: "設定の適用が確認できませんでした",
+			Err:     fmt.Errorf("期待: %s, 実際: %s", profile.IPAddress, appliedConfig.IPAddress),
+		}
+	}
+
+	// 追加アドレスがすべて設定されているか確認
+	applied := make(map[string]bool)
+	for _, entry := range appliedConfig.AdditionalAddresses {
+		applied[entry.IPAddress] = true
+	}
+	for _, entry := range profile.AdditionalAddresses {
+		if !applied[entry.IPAddress] {
+			return &NetworkError{
+				Code:    "VERIFY_FAILED",
+				Message: "追加アドレスの適用が確認できませんでした",
+				Err:     fmt.Errorf("追加アドレス %s が見つかりません", entry.IPAddress),
+			}
+		}
+	}
+
+	if err := verifyInterfaceSettings(appliedConfig, profile); err != nil {
+		return err
+	}
+
+	return verifyIPv6(appliedConfig, profile)
+}
+
+// ApplyDHCP は指定されたNICをDHCPに切り替えます
+func (c *Client) ApplyDHCP(nicName string) error {
+	// NIC名の検証（コマンドインジェクション対策）
+	if !models.IsValidNICName(nicName) {
+		return &NetworkError{
+			Code:    "INVALID_NIC_NAME",
+			Message: "NIC名に不正な文字が含まれています",
+		}
+	}
+
+	logger.Info("DHCPに切り替え中", "nic", nicName)
+
+	// IPアドレスをDHCPに設定
+	output, err := c.run(dhcpAddressCommand(nicName))
+	if err != nil {
+		logger.Error("DHCP設定の適用に失敗", err, "output", string(output))
+		return &NetworkError{
+			Code:    "APPLY_DHCP_FAILED",
+			Message: fmt.Sprintf("DHCP設定の適用に失敗しました: %s", string(output)),
+			Err:     err,
+		}
+	}
+
+	// DNSをDHCPに設定
+	if output, err := c.run(dhcpDNSCommand(nicName)); err != nil {
+		logger.Error("DNS DHCP設定の適用に失敗", err, "output", string(output))
+		// 警告として記録するが、処理は続行
+	}
+
+	// 前のプロファイルが追加したルートを削除
+	if err := c.replaceRoutes(nicName, "", nil); err != nil {
+		logger.Warn("ルートの削除に失敗", "nic", nicName, "error", err)
+	}
+
+	logger.Info("DHCP設定の適用が完了", "nic", nicName)
+	return nil
+}
@@ -0,0 +1,410 @@
+package network
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/network/networktest"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+const (
+	// dhcpConfigOutput は DHCP で 192.168.1.50 を取得している状態の netsh interface ipv4 show config の出力です
+	dhcpConfigOutput = `
+Configuration for interface "Ethernet"
+    DHCP enabled:                         Yes
+    IP Address:                           192.168.1.50
+    Subnet Prefix:                        192.168.1.0/24 (mask 255.255.255.0)
+    Default Gateway:                      192.168.1.1
+    Gateway Metric:                       0
+    InterfaceMetric:                      25
+    DNS servers configured through DHCP:  192.168.1.1
+    Register with which suffix:           Primary only
+    WINS servers configured through DHCP: None
+`
+	// staticConfigOutput は testProfile を適用した後の netsh interface ipv4 show config の出力です
+	staticConfigOutput = `
+Configuration for interface "Ethernet"
+    DHCP enabled:                         No
+    IP Address:                           10.0.0.10
+    Subnet Prefix:                        10.0.0.0/24 (mask 255.255.255.0)
+    Default Gateway:                      10.0.0.1
+    Gateway Metric:                       256
+    InterfaceMetric:                      25
+    Statically Configured DNS Servers:    10.0.0.53
+                                          8.8.8.8
+    Register with which suffix:           Primary only
+    Statically Configured WINS Servers:   None
+`
+)
+
+// Ethernet のコマンド
+const (
+	cmdShowConfig      = "netsh interface ipv4 show config name=Ethernet"
+	cmdShowInterface   = "netsh interface ipv4 show interface interface=Ethernet"
+	cmdShowIPv6        = "netsh interface ipv6 show addresses"
+	cmdSetAddress      = "netsh interface ipv4 set address name=Ethernet static 10.0.0.10 255.255.255.0 10.0.0.1"
+	cmdSetDNS          = "netsh interface ipv4 set dns name=Ethernet static 10.0.0.53"
+	cmdAddDNS          = "netsh interface ipv4 add dns name=Ethernet 8.8.8.8 index=2"
+	cmdAddressState    = "powershell -NoProfile -NonInteractive -Command Get-NetIPAddress"
+	cmdDHCPAddress     = "netsh interface ipv4 set address name=Ethernet source=dhcp"
+	cmdDHCPDNS         = "netsh interface ipv4 set dns name=Ethernet source=dhcp"
+	addressStatePrefer = "10.0.0.10 Preferred\r\n"
+)
+
+func testProfile() *models.Profile {
+	return &models.Profile{
+		Name:       "オフィス",
+		NICName:    "Ethernet",
+		IPAddress:  "10.0.0.10",
+		SubnetMask: "255.255.255.0",
+		Gateway:    "10.0.0.1",
+		DNSServers: []string{"10.0.0.53", "8.8.8.8"},
+	}
+}
+
+// newTestRunner は show config の応答に configs を順に返す FakeRunner を作成します
+// インターフェイス設定の取得は失敗させ、IPv6 は無効（Ethernet の見出しなし）とします
+func newTestRunner(configs ...string) *networktest.FakeRunner {
+	runner := networktest.NewFakeRunner()
+	for _, output := range configs {
+		runner.On(cmdShowConfig, output)
+	}
+	return runner.
+		OnError(cmdShowInterface, "", errors.New("exit status 1")).
+		On(cmdShowIPv6, "")
+}
+
+// newTestClient は設定を変更するコマンドが成功するよう応答を登録し、runner を使用する Client を作成します
+// 応答は登録順に返されるため、失敗させるコマンドは呼び出し前に runner に登録します
+func newTestClient(runner *networktest.FakeRunner) *Client {
+	runner.
+		On(cmdSetAddress, "").
+		On(cmdSetDNS, "").
+		On(cmdAddDNS, "").
+		On(cmdAddressState, addressStatePrefer).
+		On(cmdDHCPAddress, "").
+		On(cmdDHCPDNS, "")
+	client := NewClient(runner)
+	client.SetAddressProber(stubProber{})
+	return client
+}
+
+// changes は記録されたコマンドのうち、設定を変更するもの（取得・確認以外）を返します
+func changes(runner *networktest.FakeRunner) []string {
+	var cmds []string
+	for _, cmd := range runner.Commands() {
+		switch {
+		case cmd == cmdShowConfig, cmd == cmdShowInterface, cmd == cmdShowIPv6:
+		case strings.HasPrefix(cmd, cmdAddressState):
+		default:
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds
+}
+
+func TestApplyProfile(t *testing.T) {
+	runner := newTestRunner(dhcpConfigOutput, staticConfigOutput)
+	client := newTestClient(runner)
+
+	if err := client.ApplyProfile(testProfile()); err != nil {
+		t.Fatalf("ApplyProfile() error = %v", err)
+	}
+
+	want := []string{cmdSetAddress, cmdSetDNS, cmdAddDNS}
+	if got := changes(runner); !slices.Equal(got, want) {
+		t.Errorf("commands =\n%q\nwant\n%q", got, want)
+	}
+
+	// シェルを経由せず、引数を1つずつ渡す
+	var setAddress networktest.Call
+	for _, call := range runner.Calls() {
+		if call.String() == cmdSetAddress {
+			setAddress = call
+		}
+	}
+	wantArgs := []string{"interface", "ipv4", "set", "address", "name=Ethernet", "static", "10.0.0.10", "255.255.255.0", "10.0.0.1"}
+	if setAddress.Name != "netsh" || !slices.Equal(setAddress.Args, wantArgs) {
+		t.Errorf("set address = %s %q, want netsh %q", setAddress.Name, setAddress.Args, wantArgs)
+	}
+}
+
+func TestApplyProfileVerifyFailureRollsBack(t *testing.T) {
+	// 適用後も変更前のアドレスのまま
+	runner := newTestRunner(dhcpConfigOutput)
+	client := newTestClient(runner)
+
+	err := client.ApplyProfile(testProfile())
+
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) {
+		t.Fatalf("ApplyProfile() error = %v, want *NetworkError", err)
+	}
+	if netErr.Code != "VERIFY_FAILED" {
+		t.Errorf("Code = %s, want VERIFY_FAILED", netErr.Code)
+	}
+	if netErr.Rollback == nil || !netErr.Rollback.Succeeded() {
+		t.Errorf("Rollback = %+v, want succeeded", netErr.Rollback)
+	}
+
+	want := []string{cmdSetAddress, cmdSetDNS, cmdAddDNS, cmdDHCPAddress, cmdDHCPDNS}
+	if got := changes(runner); !slices.Equal(got, want) {
+		t.Errorf("commands =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestApplyProfileRollbackFailure(t *testing.T) {
+	rollbackErr := errors.New("exit status 1")
+	runner := newTestRunner(dhcpConfigOutput).
+		OnError(cmdSetDNS, "構成に失敗しました。", errors.New("exit status 1")).
+		OnError(cmdDHCPAddress, "", rollbackErr)
+	client := newTestClient(runner)
+
+	err := client.ApplyProfile(testProfile())
+
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) {
+		t.Fatalf("ApplyProfile() error = %v, want *NetworkError", err)
+	}
+	if netErr.Code != "APPLY_DNS_FAILED" {
+		t.Errorf("Code = %s, want APPLY_DNS_FAILED", netErr.Code)
+	}
+	if netErr.Rollback == nil || !netErr.Rollback.Attempted || netErr.Rollback.Succeeded() {
+		t.Fatalf("Rollback = %+v, want attempted and failed", netErr.Rollback)
+	}
+	if !errors.Is(netErr.Rollback.Err, rollbackErr) {
+		t.Errorf("Rollback.Err = %v, want %v", netErr.Rollback.Err, rollbackErr)
+	}
+
+	// DNS の失敗で中断し、DHCP への切り替えに失敗した時点でロールバックも中断する
+	want := []string{cmdSetAddress, cmdSetDNS, cmdDHCPAddress}
+	if got := changes(runner); !slices.Equal(got, want) {
+		t.Errorf("commands =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestApplyProfileWithoutSnapshot(t *testing.T) {
+	runner := networktest.NewFakeRunner().
+		OnError(cmdShowConfig, "", errors.New("exit status 1")).
+		OnError(cmdSetDNS, "", errors.New("exit status 1"))
+	client := newTestClient(runner)
+
+	err := client.ApplyProfile(testProfile())
+
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) {
+		t.Fatalf("ApplyProfile() error = %v, want *NetworkError", err)
+	}
+	if netErr.Rollback == nil || netErr.Rollback.Attempted || !errors.Is(netErr.Rollback.Err, errNoSnapshot) {
+		t.Errorf("Rollback = %+v, want not attempted (errNoSnapshot)", netErr.Rollback)
+	}
+}
+
+func TestApplyDHCP(t *testing.T) {
+	runner := networktest.NewFakeRunner()
+	client := newTestClient(runner)
+
+	if err := client.ApplyDHCP("Ethernet"); err != nil {
+		t.Fatalf("ApplyDHCP() error = %v", err)
+	}
+	want := []string{cmdDHCPAddress, cmdDHCPDNS}
+	if got := runner.Commands(); !slices.Equal(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+}
+
+func TestApplyDHCPErrors(t *testing.T) {
+	t.Run("invalid NIC name", func(t *testing.T) {
+		runner := networktest.NewFakeRunner()
+		client := newTestClient(runner)
+		err := client.ApplyDHCP(`Ethernet" & calc`)
+
+		var netErr *NetworkError
+		if !errors.As(err, &netErr) || netErr.Code != "INVALID_NIC_NAME" {
+			t.Errorf("ApplyDHCP() error = %v, want INVALID_NIC_NAME", err)
+		}
+		if calls := runner.Calls(); len(calls) != 0 {
+			t.Errorf("commands = %q, want none", runner.Commands())
+		}
+	})
+
+	t.Run("address failure", func(t *testing.T) {
+		client := newTestClient(networktest.NewFakeRunner().
+			OnError(cmdDHCPAddress, "", errors.New("exit status 1")))
+
+		var netErr *NetworkError
+		if err := client.ApplyDHCP("Ethernet"); !errors.As(err, &netErr) || netErr.Code != "APPLY_DHCP_FAILED" {
+			t.Errorf("ApplyDHCP() error = %v, want APPLY_DHCP_FAILED", err)
+		}
+	})
+
+	t.Run("DNS failure continues", func(t *testing.T) {
+		client := newTestClient(networktest.NewFakeRunner().
+			OnError(cmdDHCPDNS, "", errors.New("exit status 1")))
+
+		if err := client.ApplyDHCP("Ethernet"); err != nil {
+			t.Errorf("ApplyDHCP() error = %v, want nil", err)
+		}
+	})
+}
+
+func TestParseIPConfig(t *testing.T) {
+	tests := []struct {
+		file            string
+		nic             string
+		want            models.Profile
+		wantDHCP        bool
+		wantDNSFromDHCP bool
+	}{
+		{
+			file: "ipv4_show_config_static_en.txt",
+			nic:  "Ethernet",
+			want: models.Profile{IPAddress: "10.0.0.10", SubnetMask: "255.255.255.0", Gateway: "10.0.0.1",
+				DNSServers: []string{"10.0.0.53", "10.0.0.54", "8.8.8.8"}},
+		},
+		{
+			file: "ipv4_show_config_static_ja.txt",
+			nic:  "イーサネット",
+			want: models.Profile{IPAddress: "10.0.0.10", SubnetMask: "255.255.255.0", Gateway: "10.0.0.1",
+				DNSServers: []string{"10.0.0.53", "10.0.0.54", "8.8.8.8"}},
+		},
+		{
+			file:            "ipv4_show_config_dhcp_en.txt",
+			nic:             "Wi-Fi",
+			want:            models.Profile{IPAddress: "192.168.1.50", SubnetMask: "255.255.255.0", Gateway: "192.168.1.1", DNSServers: []string{"192.168.1.1"}},
+			wantDHCP:        true,
+			wantDNSFromDHCP: true,
+		},
+		{
+			file:            "ipv4_show_config_dhcp_ja.txt",
+			nic:             "Wi-Fi",
+			want:            models.Profile{IPAddress: "192.168.1.50", SubnetMask: "255.255.255.0", Gateway: "192.168.1.1", DNSServers: []string{"192.168.1.1", "192.168.1.2"}},
+			wantDHCP:        true,
+			wantDNSFromDHCP: true,
+		},
+		{
+			file: "ipv4_show_config_nodns_ja.txt",
+			nic:  "イーサネット 2",
+			want: models.Profile{IPAddress: "172.16.0.10", SubnetMask: "255.255.0.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			output := readTestdata(t, tt.file)
+			got := parseIPConfig(tt.nic, output)
+			if got.NICName != tt.nic || got.IPAddress != tt.want.IPAddress || got.SubnetMask != tt.want.SubnetMask ||
+				got.Gateway != tt.want.Gateway || !slices.Equal(got.DNSServers, tt.want.DNSServers) {
+				t.Errorf("parseIPConfig() = %s %s/%s gw=%q dns=%q, want %s %s/%s gw=%q dns=%q",
+					got.NICName, got.IPAddress, got.SubnetMask, got.Gateway, got.DNSServers,
+					tt.nic, tt.want.IPAddress, tt.want.SubnetMask, tt.want.Gateway, tt.want.DNSServers)
+			}
+
+			dhcp, dnsFromDHCP := parseDHCPState(output)
+			if dhcp != tt.wantDHCP || dnsFromDHCP != tt.wantDNSFromDHCP {
+				t.Errorf("parseDHCPState() = %t, %t, want %t, %t", dhcp, dnsFromDHCP, tt.wantDHCP, tt.wantDNSFromDHCP)
+			}
+		})
+	}
+}
+
+func TestExtractSubnetValue(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    string
+		wantErr bool
+	}{
+		{line: "Subnet Prefix:                        10.0.0.0/24 (mask 255.255.255.0)", want: "255.255.255.0"},
+		{line: "サブネット プレフィックス:            10.0.0.0/16 (マスク 255.255.0.0)", want: "255.255.0.0"},
+		{line: "Subnet Mask:                          255.255.255.128", want: "255.255.255.128"},
+		{line: "Subnet Prefix:                        10.0.0.0/30", want: "255.255.255.252"},
+		{line: "IP Address:                           10.0.0.10", want: ""},
+		// 変換できない値は空文字列ではなくエラーを返す
+		{line: "Subnet Prefix:                        0.0.0.0/0", wantErr: true},
+		{line: "Subnet Mask:                          255.0.255.0", wantErr: true},
+		{line: "Subnet Prefix:                        10.0.0.0/33", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := extractSubnetValue(tt.line)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("extractSubnetValue(%q) = %q, %v, want %q (error: %t)", tt.line, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRestoreSnapshotRejectsUnparsedMask(t *testing.T) {
+	output := strings.Replace(dhcpConfigOutput, "192.168.1.0/24 (mask 255.255.255.0)", "0.0.0.0/0", 1)
+	output = strings.Replace(output, "DHCP enabled:                         Yes", "DHCP enabled:                         No", 1)
+	runner := newTestRunner(output)
+	client := newTestClient(runner)
+
+	snapshot, err := client.TakeSnapshot("Ethernet")
+	if err != nil {
+		t.Fatalf("TakeSnapshot() error = %v", err)
+	}
+	if snapshot.Config.SubnetMask != "" {
+		t.Errorf("SubnetMask = %q, want empty", snapshot.Config.SubnetMask)
+	}
+
+	// 不正なマスクで netsh を実行せずに中止する
+	runner.Reset()
+	var netErr *NetworkError
+	if err := client.RestoreSnapshot(snapshot); !errors.As(err, &netErr) || netErr.Code != "RESTORE_FAILED" {
+		t.Errorf("RestoreSnapshot() error = %v, want RESTORE_FAILED", err)
+	}
+	if got := changes(runner); len(got) != 0 {
+		t.Errorf("commands = %q, want none", got)
+	}
+}
+
+func TestDNSSettingsCommands(t *testing.T) {
+	registerDNS := false
+	tests := []struct {
+		name    string
+		profile models.Profile
+		want    []string
+	}{
+		{"none", models.Profile{NICName: "Ethernet"}, nil},
+		{
+			"ordered servers",
+			models.Profile{NICName: "Ethernet", DNSServers: []string{"10.0.0.53", "10.0.0.54", "8.8.8.8"}},
+			[]string{
+				"netsh interface ipv4 set dns name=Ethernet static 10.0.0.53",
+				"netsh interface ipv4 add dns name=Ethernet 10.0.0.54 index=2",
+				"netsh interface ipv4 add dns name=Ethernet 8.8.8.8 index=3",
+			},
+		},
+		{
+			"suffix and registration",
+			models.Profile{NICName: "Ethernet", DNSServers: []string{"10.0.0.53"}, DNSSuffix: "corp.example.com", RegisterDNS: &registerDNS},
+			[]string{
+				"netsh interface ipv4 set dns name=Ethernet static 10.0.0.53",
+				"powershell -NoProfile -NonInteractive -Command Set-DnsClient -InterfaceAlias 'Ethernet' -ConnectionSpecificSuffix 'corp.example.com' -RegisterThisConnectionsAddress $false",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, cmd := range dnsSettingsCommands(&tt.profile) {
+				got = append(got, cmd.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("dnsSettingsCommands() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// stubProber は固定の結果を返す AddressProber です
+type stubProber struct {
+	// macs はアドレスごとに応答したホストのMACアドレスです
+	macs map[string]string
+	err  error
+}
+
+func (p stubProber) Probe(nicName, address string) (string, error) {
+	return p.macs[address], p.err
+}
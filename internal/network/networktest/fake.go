@@ -0,0 +1,125 @@
+// Package networktest は network パッケージをWindows以外の環境でテストするための
+// Runner の実装を提供します
+package networktest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Call は FakeRunner が受け取ったコマンド呼び出しを表します
+type Call struct {
+	Name string
+	Args []string
+}
+
+// String はコマンドラインを空白区切りの文字列で返します
+func (c Call) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// Response はコマンドに対して返す応答を表します
+type Response struct {
+	Output string
+	Err    error
+}
+
+// FakeRunner は登録済みの出力を再生し、発行されたコマンドを記録する network.Runner の実装です
+// 応答はコマンドライン（コマンド名と引数を空白で連結した文字列）の前方一致で選択され、
+// 複数の登録が一致する場合は最も長いプレフィックスが優先されます
+type FakeRunner struct {
+	mu        sync.Mutex
+	responses map[string][]Response
+	calls     []Call
+}
+
+// NewFakeRunner は空の FakeRunner を作成します
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{
+		responses: make(map[string][]Response),
+	}
+}
+
+// On は prefix に一致するコマンドに output を返すよう登録します
+// 同じ prefix に複数回登録した場合は登録順に応答し、最後の応答はその後も繰り返し返されます
+func (f *FakeRunner) On(prefix, output string) *FakeRunner {
+	return f.OnResponse(prefix, Response{Output: output})
+}
+
+// OnError は prefix に一致するコマンドが output とともに err を返すよう登録します
+func (f *FakeRunner) OnError(prefix, output string, err error) *FakeRunner {
+	return f.OnResponse(prefix, Response{Output: output, Err: err})
+}
+
+// OnResponse は prefix に一致するコマンドに resp を返すよう登録します
+func (f *FakeRunner) OnResponse(prefix string, resp Response) *FakeRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[prefix] = append(f.responses[prefix], resp)
+	return f
+}
+
+// Output はコマンドを記録し、登録済みの出力を返します
+func (f *FakeRunner) Output(name string, args ...string) ([]byte, error) {
+	return f.run(name, args...)
+}
+
+// CombinedOutput はコマンドを記録し、登録済みの出力を返します
+func (f *FakeRunner) CombinedOutput(name string, args ...string) ([]byte, error) {
+	return f.run(name, args...)
+}
+
+// Calls は記録されたコマンド呼び出しを発行順に返します
+func (f *FakeRunner) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	calls := make([]Call, len(f.calls))
+	copy(calls, f.calls)
+	return calls
+}
+
+// Commands は記録されたコマンドラインを発行順に返します
+func (f *FakeRunner) Commands() []string {
+	calls := f.Calls()
+	commands := make([]string, len(calls))
+	for i, call := range calls {
+		commands[i] = call.String()
+	}
+	return commands
+}
+
+// Reset は記録されたコマンド呼び出しを消去します（登録済みの応答は保持します）
+func (f *FakeRunner) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
+
+func (f *FakeRunner) run(name string, args ...string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	call := Call{Name: name, Args: append([]string(nil), args...)}
+	f.calls = append(f.calls, call)
+
+	commandLine := call.String()
+	matched := ""
+	found := false
+	for prefix := range f.responses {
+		if strings.HasPrefix(commandLine, prefix) && (!found || len(prefix) > len(matched)) {
+			matched = prefix
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("networktest: 応答が登録されていないコマンドです: %s", commandLine)
+	}
+
+	queue := f.responses[matched]
+	resp := queue[0]
+	if len(queue) > 1 {
+		f.responses[matched] = queue[1:]
+	}
+	return []byte(resp.Output), resp.Err
+}
@@ -0,0 +1,212 @@
+package network
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// NIC の管理状態と接続状態（表示言語に依存しない値）
+const (
+	NICAdminEnabled  = "enabled"
+	NICAdminDisabled = "disabled"
+	NICOperUp        = "up"
+	NICOperDown      = "down"
+)
+
+// NIC はネットワークアダプターの情報です
+type NIC struct {
+	Name string `json:"name"`
+	// Index はインターフェイスのインデックスです（netsh から取得した場合は 0）
+	Index int `json:"index,omitempty"`
+	// AdminState は管理状態です（NICAdminEnabled / NICAdminDisabled）
+	AdminState string `json:"adminState"`
+	// OperState は接続状態です（NICOperUp / NICOperDown）
+	OperState string `json:"operState"`
+	// Type は種類です（Get-NetAdapter の MediaType、netsh から取得した場合は "Dedicated" などの種類）
+	Type string `json:"type,omitempty"`
+	// Description はアダプターの説明（製品名）です（netsh から取得した場合は空）
+	Description string `json:"description,omitempty"`
+	// MAC はMACアドレスです（"00-11-22-AA-BB-CC" の形式。netsh から取得した場合は空）
+	MAC string `json:"mac,omitempty"`
+}
+
+// Enabled は管理状態が有効かどうかを返します
+func (n *NIC) Enabled() bool {
+	return n.AdminState == NICAdminEnabled
+}
+
+// Up は接続しているかどうかを返します
+func (n *NIC) Up() bool {
+	return n.OperState == NICOperUp
+}
+
+// StateString は状態を表示用の文字列で返します（例: "接続"、"切断"、"無効"）
+func (n *NIC) StateString() string {
+	switch {
+	case !n.Enabled():
+		return "無効"
+	case n.Up():
+		return "接続"
+	default:
+		return "切断"
+	}
+}
+
+// NICNames はNICの名前の一覧を返します
+func NICNames(nics []NIC) []string {
+	names := make([]string, len(nics))
+	for i := range nics {
+		names[i] = nics[i].Name
+	}
+	return names
+}
+
+// nicListScript はネットワークアダプターの一覧を JSON の配列で出力するスクリプトです
+// 状態は列挙型の名前（"Up" など）で出力されるため、Windows の表示言語に依存しません
+// 既定ではコンソールのコードページ（日本語版では Shift_JIS）で出力され、日本語のNIC名が文字化けするため、UTF-8 で出力します
+const nicListScript = `[Console]::OutputEncoding = [Text.Encoding]::UTF8; $ErrorActionPreference = 'Stop'; ` +
+	`ConvertTo-Json -Compress -InputObject @(Get-NetAdapter | Sort-Object ifIndex | ForEach-Object { ` +
+	`[pscustomobject]@{ name = $_.Name; index = [int]$_.ifIndex; adminStatus = [string]$_.AdminStatus; status = [string]$_.Status; ` +
+	`mediaType = [string]$_.MediaType; description = [string]$_.InterfaceDescription; mac = [string]$_.MacAddress } })`
+
+// GetNICList は利用可能なNICの一覧を取得します
+// Get-NetAdapter で取得し、使用できない場合は netsh interface show interface の出力を解析します
+func (c *Client) GetNICList() ([]NIC, error) {
+	cmd := powershellCommand(nicListScript)
+	output, err := c.runner.Output(cmd.Name, cmd.Args...)
+	if err == nil {
+		nics, parseErr := parseNetAdapters(output)
+		if parseErr == nil {
+			return nics, nil
+		}
+		err = parseErr
+	}
+	logger.Debug("Get-NetAdapter でNICの一覧を取得できないため、netsh で取得します", "error", err)
+
+	output, err = c.runner.Output("netsh", "interface", "show", "interface")
+	if err != nil {
+		return nil, &NetworkError{
+			Code:    "GET_NIC_LIST_FAILED",
+			Message: "NICリストの取得に失敗しました",
+			Err:     err,
+		}
+	}
+	return parseNetshInterfaces(string(output)), nil
+}
+
+// netAdapter は nicListScript が出力するアダプターの情報です
+type netAdapter struct {
+	Name        string `json:"name"`
+	Index       int    `json:"index"`
+	AdminStatus string `json:"adminStatus"`
+	Status      string `json:"status"`
+	MediaType   string `json:"mediaType"`
+	Description string `json:"description"`
+	MAC         string `json:"mac"`
+}
+
+// parseNetAdapters は nicListScript の出力を解析します
+// ConvertTo-Json は要素が1つの配列を（PowerShell のバージョンによっては）オブジェクトとして出力するため、どちらの形式も受け付けます
+func parseNetAdapters(output []byte) ([]NIC, error) {
+	// UTF-8 で出力すると先頭に BOM が付く場合がある
+	output = bytes.TrimSpace(bytes.TrimPrefix(output, []byte("\xef\xbb\xbf")))
+
+	var adapters []netAdapter
+	if bytes.HasPrefix(output, []byte("{")) {
+		var adapter netAdapter
+		if err := json.Unmarshal(output, &adapter); err != nil {
+			return nil, err
+		}
+		adapters = append(adapters, adapter)
+	} else if err := json.Unmarshal(output, &adapters); err != nil {
+		return nil, err
+	}
+
+	nics := make([]NIC, 0, len(adapters))
+	for _, a := range adapters {
+		nic := NIC{
+			Name:        a.Name,
+			Index:       a.Index,
+			AdminState:  NICAdminEnabled,
+			OperState:   NICOperDown,
+			Type:        a.MediaType,
+			Description: a.Description,
+		}
+		// 無効にしたアダプターは AdminStatus が Down、Status が Disabled になる
+		if strings.EqualFold(a.AdminStatus, "Down") || strings.EqualFold(a.Status, "Disabled") {
+			nic.AdminState = NICAdminDisabled
+		}
+		if strings.EqualFold(a.Status, "Up") {
+			nic.OperState = NICOperUp
+		}
+		if mac, err := models.NormalizeMAC(a.MAC); err == nil {
+			nic.MAC = mac
+		}
+		nics = append(nics, nic)
+	}
+	return nics, nil
+}
+
+// netshInterfaceValues は netsh interface show interface の状態・種類の表示を日本語/英語両対応で定義します
+var netshInterfaceValues = struct {
+	Enabled   []string
+	Connected []string
+	Types     map[string]string
+}{
+	Enabled:   []string{"有効", "Enabled"},
+	Connected: []string{"接続済み", "接続", "Connected"},
+	Types: map[string]string{
+		"専用":     "Dedicated",
+		"ループバック": "Loopback",
+		"内部":     "Internal",
+	},
+}
+
+// parseNetshInterfaces は netsh interface show interface の出力からNICの一覧を抽出します
+// 区切り線より後の行を「管理状態 状態 種類 インターフェイス名」として読み取ります（名前は空白を含む場合があります）
+// 例:
+//
+//	管理状態     状態           種類             インターフェイス名
+//	-------------------------------------------------------------------------
+//	有効             接続済み           専用               イーサネット
+//	無効             切断             専用               Wi-Fi 2
+func parseNetshInterfaces(output string) []NIC {
+	var nics []NIC
+	separated := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "---") {
+			separated = true
+			continue
+		}
+		if !separated || line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		nic := NIC{
+			Name:       strings.Join(fields[3:], " "),
+			AdminState: NICAdminDisabled,
+			OperState:  NICOperDown,
+			Type:       fields[2],
+		}
+		if equalsAny(fields[0], netshInterfaceValues.Enabled) {
+			nic.AdminState = NICAdminEnabled
+		}
+		if equalsAny(fields[1], netshInterfaceValues.Connected) {
+			nic.OperState = NICOperUp
+		}
+		if t, ok := netshInterfaceValues.Types[nic.Type]; ok {
+			nic.Type = t
+		}
+		nics = append(nics, nic)
+	}
+	return nics
+}
@@ -0,0 +1,149 @@
+package network
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/network/networktest"
+)
+
+const (
+	cmdNICList      = "powershell -NoProfile -NonInteractive -Command [Console]::OutputEncoding"
+	cmdNetshNICList = "netsh interface show interface"
+)
+
+// ethernetAdapter は get_netadapter_*.json のイーサネットです
+var ethernetAdapter = NIC{
+	Name:        "イーサネット",
+	Index:       12,
+	AdminState:  NICAdminEnabled,
+	OperState:   NICOperUp,
+	Type:        "802.3",
+	Description: "Intel(R) Ethernet Connection (7) I219-LM",
+	MAC:         "00-11-22-AA-BB-CC",
+}
+
+func TestParseNetAdapters(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    []NIC
+		wantErr bool
+	}{
+		{
+			name:   "array",
+			output: readTestdata(t, "get_netadapter_array.json"),
+			want: []NIC{
+				ethernetAdapter,
+				{Name: "Wi-Fi 2", Index: 15, AdminState: NICAdminDisabled, OperState: NICOperDown, Type: "Native 802.11",
+					Description: "Intel(R) Wi-Fi 6 AX201 160MHz", MAC: "00-11-22-DD-EE-FF"},
+				{Name: "vEthernet (Default Switch)", Index: 23, AdminState: NICAdminEnabled, OperState: NICOperDown, Type: "802.3",
+					Description: "Hyper-V Virtual Ethernet Adapter"},
+			},
+		},
+		// アダプターが1つの場合、ConvertTo-Json は配列ではなくオブジェクトを出力する場合がある
+		{name: "single object", output: readTestdata(t, "get_netadapter_single.json"), want: []NIC{ethernetAdapter}},
+		{name: "BOM", output: "\xef\xbb\xbf" + readTestdata(t, "get_netadapter_single.json"), want: []NIC{ethernetAdapter}},
+		{name: "empty", output: "[]", want: []NIC{}},
+		{name: "error message", output: "Get-NetAdapter : 用語 'Get-NetAdapter' は認識されません。", wantErr: true},
+		{name: "no output", output: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNetAdapters([]byte(tt.output))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseNetAdapters() error = %v, want error: %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseNetAdapters() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNetshInterfaces(t *testing.T) {
+	tests := []struct {
+		file string
+		want []NIC
+	}{
+		{"show_interface_en.txt", []NIC{
+			{Name: "Ethernet", AdminState: NICAdminEnabled, OperState: NICOperUp, Type: "Dedicated"},
+			{Name: "Wi-Fi 2", AdminState: NICAdminDisabled, OperState: NICOperDown, Type: "Dedicated"},
+			{Name: "Ethernet 3", AdminState: NICAdminEnabled, OperState: NICOperDown, Type: "Dedicated"},
+			{Name: "Loopback Pseudo-Interface 1", AdminState: NICAdminEnabled, OperState: NICOperUp, Type: "Loopback"},
+		}},
+		// 種類は英語の表記に変換する
+		{"show_interface_ja.txt", []NIC{
+			{Name: "イーサネット", AdminState: NICAdminEnabled, OperState: NICOperUp, Type: "Dedicated"},
+			{Name: "Wi-Fi 2", AdminState: NICAdminDisabled, OperState: NICOperDown, Type: "Dedicated"},
+			{Name: "イーサネット 3", AdminState: NICAdminEnabled, OperState: NICOperDown, Type: "Dedicated"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := parseNetshInterfaces(readTestdata(t, tt.file)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseNetshInterfaces() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := parseNetshInterfaces(""); len(got) != 0 {
+		t.Errorf("parseNetshInterfaces(\"\") = %+v, want none", got)
+	}
+}
+
+func TestGetNICList(t *testing.T) {
+	tests := []struct {
+		name      string
+		runner    *networktest.FakeRunner
+		wantNames []string
+		wantCode  string
+	}{
+		{
+			name: "Get-NetAdapter",
+			runner: networktest.NewFakeRunner().
+				On(cmdNICList, readTestdata(t, "get_netadapter_single.json")),
+			wantNames: []string{"イーサネット"},
+		},
+		{
+			name: "fallback on error",
+			runner: networktest.NewFakeRunner().
+				OnError(cmdNICList, "", errors.New("exit status 1")).
+				On(cmdNetshNICList, readTestdata(t, "show_interface_ja.txt")),
+			wantNames: []string{"イーサネット", "Wi-Fi 2", "イーサネット 3"},
+		},
+		{
+			name: "fallback on invalid output",
+			runner: networktest.NewFakeRunner().
+				On(cmdNICList, "Get-NetAdapter : 用語 'Get-NetAdapter' は認識されません。").
+				On(cmdNetshNICList, readTestdata(t, "show_interface_en.txt")),
+			wantNames: []string{"Ethernet", "Wi-Fi 2", "Ethernet 3", "Loopback Pseudo-Interface 1"},
+		},
+		{
+			name: "both failed",
+			runner: networktest.NewFakeRunner().
+				OnError(cmdNICList, "", errors.New("exit status 1")).
+				OnError(cmdNetshNICList, "", errors.New("exit status 1")),
+			wantCode: "GET_NIC_LIST_FAILED",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nics, err := NewClient(tt.runner).GetNICList()
+			if tt.wantCode != "" {
+				var netErr *NetworkError
+				if !errors.As(err, &netErr) || netErr.Code != tt.wantCode {
+					t.Errorf("GetNICList() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetNICList() error = %v", err)
+			}
+			if got := NICNames(nics); !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("GetNICList() = %q, want %q", got, tt.wantNames)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package network
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// FieldChange は現在の設定と適用後の設定の1項目分の差分を表します
+type FieldChange struct {
+	Field   string `json:"field"`
+	Label   string `json:"label"`
+	Current string `json:"current"`
+	Target  string `json:"target"`
+	Changed bool   `json:"changed"`
+}
+
+// Plan はプロファイルまたはDHCPを適用した場合に発行されるコマンドと設定の差分を表します
+// Plan の作成はNICの設定を変更しません
+type Plan struct {
+	NICName     string        `json:"nicName"`
+	ProfileName string        `json:"profileName,omitempty"`
+	DHCP        bool          `json:"dhcp"`
+	Commands    []Command     `json:"commands"`
+	Changes     []FieldChange `json:"changes"`
+	// Warnings は現在の設定が取得できなかった場合などの注意事項です
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// HasChanges は設定に変更があるかどうかを返します
+func (p *Plan) HasChanges() bool {
+	for _, change := range p.Changes {
+		if change.Changed {
+			return true
+		}
+	}
+	return false
+}
+
+// String は確認用のテキストを返します
+func (p *Plan) String() string {
+	var b strings.Builder
+	if p.DHCP {
+		fmt.Fprintf(&b, "対象: %s → DHCP（自動取得）\n", p.NICName)
+	} else {
+		fmt.Fprintf(&b, "対象: %s → %s\n", p.NICName, p.ProfileName)
+	}
+
+	b.WriteString("\n変更内容:\n")
+	for _, change := range p.Changes {
+		mark := " "
+		if change.Changed {
+			mark = "*"
+		}
+		fmt.Fprintf(&b, "%s %s: %s → %s\n", mark, change.Label, displayValue(change.Current), displayValue(change.Target))
+	}
+
+	b.WriteString("\n実行するコマンド:\n")
+	for _, cmd := range p.Commands {
+		fmt.Fprintf(&b, "  %s\n", cmd)
+	}
+
+	if len(p.Warnings) > 0 {
+		b.WriteString("\n注意:\n")
+		for _, warning := range p.Warnings {
+			fmt.Fprintf(&b, "  %s\n", warning)
+		}
+	}
+	return b.String()
+}
+
+// displayValue は空の値を表示用の文字列に変換します
+func displayValue(value string) string {
+	if value == "" {
+		return "（なし）"
+	}
+	return value
+}
+
+// PlanProfile はプロファイルを適用した場合に発行されるコマンドと設定の差分を返します
+func (c *Client) PlanProfile(profile *models.Profile) (*Plan, error) {
+	if !models.IsValidNICName(profile.NICName) {
+		return nil, &NetworkError{
+			Code:    "INVALID_NIC_NAME",
+			Message: "NIC名に不正な文字が含まれています",
+		}
+	}
+
+	plan := &Plan{
+		NICName:     profile.NICName,
+		ProfileName: profile.Name,
+	}
+	plan.Commands = append(plan.Commands, ipSettingsCommand(profile))
+	plan.Commands = append(plan.Commands, additionalAddressCommands(profile)...)
+
+	current := c.planSnapshot(plan)
+	var currentRoutes []models.StaticRoute
+	if current != nil {
+		currentRoutes = current.Routes
+	}
+	plan.Commands = append(plan.Commands, routeCommands(profile.NICName, currentRoutes, profile.Routes)...)
+	plan.Commands = append(plan.Commands, interfaceCommands(profile)...)
+	plan.Commands = append(plan.Commands, dnsSettingsCommands(profile)...)
+
+	if profile.HasIPv6() {
+		currentIPv6 := &ipv6Config{}
+		if current != nil && current.Config != nil {
+			currentIPv6 = ipv6ConfigFromProfile(current.Config)
+		}
+		plan.Commands = append(plan.Commands, ipv6Commands(currentIPv6, profile, false)...)
+	}
+	plan.Changes = diffProfile(current, profile)
+	plan.Changes = append(plan.Changes, newFieldChange("routes", "静的ルート", joinRoutes(currentRoutes), joinRoutes(profile.Routes)))
+	return plan, nil
+}
+
+// PlanDHCP は指定されたNICをDHCPに切り替えた場合に発行されるコマンドと設定の差分を返します
+func (c *Client) PlanDHCP(nicName string) (*Plan, error) {
+	if !models.IsValidNICName(nicName) {
+		return nil, &NetworkError{
+			Code:    "INVALID_NIC_NAME",
+			Message: "NIC名に不正な文字が含まれています",
+		}
+	}
+
+	plan := &Plan{
+		NICName:  nicName,
+		DHCP:     true,
+		Commands: []Command{dhcpAddressCommand(nicName), dhcpDNSCommand(nicName)},
+	}
+
+	current := c.planSnapshot(plan)
+	currentMode := ""
+	var currentRoutes []models.StaticRoute
+	if current != nil {
+		currentMode = addressMode(current.DHCPEnabled)
+		currentRoutes = current.Routes
+	}
+	plan.Commands = append(plan.Commands, routeCommands(nicName, currentRoutes, nil)...)
+	plan.Changes = []FieldChange{
+		newFieldChange("addressMode", "アドレスの取得方法", currentMode, addressMode(true)),
+		newFieldChange("routes", "静的ルート", joinRoutes(currentRoutes), ""),
+	}
+	return plan, nil
+}
+
+// planSnapshot は差分計算用に現在の設定を取得します
+// 取得に失敗した場合は警告を記録して nil を返します
+func (c *Client) planSnapshot(plan *Plan) *Snapshot {
+	snapshot, err := c.TakeSnapshot(plan.NICName)
+	if err != nil {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("現在の設定を取得できませんでした: %v", err))
+		return nil
+	}
+	return snapshot
+}
+
+// diffProfile は現在の設定と適用するプロファイルの差分を項目ごとに返します
+func diffProfile(current *Snapshot, target *models.Profile) []FieldChange {
+	var cur models.Profile
+	currentMode := ""
+	if current != nil {
+		currentMode = addressMode(current.DHCPEnabled)
+		if current.Config != nil {
+			cur = *current.Config
+		}
+	}
+
+	changes := []FieldChange{
+		newFieldChange("addressMode", "アドレスの取得方法", currentMode, addressMode(false)),
+		newFieldChange("ipAddress", "IPアドレス", cur.IPAddress, target.IPAddress),
+		newFieldChange("subnetMask", "サブネットマスク", cur.SubnetMask, target.SubnetMask),
+		newFieldChange("additionalAddresses", "追加アドレス", joinAddresses(cur.AdditionalAddresses), joinAddresses(target.AdditionalAddresses)),
+		newFieldChange("gateway", "デフォルトゲートウェイ", cur.Gateway, target.Gateway),
+		newFieldChange("dnsServers", "DNSサーバー", strings.Join(cur.DNSServers, ", "), strings.Join(target.DNSServers, ", ")),
+	}
+
+	// インターフェイスメトリック、MTU、IPv6は指定された項目のみ変更されるため、指定がある場合だけ表示
+	if target.InterfaceMetric != "" {
+		changes = append(changes, newFieldChange("interfaceMetric", "インターフェイスメトリック", cur.InterfaceMetric, target.InterfaceMetric))
+	}
+	if target.MTU != 0 {
+		changes = append(changes, newFieldChange("mtu", "MTU", formatMTU(cur.MTU), formatMTU(target.MTU)))
+	}
+	if target.IPv6Address != "" {
+		changes = append(changes, newFieldChange("ipv6Address", "IPv6アドレス", cur.IPv6CIDR(), target.IPv6CIDR()))
+	}
+	if target.IPv6Gateway != "" {
+		changes = append(changes, newFieldChange("ipv6Gateway", "IPv6ゲートウェイ", cur.IPv6Gateway, target.IPv6Gateway))
+	}
+	if len(target.IPv6DNSServers) > 0 {
+		changes = append(changes, newFieldChange("ipv6DnsServers", "IPv6 DNSサーバー",
+			strings.Join(cur.IPv6DNSServers, ", "), strings.Join(target.IPv6DNSServers, ", ")))
+	}
+	return changes
+}
+
+func newFieldChange(field, label, current, target string) FieldChange {
+	return FieldChange{
+		Field:   field,
+		Label:   label,
+		Current: current,
+		Target:  target,
+		Changed: current != target,
+	}
+}
+
+// joinAddresses はアドレスの一覧を表示用の文字列に変換します
+func joinAddresses(entries []models.IPAddressEntry) string {
+	values := make([]string, len(entries))
+	for i, entry := range entries {
+		values[i] = entry.String()
+	}
+	return strings.Join(values, ", ")
+}
+
+// formatMTU はMTUを表示用の文字列に変換します（0 は空文字列）
+func formatMTU(mtu int) string {
+	if mtu == 0 {
+		return ""
+	}
+	return strconv.Itoa(mtu)
+}
+
+// joinRoutes はルートの一覧を表示用の文字列に変換します
+func joinRoutes(routes []models.StaticRoute) string {
+	values := make([]string, len(routes))
+	for i, route := range routes {
+		values[i] = route.String()
+	}
+	return strings.Join(values, ", ")
+}
+
+// addressMode はアドレスの取得方法を表示用の文字列で返します
+func addressMode(dhcp bool) string {
+	if dhcp {
+		return "DHCP"
+	}
+	return "静的"
+}
@@ -0,0 +1,219 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// errNoSnapshot は変更前の設定が取得できずロールバックできない場合のエラーです
+var errNoSnapshot = errors.New("変更前の設定を取得できませんでした")
+
+// Snapshot は設定変更前に取得したNICの設定を表します
+type Snapshot struct {
+	NICName     string          `json:"nicName"`
+	DHCPEnabled bool            `json:"dhcpEnabled"`
+	DNSFromDHCP bool            `json:"dnsFromDhcp"`
+	Config      *models.Profile `json:"config,omitempty"`
+	// IPv6Captured はIPv6の設定を取得できたかどうかです（false の場合、IPv6は復元しません）
+	IPv6Captured bool `json:"ipv6Captured"`
+	// Routes は変更前にプロファイルが追加していたルートです
+	Routes      []models.StaticRoute `json:"routes,omitempty"`
+	RoutesOwner string               `json:"routesOwner,omitempty"`
+}
+
+// RollbackResult はロールバックの実行結果を表します
+type RollbackResult struct {
+	// Attempted はロールバックを実行したかどうかです（スナップショットがない場合は false）
+	Attempted bool
+	// Err はロールバックが失敗した場合のエラーです
+	Err error
+}
+
+// Succeeded はロールバックが成功したかどうかを返します
+func (r *RollbackResult) Succeeded() bool {
+	return r.Attempted && r.Err == nil
+}
+
+// String はロールバック結果を通知向けの文字列で返します
+func (r *RollbackResult) String() string {
+	switch {
+	case r.Succeeded():
+		return "（変更前の設定に戻しました）"
+	case !r.Attempted:
+		return fmt.Sprintf("（ロールバックできませんでした: %v）", r.Err)
+	default:
+		return fmt.Sprintf("（ロールバックに失敗しました: %v）", r.Err)
+	}
+}
+
+// TakeSnapshot は指定されたNICの現在の設定をロールバック用に取得します
+func (c *Client) TakeSnapshot(nicName string) (*Snapshot, error) {
+	output, err := c.showIPConfig(nicName)
+	if err != nil {
+		return nil, err
+	}
+
+	dhcpEnabled, dnsFromDHCP := parseDHCPState(output)
+	snapshot := &Snapshot{
+		NICName:     nicName,
+		DHCPEnabled: dhcpEnabled,
+		DNSFromDHCP: dnsFromDHCP,
+		Config:      parseIPConfig(nicName, output),
+	}
+	snapshot.RoutesOwner, snapshot.Routes = c.installedRoutes(nicName)
+	snapshot.IPv6Captured = c.supplementConfig(snapshot.Config)
+	return snapshot, nil
+}
+
+// RestoreSnapshot はスナップショットの設定をNICに適用します
+// DHCPが有効だった場合はDHCPに戻し、静的設定だった場合は取得したIPアドレスとDNSを再設定します
+func (c *Client) RestoreSnapshot(snapshot *Snapshot) error {
+	if snapshot == nil {
+		return errNoSnapshot
+	}
+
+	logger.Info("変更前の設定に戻しています", "nic", snapshot.NICName, "dhcp", snapshot.DHCPEnabled)
+
+	if snapshot.DHCPEnabled {
+		if err := c.ApplyDHCP(snapshot.NICName); err != nil {
+			return err
+		}
+		if err := c.replaceRoutes(snapshot.NICName, snapshot.RoutesOwner, snapshot.Routes); err != nil {
+			return err
+		}
+		if err := c.restoreInterfaceSettings(snapshot); err != nil {
+			return err
+		}
+		return c.restoreIPv6Settings(snapshot)
+	}
+
+	if snapshot.Config == nil || snapshot.Config.IPAddress == "" || snapshot.Config.SubnetMask == "" {
+		return &NetworkError{
+			Code:    "RESTORE_FAILED",
+			Message: fmt.Sprintf("NIC '%s' の変更前の設定に復元できるIPアドレスがありません", snapshot.NICName),
+		}
+	}
+
+	restore := *snapshot.Config
+	restore.NICName = snapshot.NICName
+	if err := c.applyIPSettings(&restore); err != nil {
+		return err
+	}
+
+	if err := c.applyAdditionalAddresses(&restore); err != nil {
+		return err
+	}
+
+	if err := c.replaceRoutes(snapshot.NICName, snapshot.RoutesOwner, snapshot.Routes); err != nil {
+		return err
+	}
+
+	if err := c.restoreInterfaceSettings(snapshot); err != nil {
+		return err
+	}
+
+	if err := c.restoreDNSSettings(snapshot, &restore); err != nil {
+		return err
+	}
+
+	if err := c.restoreIPv6Settings(snapshot); err != nil {
+		return err
+	}
+
+	return c.verifyProfileApplication(&restore)
+}
+
+// restoreDNSSettings はスナップショットのDNS設定を復元します
+func (c *Client) restoreDNSSettings(snapshot *Snapshot, restore *models.Profile) error {
+	var cmd Command
+	switch {
+	case snapshot.DNSFromDHCP:
+		cmd = dhcpDNSCommand(snapshot.NICName)
+	case len(restore.DNSServers) == 0:
+		cmd = netshCommand("interface", "ipv4", "set", "dns", "name="+snapshot.NICName, "static", "none")
+	default:
+		return c.applyDNSSettings(restore)
+	}
+
+	if output, err := c.run(cmd); err != nil {
+		logger.Error("DNS設定の復元に失敗", err, "output", string(output))
+		return &NetworkError{
+			Code:    "APPLY_DNS_FAILED",
+			Message: fmt.Sprintf("DNS設定の復元に失敗しました: %s", string(output)),
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// restoreInterfaceSettings はスナップショットのインターフェイスメトリックとMTUを復元します
+// 取得できなかった項目は変更しません
+func (c *Client) restoreInterfaceSettings(snapshot *Snapshot) error {
+	if snapshot.Config == nil {
+		return nil
+	}
+	restore := *snapshot.Config
+	restore.NICName = snapshot.NICName
+	return c.applyInterfaceSettings(&restore)
+}
+
+// restoreIPv6Settings はスナップショットのIPv6設定を復元します
+// 適用時に追加したアドレスやゲートウェイは削除されます
+func (c *Client) restoreIPv6Settings(snapshot *Snapshot) error {
+	if !snapshot.IPv6Captured || snapshot.Config == nil {
+		return nil
+	}
+	restore := *snapshot.Config
+	restore.NICName = snapshot.NICName
+	return c.applyIPv6Settings(&restore, true)
+}
+
+// rollback はスナップショットの設定に戻し、元のエラーにロールバック結果を付加して返します
+func (c *Client) rollback(snapshot *Snapshot, cause error) error {
+	netErr := &NetworkError{}
+	var original *NetworkError
+	if errors.As(cause, &original) {
+		*netErr = *original
+	} else {
+		netErr.Code = "APPLY_FAILED"
+		netErr.Message = "設定の適用に失敗しました"
+		netErr.Err = cause
+	}
+
+	result := &RollbackResult{}
+	if snapshot == nil {
+		result.Err = errNoSnapshot
+	} else {
+		result.Attempted = true
+		result.Err = c.RestoreSnapshot(snapshot)
+	}
+	netErr.Rollback = result
+
+	if result.Succeeded() {
+		logger.Info("ロールバックが完了", "nic", snapshot.NICName)
+	} else {
+		logger.Error("ロールバックに失敗", result.Err, "cause", cause)
+	}
+	return netErr
+}
+
+// parseDHCPState はnetshの出力からDHCPの有効状態とDNSの取得元を解析します
+func parseDHCPState(output string) (dhcpEnabled, dnsFromDHCP bool) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		if containsAny(line, ipConfigPatterns.DHCP) && !containsAny(line, ipConfigPatterns.DNS) {
+			dhcpEnabled = containsAny(extractLastValue(line), ipConfigPatterns.Yes)
+		}
+
+		// 「DHCP 経由で構成された DNS サーバー」「DNS servers configured through DHCP」
+		if containsAny(line, ipConfigPatterns.DNS) && strings.Contains(line, "DHCP") {
+			dnsFromDHCP = true
+		}
+	}
+	return dhcpEnabled, dnsFromDHCP
+}
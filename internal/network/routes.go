@@ -0,0 +1,125 @@
+package network
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// RouteStore はプロファイルがNICに追加した静的ルートを記録します
+// プロファイルを切り替える際、前のプロファイルのルートを削除するために使用します
+type RouteStore interface {
+	// InstalledRoutes は指定されたNICに追加済みのルートと、追加したプロファイル名を返します
+	InstalledRoutes(nicName string) (owner string, routes []models.StaticRoute, err error)
+	// SetInstalledRoutes は指定されたNICに追加したルートを記録します（routes が空の場合は記録を削除）
+	SetInstalledRoutes(nicName, owner string, routes []models.StaticRoute) error
+}
+
+// SetRouteStore は追加したルートの記録先を設定します
+// 設定されていない場合、プロファイルのルートは追加されますが切り替え時に削除されません
+func (c *Client) SetRouteStore(store RouteStore) {
+	c.routes = store
+}
+
+// routeAddCommand は静的ルートを永続的に追加するコマンドを返します
+func routeAddCommand(nicName string, route models.StaticRoute) Command {
+	args := []string{"interface", "ipv4", "add", "route",
+		"prefix=" + routePrefix(route),
+		"interface=" + nicName}
+	if route.Gateway != "" {
+		args = append(args, "nexthop="+route.Gateway)
+	}
+	if route.Metric > 0 {
+		args = append(args, "metric="+strconv.Itoa(route.Metric))
+	}
+	args = append(args, "store=persistent")
+	return netshCommand(args...)
+}
+
+// routeDeleteCommand は静的ルートを削除するコマンドを返します
+func routeDeleteCommand(nicName string, route models.StaticRoute) Command {
+	args := []string{"interface", "ipv4", "delete", "route",
+		"prefix=" + routePrefix(route),
+		"interface=" + nicName}
+	if route.Gateway != "" {
+		args = append(args, "nexthop="+route.Gateway)
+	}
+	args = append(args, "store=persistent")
+	return netshCommand(args...)
+}
+
+// routePrefix はルートの宛先を "宛先/プレフィックス長" 形式で返します
+func routePrefix(route models.StaticRoute) string {
+	ones, _ := models.MaskToPrefixLength(route.SubnetMask)
+	return fmt.Sprintf("%s/%d", route.Destination, ones)
+}
+
+// routeCommands は前のプロファイルのルートを削除し、新しいルートを追加するコマンドを返します
+func routeCommands(nicName string, previous, next []models.StaticRoute) []Command {
+	var cmds []Command
+	for _, route := range previous {
+		cmds = append(cmds, routeDeleteCommand(nicName, route))
+	}
+	for _, route := range next {
+		cmds = append(cmds, routeAddCommand(nicName, route))
+	}
+	return cmds
+}
+
+// installedRoutes は記録済みのルートを返します（記録先がない場合や取得に失敗した場合は nil）
+func (c *Client) installedRoutes(nicName string) (string, []models.StaticRoute) {
+	if c.routes == nil {
+		return "", nil
+	}
+	owner, routes, err := c.routes.InstalledRoutes(nicName)
+	if err != nil {
+		logger.Warn("追加済みルートの取得に失敗", "nic", nicName, "error", err)
+		return "", nil
+	}
+	return owner, routes
+}
+
+// replaceRoutes は前のプロファイルのルートを削除して新しいルートを追加し、記録を更新します
+// 既に存在しないルートの削除に失敗しても処理は続行します
+func (c *Client) replaceRoutes(nicName, owner string, next []models.StaticRoute) error {
+	_, previous := c.installedRoutes(nicName)
+	if len(previous) == 0 && len(next) == 0 {
+		return nil
+	}
+
+	for _, route := range previous {
+		if output, err := c.run(routeDeleteCommand(nicName, route)); err != nil {
+			logger.Warn("ルートの削除に失敗", "route", route.String(), "error", err, "output", string(output))
+		}
+	}
+
+	var added []models.StaticRoute
+	for _, route := range next {
+		if output, err := c.run(routeAddCommand(nicName, route)); err != nil {
+			logger.Error("ルートの追加に失敗", err, "route", route.String(), "output", string(output))
+			// 途中まで追加したルートも削除できるように記録しておく
+			c.recordRoutes(nicName, owner, added)
+			return &NetworkError{
+				Code:    "APPLY_ROUTE_FAILED",
+				Message: fmt.Sprintf("ルート %s の追加に失敗しました: %s", route, string(output)),
+				Err:     err,
+			}
+		}
+		added = append(added, route)
+	}
+
+	c.recordRoutes(nicName, owner, added)
+	return nil
+}
+
+// recordRoutes は追加したルートを記録します
+func (c *Client) recordRoutes(nicName, owner string, routes []models.StaticRoute) {
+	if c.routes == nil {
+		return
+	}
+	if err := c.routes.SetInstalledRoutes(nicName, owner, routes); err != nil {
+		logger.Warn("追加したルートの記録に失敗", "nic", nicName, "error", err)
+	}
+}
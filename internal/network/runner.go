@@ -0,0 +1,24 @@
+package network
+
+// Runner は外部コマンドの実行を抽象化します
+// 既定では ExecRunner を使用し、テストでは networktest.FakeRunner に差し替えることで
+// netsh を実行せずに設定の適用・確認処理を検証できます
+type Runner interface {
+	// Output はコマンドを実行して標準出力を返します
+	Output(name string, args ...string) ([]byte, error)
+	// CombinedOutput はコマンドを実行して標準出力と標準エラー出力をまとめて返します
+	CombinedOutput(name string, args ...string) ([]byte, error)
+}
+
+// ExecRunner はコンソールウィンドウを表示せずに実際のコマンドを実行する Runner です
+type ExecRunner struct{}
+
+// Output はコマンドを実行して標準出力を返します
+func (ExecRunner) Output(name string, args ...string) ([]byte, error) {
+	return createHiddenCmd(name, args...).Output()
+}
+
+// CombinedOutput はコマンドを実行して標準出力と標準エラー出力をまとめて返します
+func (ExecRunner) CombinedOutput(name string, args ...string) ([]byte, error) {
+	return createHiddenCmd(name, args...).CombinedOutput()
+}
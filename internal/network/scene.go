@@ -0,0 +1,149 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// SceneStepResult はシーンの1つのNICの適用結果です
+type SceneStepResult struct {
+	Target models.SceneTarget
+	// Before は変更前の設定です（ロールバックと履歴に使用）
+	Before *Snapshot
+	// Applied はこのNICの適用に成功したかどうかです（後のNICの失敗でロールバックした場合も true）
+	Applied bool
+	// Err はこのNICの適用に失敗した場合のエラーです
+	Err error
+	// Rollback は変更前の設定に戻した場合の結果です（ロールバックしていない場合は nil）
+	Rollback *RollbackResult
+	// Health はプロファイルの疎通確認の結果です（疎通確認がない場合は nil）
+	Health *HealthReport
+}
+
+// SceneError はシーンの適用に失敗した場合のエラーです
+// 失敗したNICまでに変更したNICは、逆の順序で変更前の設定に戻します
+type SceneError struct {
+	// Failed は失敗したステップの位置です（-1 の場合は変更前の設定の取得に失敗し、何も変更していません）
+	Failed  int
+	Err     error
+	Results []SceneStepResult
+}
+
+func (e *SceneError) Error() string {
+	if e.Failed < 0 {
+		return fmt.Sprintf("シーンを適用できません（NICの設定は変更していません）: %v", e.Err)
+	}
+
+	msg := fmt.Sprintf("%s の適用に失敗しました: %v", e.Results[e.Failed].Target.NICName, e.Err)
+	if failed := e.FailedRollbacks(); len(failed) > 0 {
+		return msg + fmt.Sprintf("（%s を変更前の設定に戻せませんでした）", strings.Join(failed, ", "))
+	}
+	return msg + "（すべてのNICを変更前の設定に戻しました）"
+}
+
+func (e *SceneError) Unwrap() error {
+	return e.Err
+}
+
+// FailedRollbacks は変更前の設定に戻せなかったNICの名前を返します
+func (e *SceneError) FailedRollbacks() []string {
+	var nics []string
+	for _, result := range e.Results {
+		if result.Rollback != nil && !result.Rollback.Succeeded() {
+			nics = append(nics, result.Target.NICName)
+		}
+	}
+	return nics
+}
+
+// ApplyScene は複数のNICに順に設定を適用します
+// 適用前にすべてのNICの設定を取得し、取得できないNICがある場合は何も変更しません。
+// いずれかのNICで失敗した場合は、それまでに変更したNICを逆の順序で変更前の設定に戻し、*SceneError を返します
+func (c *Client) ApplyScene(targets []models.SceneTarget) ([]SceneStepResult, error) {
+	results := make([]SceneStepResult, len(targets))
+	for i, target := range targets {
+		results[i].Target = target
+		if !models.IsValidNICName(target.NICName) {
+			return results, &SceneError{Failed: -1, Results: results, Err: &NetworkError{
+				Code:    "INVALID_NIC_NAME",
+				Message: fmt.Sprintf("NIC名に不正な文字が含まれています: %s", target.NICName),
+			}}
+		}
+	}
+
+	// 失敗時にすべてのNICを戻せるよう、変更前の設定を先に取得する
+	for i := range results {
+		snapshot, err := c.TakeSnapshot(results[i].Target.NICName)
+		if err != nil {
+			return results, &SceneError{Failed: -1, Results: results, Err: err}
+		}
+		results[i].Before = snapshot
+	}
+
+	for i := range results {
+		result := &results[i]
+		logger.Info("シーンのステップを適用中", "step", i, "target", result.Target.Description())
+
+		var err error
+		if result.Target.Profile != nil {
+			// 失敗した場合は applyProfile がこのNICを変更前の設定に戻す
+			result.Health, err = c.applyProfile(result.Target.Profile, result.Before)
+		} else if err = c.ApplyDHCP(result.Target.NICName); err != nil {
+			err = c.rollback(result.Before, err)
+		}
+
+		if err == nil {
+			result.Applied = true
+			continue
+		}
+
+		result.Err = err
+		var netErr *NetworkError
+		if errors.As(err, &netErr) {
+			result.Rollback = netErr.Rollback
+		}
+		c.rollbackScene(results[:i])
+		return results, &SceneError{Failed: i, Err: err, Results: results}
+	}
+
+	logger.Info("シーンの適用が完了", "steps", len(results))
+	return results, nil
+}
+
+// rollbackScene は適用したNICを逆の順序で変更前の設定に戻します
+func (c *Client) rollbackScene(results []SceneStepResult) {
+	for i := len(results) - 1; i >= 0; i-- {
+		result := &results[i]
+		result.Rollback = &RollbackResult{Attempted: true, Err: c.RestoreSnapshot(result.Before)}
+		if result.Rollback.Succeeded() {
+			logger.Info("シーンのロールバックが完了", "nic", result.Target.NICName)
+		} else {
+			logger.Error("シーンのロールバックに失敗", result.Rollback.Err, "nic", result.Target.NICName)
+		}
+	}
+}
+
+// PlanScene はシーンを適用した場合の各NICのコマンドと差分を返します
+func (c *Client) PlanScene(targets []models.SceneTarget) ([]*Plan, error) {
+	plans := make([]*Plan, 0, len(targets))
+	for _, target := range targets {
+		var (
+			plan *Plan
+			err  error
+		)
+		if target.Profile != nil {
+			plan, err = c.PlanProfile(target.Profile)
+		} else {
+			plan, err = c.PlanDHCP(target.NICName)
+		}
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
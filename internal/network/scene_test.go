@@ -0,0 +1,68 @@
+package network
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+func TestApplySceneRollback(t *testing.T) {
+	const (
+		cmdWiFiDHCPAddress = "netsh interface ipv4 set address name=Wi-Fi source=dhcp"
+		cmdWiFiDHCPDNS     = "netsh interface ipv4 set dns name=Wi-Fi source=dhcp"
+		cmdLocalSetAddress = "netsh interface ipv4 set address name=Local static 10.0.2.10 255.255.255.0"
+	)
+	applyErr := errors.New("exit status 1")
+	runner := newTestRunner(dhcpConfigOutput, staticConfigOutput).
+		On("netsh interface ipv4 show config name=Wi-Fi", dhcpConfigOutput).
+		On("netsh interface ipv4 show config name=Local", dhcpConfigOutput).
+		OnError(cmdLocalSetAddress, "構成に失敗しました。", applyErr).
+		On(cmdWiFiDHCPAddress, "").
+		On(cmdWiFiDHCPDNS, "")
+	client := newTestClient(runner)
+
+	// 3つ目のNICで失敗する
+	targets := []models.SceneTarget{
+		{NICName: "Ethernet", Profile: testProfile()},
+		{NICName: "Wi-Fi"},
+		{NICName: "Local", Profile: &models.Profile{Name: "検証", NICName: "Local", IPAddress: "10.0.2.10", SubnetMask: "255.255.255.0"}},
+	}
+	results, err := client.ApplyScene(targets)
+
+	var sceneErr *SceneError
+	if !errors.As(err, &sceneErr) || sceneErr.Failed != 2 || !errors.Is(err, applyErr) {
+		t.Fatalf("ApplyScene() error = %v, want SceneError at step 2", err)
+	}
+	if failed := sceneErr.FailedRollbacks(); len(failed) != 0 {
+		t.Errorf("FailedRollbacks() = %q, want none", failed)
+	}
+	for i, result := range results[:2] {
+		if !result.Applied || result.Rollback == nil || !result.Rollback.Succeeded() {
+			t.Errorf("results[%d] = %+v, want applied and rolled back", i, result)
+		}
+	}
+	if results[2].Applied || results[2].Err == nil {
+		t.Errorf("results[2] = %+v, want failed", results[2])
+	}
+
+	// 適用した順序と逆の順序で変更前の設定（DHCP）に戻す
+	want := []string{
+		cmdSetAddress, cmdSetDNS, cmdAddDNS,
+		cmdWiFiDHCPAddress, cmdWiFiDHCPDNS,
+		cmdLocalSetAddress,
+		cmdWiFiDHCPAddress, cmdWiFiDHCPDNS,
+		cmdDHCPAddress, cmdDHCPDNS,
+	}
+	var got []string
+	for _, cmd := range changes(runner) {
+		if strings.Contains(cmd, " set ") || strings.Contains(cmd, " add ") {
+			got = append(got, cmd)
+		}
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("commands =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package systray
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/autoswitch"
+	"github.com/fast-ip-change/fast-ip-change/internal/config"
+	"github.com/fast-ip-change/fast-ip-change/internal/history"
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/internal/network"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+	"github.com/getlantern/systray"
+)
+
+var (
+	autoSwitchWatcher  *autoswitch.Watcher
+	autoSwitchMenuItem *systray.MenuItem // 「ネットワークに応じて自動で切り替える」メニュー項目
+)
+
+// startAutoSwitch は自動切り替えの監視を開始します
+// 有効/無効は確認のたびに設定を読み取るため、設定を変更しても再起動は不要です
+func startAutoSwitch() {
+	autoSwitchWatcher = autoswitch.NewWatcher(network.DefaultClient(), currentConfig, applyAutoSwitch)
+	go autoSwitchWatcher.Run(make(chan struct{}))
+}
+
+// currentConfig は現在の設定を返します
+func currentConfig() *models.Config {
+	appConfigMu.RLock()
+	defer appConfigMu.RUnlock()
+	cfg := *appConfig // コピーを作成
+	return &cfg
+}
+
+// applyAutoSwitch は条件に一致したプロファイルを適用し、「元に戻す」ボタン付きで通知します
+func applyAutoSwitch(match autoswitch.Match) error {
+	profile := match.Profile
+	if err := profile.Validate(); err != nil {
+		logger.Error("プロファイルの検証に失敗", err, "profile", profile.Name)
+		showNotification("エラー", fmt.Sprintf("自動切り替えのプロファイル %s の設定が不正です: %v", profile.Name, err), false)
+		return err
+	}
+
+	defer updateUndoMenu()
+	report, err := history.ApplyProfile(profile)
+	if err != nil {
+		showNotification("エラー", fmt.Sprintf("プロファイル %s を自動で適用できませんでした: %v", profile.Name, err), false)
+		return err
+	}
+
+	logger.Info("プロファイルを自動で適用しました", "profile", profile.Name, "reason", match.Reason)
+	message := fmt.Sprintf("ネットワーク（%s）を検出したため、%s（%s）を適用しました。", match.Reason, profile.Name, profile.IPAddress)
+	actions := undoActions()
+	if len(actions) == 0 {
+		message += "\n元に戻すにはメニューの「直前の設定に戻す」を選択してください。"
+	}
+	showAppliedNotification("自動切り替え", message, report, actions...)
+	return nil
+}
+
+// notifyManualChange は手動で切り替えたことを自動切り替えに通知します
+// 手動の切り替えを優先し、ネットワーク環境が変わるまで自動で切り替えないようにします
+func notifyManualChange() {
+	if autoSwitchWatcher != nil {
+		autoSwitchWatcher.NotifyManualChange(time.Now())
+	}
+}
+
+// toggleAutoSwitch は自動切り替えの有効/無効を切り替えて設定ファイルに保存します
+func toggleAutoSwitch() {
+	enabled := !autoSwitchMenuItem.Checked()
+	cfg, err := config.UpdateConfig(func(cfg *models.Config) error {
+		cfg.Settings.AutoSwitch = enabled
+		return nil
+	})
+	if err != nil {
+		logger.Error("自動切り替えの設定の保存に失敗", err)
+		showNotification("エラー", fmt.Sprintf("設定を保存できませんでした: %v", err), false)
+		return
+	}
+
+	appConfigMu.Lock()
+	appConfig = cfg
+	appConfigMu.Unlock()
+	updateAutoSwitchMenu()
+	logger.Info("自動切り替えの設定を変更しました", "enabled", enabled)
+}
+
+// updateAutoSwitchMenu は設定に応じて自動切り替えのメニュー項目のチェックを更新します
+func updateAutoSwitchMenu() {
+	appConfigMu.RLock()
+	enabled := appConfig.Settings.AutoSwitch
+	appConfigMu.RUnlock()
+
+	if enabled {
+		autoSwitchMenuItem.Check()
+	} else {
+		autoSwitchMenuItem.Uncheck()
+	}
+}
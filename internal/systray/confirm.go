@@ -0,0 +1,64 @@
+package systray
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/internal/network"
+	"golang.org/x/sys/windows"
+)
+
+// idOK は MessageBox で「OK」が選択されたときの戻り値です
+const idOK = 1
+
+// confirmApply は適用前の変更内容をダイアログで表示し、続行するかどうかを返します
+// 確認設定が無効の場合は変更内容を取得せずに true を返します
+func confirmApply(makePlan func() (*network.Plan, error)) bool {
+	return confirmApplyPlans(func() ([]*network.Plan, error) {
+		plan, err := makePlan()
+		if err != nil {
+			return nil, err
+		}
+		return []*network.Plan{plan}, nil
+	})
+}
+
+// confirmApplyPlans は複数のNICの変更内容をまとめて表示し、続行するかどうかを返します（シーン用）
+func confirmApplyPlans(makePlans func() ([]*network.Plan, error)) bool {
+	appConfigMu.RLock()
+	confirm := appConfig.Settings.ConfirmBeforeApply
+	appConfigMu.RUnlock()
+
+	if !confirm {
+		return true
+	}
+
+	plans, err := makePlans()
+	if err != nil {
+		logger.Error("適用内容の取得に失敗", err)
+		showNotification("エラー", fmt.Sprintf("適用内容を確認できませんでした: %v", err), false)
+		return false
+	}
+
+	var message strings.Builder
+	for _, plan := range plans {
+		message.WriteString(plan.String())
+	}
+	message.WriteString("\n適用しますか？")
+
+	text, err := windows.UTF16PtrFromString(message.String())
+	if err != nil {
+		logger.Error("確認ダイアログの作成に失敗", err)
+		return false
+	}
+	caption, _ := windows.UTF16PtrFromString("Fast IP Change - 適用内容の確認")
+
+	ret, err := windows.MessageBox(0, text, caption,
+		windows.MB_OKCANCEL|windows.MB_ICONQUESTION|windows.MB_TOPMOST|windows.MB_SETFOREGROUND)
+	if ret == 0 {
+		logger.Error("確認ダイアログの表示に失敗", err)
+		return false
+	}
+	return ret == idOK
+}
@@ -0,0 +1,74 @@
+package systray
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/history"
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/internal/network"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// applyScene はシーンを検証し、確認（設定で有効な場合）の後に複数のNICへまとめて適用します
+// 結果は成功・失敗にかかわらず1つの通知にまとめて表示します
+func applyScene(sceneID string) {
+	appConfigMu.RLock()
+	var (
+		scene   *models.Scene
+		targets []models.SceneTarget
+		err     error
+	)
+	if s := appConfig.FindScene(sceneID); s != nil {
+		copied := *s // コピーを作成
+		scene = &copied
+		// ResolveScene はプロファイルのコピーを返すため、ロックの解放後も使用できる
+		targets, err = appConfig.ResolveScene(scene)
+	}
+	appConfigMu.RUnlock()
+
+	if scene == nil {
+		showNotification("エラー", "シーンが見つかりませんでした", false)
+		return
+	}
+	if err != nil {
+		logger.Error("シーンの検証に失敗", err, "scene", scene.Name)
+		showNotification("エラー", fmt.Sprintf("シーン設定が不正です: %v", err), false)
+		return
+	}
+
+	// 適用前の確認（設定で有効な場合）
+	if !confirmApplyPlans(func() ([]*network.Plan, error) { return network.PlanScene(targets) }) {
+		logger.Info("シーンの適用をキャンセルしました", "scene", scene.Name)
+		return
+	}
+
+	defer notifyManualChange()
+	defer updateUndoMenu()
+	results, err := history.ApplyScene(scene, targets)
+	if err != nil {
+		logger.Error("シーンの適用に失敗", err, "scene", scene.Name)
+		showNotification("エラー", fmt.Sprintf("シーン「%s」を適用できませんでした: %v", scene.Name, err), false)
+		return
+	}
+
+	descriptions := make([]string, len(targets))
+	for i, target := range targets {
+		descriptions[i] = target.Description()
+	}
+	message := fmt.Sprintf("シーン「%s」を適用しました（%s）", scene.Name, strings.Join(descriptions, ", "))
+	logger.Info("シーンを適用しました", "scene", scene.Name)
+
+	// 疎通確認に失敗したNICがある場合は警告として通知する
+	var failed []string
+	for _, result := range results {
+		if result.Health != nil && !result.Health.Passed() {
+			failed = append(failed, fmt.Sprintf("%s: %s", result.Target.NICName, result.Health))
+		}
+	}
+	if len(failed) > 0 {
+		showNotification("警告", message+"\n"+strings.Join(failed, "\n"), false)
+		return
+	}
+	showNotification("成功", message, true)
+}
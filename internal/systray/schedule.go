@@ -0,0 +1,144 @@
+package systray
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/config"
+	"github.com/fast-ip-change/fast-ip-change/internal/history"
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/internal/network"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+	"github.com/getlantern/systray"
+)
+
+// scheduleCheckInterval はスケジュールを確認する間隔です
+const scheduleCheckInterval = 30 * time.Second
+
+var (
+	scheduleRuns   map[string]time.Time // スケジュールIDごとに最後に実行した予定時刻（state.json と同じ内容）
+	scheduleRunsMu sync.Mutex
+)
+
+// startScheduler はスケジュールの確認を開始します
+// 最後に実行した予定時刻は state.json に記録し、再起動後に同じ切り替えを繰り返さないようにします
+func startScheduler() {
+	scheduleRuns = make(map[string]time.Time)
+	if state, err := config.LoadState(); err != nil {
+		logger.Warn("状態ファイルの読み込みに失敗", "error", err)
+	} else {
+		for id, at := range state.ScheduleRuns {
+			scheduleRuns[id] = at
+		}
+	}
+
+	go func() {
+		runDueSchedules(time.Now())
+		ticker := time.NewTicker(scheduleCheckInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			runDueSchedules(now)
+		}
+	}()
+}
+
+// runDueSchedules は予定時刻を過ぎた未実行のスケジュールを実行し、次の切り替えをツールチップに表示します
+func runDueSchedules(now time.Time) {
+	cfg := currentConfig()
+	for i := range cfg.Schedules {
+		s := cfg.Schedules[i] // コピーを作成
+		if s.Disabled {
+			continue
+		}
+		scheduleRunsMu.Lock()
+		last := scheduleRuns[s.ID]
+		scheduleRunsMu.Unlock()
+
+		due, action, err := s.Due(last, now)
+		if err != nil || action == models.ScheduleIdle {
+			// 設定が不正な場合は、設定の読み込み時に検証エラーとしてログに記録済み
+			continue
+		}
+
+		// 失敗しても同じ予定時刻の切り替えを繰り返さないよう、先に記録する
+		setScheduleRun(s.ID, due)
+		switch action {
+		case models.ScheduleMissed:
+			logger.Warn("予定時刻を過ぎているため、スケジュールの切り替えを実行しません", "schedule", s.ID, "due", due.Format(time.DateTime))
+		case models.ScheduleInitial:
+			logger.Info("新しいスケジュールのため、次の予定時刻から切り替えます", "schedule", s.ID, "due", due.Format(time.DateTime))
+		case models.ScheduleRun:
+			runSchedule(cfg, &s)
+		}
+	}
+	updateScheduleTooltip(cfg, now)
+}
+
+// setScheduleRun はスケジュールを実行した予定時刻を記録します
+func setScheduleRun(id string, due time.Time) {
+	scheduleRunsMu.Lock()
+	scheduleRuns[id] = due
+	scheduleRunsMu.Unlock()
+
+	if err := config.SetScheduleRun(id, due); err != nil {
+		logger.Warn("スケジュールの実行時刻の保存に失敗", "schedule", id, "error", err)
+	}
+}
+
+// runSchedule はスケジュールのプロファイルを適用（またはNICをDHCPに切り替え）し、結果を通知します
+func runSchedule(cfg *models.Config, s *models.Schedule) {
+	target := cfg.TargetString(s)
+	logger.Info("スケジュールの切り替えを実行します", "schedule", s.ID, "when", s.WhenString(), "target", target)
+
+	defer notifyManualChange()
+	defer updateUndoMenu()
+
+	var (
+		report *network.HealthReport
+		err    error
+	)
+	if s.DHCPNIC != "" {
+		err = history.ApplyDHCP(s.DHCPNIC)
+	} else if profile := cfg.FindProfile(s.ProfileID); profile == nil {
+		err = fmt.Errorf("プロファイルが見つかりません: %s", s.ProfileID)
+	} else {
+		p := *profile // コピーを作成
+		if err = p.Validate(); err == nil {
+			report, err = history.ApplyProfile(&p)
+		}
+	}
+
+	if err != nil {
+		logger.Error("スケジュールの切り替えに失敗", err, "schedule", s.ID)
+		showNotification("エラー", fmt.Sprintf("スケジュール（%s）で %s に切り替えられませんでした: %v", s.WhenString(), target, err), false)
+		return
+	}
+	logger.Info("スケジュールの切り替えが完了", "schedule", s.ID)
+	showAppliedNotification("スケジュール", fmt.Sprintf("スケジュール（%s）により %s に切り替えました", s.WhenString(), target), report)
+}
+
+// updateScheduleTooltip は次に予定されている切り替えをトレイアイコンのツールチップに表示します
+func updateScheduleTooltip(cfg *models.Config, now time.Time) {
+	var (
+		next   time.Time
+		target *models.Schedule
+	)
+	for i := range cfg.Schedules {
+		s := &cfg.Schedules[i]
+		if s.Disabled {
+			continue
+		}
+		at, err := s.Next(now)
+		if err == nil && (target == nil || at.Before(next)) {
+			next, target = at, s
+		}
+	}
+
+	if target == nil {
+		systray.SetTooltip(trayTooltip)
+		return
+	}
+	systray.SetTooltip(fmt.Sprintf("%s\n次の切り替え: %s(%s) %s %s",
+		trayTooltip, next.Format("01/02"), models.FormatWeekday(next.Weekday()), next.Format("15:04"), cfg.TargetString(target)))
+}
@@ -5,11 +5,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/fast-ip-change/fast-ip-change/assets"
 	"github.com/fast-ip-change/fast-ip-change/internal/config"
+	"github.com/fast-ip-change/fast-ip-change/internal/history"
 	"github.com/fast-ip-change/fast-ip-change/internal/logger"
 	"github.com/fast-ip-change/fast-ip-change/internal/network"
 	"github.com/fast-ip-change/fast-ip-change/pkg/models"
@@ -19,6 +22,9 @@ import (
 
 // Note: internal/ui パッケージは settings.exe で使用されるため、このファイルでは使用しない
 
+// trayTooltip はトレイアイコンのツールチップです（次のスケジュールがある場合は続けて表示）
+const trayTooltip = "Fast IP Change - IPアドレスを簡単に切り替え"
+
 // Windows プロセス作成フラグ
 const (
 	createNoWindow = 0x08000000 // CREATE_NO_WINDOW: コンソールウィンドウを表示しない
@@ -31,6 +37,7 @@ var (
 	profileStopChs map[string]chan struct{}      // goroutine停止用のチャネル
 	profileStopMu  sync.Mutex                    // profileStopChs の排他制御用
 	dhcpMenuItems  map[string]*systray.MenuItem  // DHCPメニュー項目（NIC名 -> メニュー項目）
+	undoMenuItem   *systray.MenuItem             // 「直前の設定に戻す」メニュー項目
 )
 
 // Run はシステムトレイアプリケーションを起動します
@@ -44,6 +51,7 @@ func Run() error {
 	appConfigMu.Lock()
 	appConfig = cfg
 	appConfigMu.Unlock()
+	configureNetwork(cfg)
 
 	// システムトレイを起動
 	systray.Run(onReady, onExit)
@@ -59,7 +67,7 @@ func onReady() {
 		logger.Info("アイコンを設定します", "size", len(iconData))
 		// Windowsでは、アイコンを設定する前にタイトルを設定する必要がある場合がある
 		systray.SetTitle("Fast IP Change")
-		systray.SetTooltip("Fast IP Change - IPアドレスを簡単に切り替え")
+		systray.SetTooltip(trayTooltip)
 		// アイコンを設定
 		systray.SetIcon(iconData)
 		logger.Info("アイコンを正常に設定しました")
@@ -83,6 +91,17 @@ func onReady() {
 	mDHCP := systray.AddMenuItem("DHCP（自動取得）", "DHCPに切り替え")
 	setupDHCPSubMenu(mDHCP)
 
+	// 直前の設定に戻す
+	undoMenuItem = systray.AddMenuItem("直前の設定に戻す", "最後の切り替え前の設定に戻す")
+	updateUndoMenu()
+
+	// 自動切り替え（プロファイルの条件に一致したネットワークを検出したら適用）
+	autoSwitchMenuItem = systray.AddMenuItemCheckbox("ネットワークに応じて自動で切り替える", "条件を設定したプロファイルを自動で適用", false)
+	updateAutoSwitchMenu()
+	startUndoAction()
+	startAutoSwitch()
+	startScheduler()
+
 	systray.AddSeparator()
 
 	// 設定メニュー
@@ -101,6 +120,10 @@ func onReady() {
 				showNICStatus()
 			case <-mRouteTable.ClickedCh:
 				showRouteTable()
+			case <-undoMenuItem.ClickedCh:
+				undoLastChange()
+			case <-autoSwitchMenuItem.ClickedCh:
+				toggleAutoSwitch()
 			case <-mSettings.ClickedCh:
 				openSettings()
 			case <-mLogs.ClickedCh:
@@ -128,19 +151,31 @@ func setupDHCPSubMenu(parent *systray.MenuItem) {
 	appConfigMu.RUnlock()
 
 	for _, nic := range nics {
-		if !settings.IsNICEnabledForDHCP(nic) {
+		if !settings.IsNICEnabledForDHCP(nic.Name) {
 			continue
 		}
 
-		subItem := parent.AddSubMenuItem(nic, fmt.Sprintf("%s をDHCPに切り替え", nic))
-		dhcpMenuItems[nic] = subItem
+		title := nic.Name
+		if !nic.Up() {
+			title = fmt.Sprintf("%s（%s）", nic.Name, nic.StateString())
+		}
+		tooltip := fmt.Sprintf("%s をDHCPに切り替え", nic.Name)
+		if nic.Description != "" {
+			tooltip += "\n" + nic.Description
+		}
+		subItem := parent.AddSubMenuItem(title, tooltip)
+		// 無効なNICには設定を適用できないため選択不可にする
+		if !nic.Enabled() {
+			subItem.Disable()
+		}
+		dhcpMenuItems[nic.Name] = subItem
 
 		// クリックイベントを監視
 		go func(nicName string, item *systray.MenuItem) {
 			for range item.ClickedCh {
 				applyDHCPToNIC(nicName)
 			}
-		}(nic, subItem)
+		}(nic.Name, subItem)
 	}
 }
 
@@ -175,37 +210,101 @@ func updateProfileMenu() {
 	// 設定を読み取り
 	appConfigMu.RLock()
 	profiles := appConfig.Profiles
+	templates := appConfig.Templates
+	scenes := appConfig.Scenes
 	appConfigMu.RUnlock()
 
 	// プロファイルが存在しない場合
-	if len(profiles) == 0 {
+	if len(profiles) == 0 && len(templates) == 0 && len(scenes) == 0 {
 		mNoProfile := systray.AddMenuItem("プロファイルがありません", "")
 		mNoProfile.Disable()
 		return
 	}
 
-	// プロファイルメニューを追加
+	// ピン留めしたプロファイルはグループに関係なく先頭に表示（グループ内にも表示する）
+	for _, profile := range profiles {
+		if profile.Pinned {
+			id := profile.ID
+			addProfileMenuItem("pinned:"+id, nil, profileMenuTitle(&profile), fmt.Sprintf("IP: %s", profile.IPAddress), func() { applyProfile(id) })
+		}
+	}
+
+	// プロファイルメニューを追加（グループはサブメニュー。設定ファイルの順序で表示）
+	groups := make(map[string]*systray.MenuItem)
 	for _, profile := range profiles {
-		menuTitle := fmt.Sprintf("%s [%s]", profile.Name, profile.NICName)
-		menuItem := systray.AddMenuItem(menuTitle, fmt.Sprintf("IP: %s", profile.IPAddress))
-		menuItems[profile.ID] = menuItem
-
-		// 停止用チャネルを作成
-		stopCh := make(chan struct{})
-		profileStopChs[profile.ID] = stopCh
-
-		// 各メニューアイテムのクリックイベントを監視（停止可能なgoroutine）
-		go func(id string, item *systray.MenuItem, stop chan struct{}) {
-			for {
-				select {
-				case <-stop:
-					return
-				case <-item.ClickedCh:
-					applyProfile(id)
-				}
+		id := profile.ID
+		parent := groupMenuItem(groups, profile.GroupPath())
+		addProfileMenuItem(id, parent, profileMenuTitle(&profile), fmt.Sprintf("IP: %s", profile.IPAddress), func() { applyProfile(id) })
+	}
+
+	// テンプレートのメニューを追加（クリック時に変数の値を入力）
+	for _, template := range templates {
+		id := template.ID
+		parent := groupMenuItem(groups, template.Profile.GroupPath())
+		addProfileMenuItem(id, parent, template.Name+"...", "変数の値を入力して適用", func() { applyTemplate(id) })
+	}
+
+	// シーンのメニューを追加（複数のNICをまとめて切り替え）
+	for _, scene := range scenes {
+		id := scene.ID
+		parent := groupMenuItem(groups, scene.GroupPath())
+		title := fmt.Sprintf("%s [%d NIC]", scene.Name, len(scene.Steps))
+		addProfileMenuItem("scene:"+id, parent, title, "シーン: 複数のNICをまとめて切り替え", func() { applyScene(id) })
+	}
+}
+
+// profileMenuTitle はプロファイルのメニューの表示名です
+func profileMenuTitle(profile *models.Profile) string {
+	return fmt.Sprintf("%s [%s]", profile.Name, profile.NICName)
+}
+
+// groupMenuItem はグループのサブメニューを返します（ない場合は親のグループから順に作成）
+// グループがない場合は nil（ルートのメニュー）を返します。profileStopMu を取得した状態で呼び出してください
+func groupMenuItem(groups map[string]*systray.MenuItem, path []string) *systray.MenuItem {
+	var parent *systray.MenuItem
+	for i, name := range path {
+		key := "group:" + strings.Join(path[:i+1], models.GroupSeparator)
+		item, ok := groups[key]
+		if !ok {
+			if parent == nil {
+				item = systray.AddMenuItem(name, "")
+			} else {
+				item = parent.AddSubMenuItem(name, "")
 			}
-		}(profile.ID, menuItem, stopCh)
+			groups[key] = item
+			menuItems[key] = item
+		}
+		parent = item
+	}
+	return parent
+}
+
+// addProfileMenuItem はメニュー項目を追加し、クリックされたときに onClick を呼び出します
+// parent が nil の場合はルートのメニューに追加します。profileStopMu を取得した状態で呼び出してください
+func addProfileMenuItem(key string, parent *systray.MenuItem, title, tooltip string, onClick func()) {
+	var menuItem *systray.MenuItem
+	if parent == nil {
+		menuItem = systray.AddMenuItem(title, tooltip)
+	} else {
+		menuItem = parent.AddSubMenuItem(title, tooltip)
 	}
+	menuItems[key] = menuItem
+
+	// 停止用チャネルを作成
+	stopCh := make(chan struct{})
+	profileStopChs[key] = stopCh
+
+	// クリックイベントを監視（停止可能なgoroutine）
+	go func() {
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-menuItem.ClickedCh:
+				onClick()
+			}
+		}
+	}()
 }
 
 func applyProfile(profileID string) {
@@ -226,6 +325,11 @@ func applyProfile(profileID string) {
 		return
 	}
 
+	applyProfileValue(profile)
+}
+
+// applyProfileValue はプロファイルを検証し、確認（設定で有効な場合）の後に適用します
+func applyProfileValue(profile *models.Profile) {
 	// プロファイルの検証（設定ファイル改ざん対策）
 	if err := profile.Validate(); err != nil {
 		logger.Error("プロファイルの検証に失敗", err, "profile", profile.Name)
@@ -233,18 +337,34 @@ func applyProfile(profileID string) {
 		return
 	}
 
-	// プロファイルを適用
-	if err := network.ApplyProfile(profile); err != nil {
+	// 適用前の確認（設定で有効な場合）
+	if !confirmApply(func() (*network.Plan, error) { return network.PlanProfile(profile) }) {
+		logger.Info("プロファイルの適用をキャンセルしました", "profile", profile.Name)
+		return
+	}
+
+	// プロファイルを適用（履歴に記録）
+	defer notifyManualChange()
+	defer updateUndoMenu()
+	if report, err := history.ApplyProfile(profile); err != nil {
 		logger.Error("プロファイルの適用に失敗", err, "profile", profile.Name)
 		showNotification("エラー", fmt.Sprintf("IPアドレス設定の適用に失敗しました: %v", err), false)
 	} else {
 		logger.Info("プロファイルを適用しました", "profile", profile.Name)
-		showNotification("成功", fmt.Sprintf("IPアドレスを %s に変更しました", profile.IPAddress), true)
+		showAppliedNotification("成功", fmt.Sprintf("IPアドレスを %s に変更しました", profile.IPAddress), report)
 	}
 }
 
 func applyDHCPToNIC(nicName string) {
-	if err := network.ApplyDHCP(nicName); err != nil {
+	// 適用前の確認（設定で有効な場合）
+	if !confirmApply(func() (*network.Plan, error) { return network.PlanDHCP(nicName) }) {
+		logger.Info("DHCPへの切り替えをキャンセルしました", "nic", nicName)
+		return
+	}
+
+	defer notifyManualChange()
+	defer updateUndoMenu()
+	if err := history.ApplyDHCP(nicName); err != nil {
 		logger.Error("DHCP設定の適用に失敗", err)
 		showNotification("エラー", fmt.Sprintf("DHCP設定の適用に失敗しました: %v", err), false)
 	} else {
@@ -253,6 +373,43 @@ func applyDHCPToNIC(nicName string) {
 	}
 }
 
+// updateUndoMenu は履歴に応じて「直前の設定に戻す」の有効/無効と説明を更新します
+func updateUndoMenu() {
+	entries, err := history.Load()
+	if err != nil {
+		logger.Warn("履歴の読み込みに失敗", "error", err)
+	}
+
+	target := history.LastUndoable(entries)
+	if target == nil {
+		undoMenuItem.SetTooltip("戻せる履歴がありません")
+		undoMenuItem.Disable()
+		return
+	}
+	undoMenuItem.SetTooltip(fmt.Sprintf("「%s」（%s）の前の設定に戻す", target.Description(), target.Time.Format("01/02 15:04")))
+	undoMenuItem.Enable()
+}
+
+// undoLastChange は最後の切り替え前の設定に戻します
+func undoLastChange() {
+	defer notifyManualChange()
+	defer updateUndoMenu()
+
+	target, err := history.Undo()
+	if err != nil {
+		logger.Error("直前の設定への復元に失敗", err)
+		showNotification("エラー", fmt.Sprintf("直前の設定に戻せませんでした: %v", err), false)
+		return
+	}
+
+	logger.Info("直前の設定に戻しました", "nic", target.NICName, "history", target.ID)
+	if target.InBatch() {
+		showNotification("成功", fmt.Sprintf("シーン「%s」を適用する前の設定に戻しました", target.SceneName), true)
+		return
+	}
+	showNotification("成功", fmt.Sprintf("%s を「%s」の前の設定に戻しました", target.NICName, target.Description()), true)
+}
+
 func showNICStatus() {
 	logger.Info("NIC状態画面を起動します")
 
@@ -378,9 +535,12 @@ func openSettings() {
 		appConfigMu.Lock()
 		appConfig = cfg
 		appConfigMu.Unlock()
+		configureNetwork(cfg)
 
 		updateProfileMenu()
 		updateDHCPMenu()
+		updateAutoSwitchMenu()
+		updateScheduleTooltip(cfg, time.Now())
 	}()
 }
 
@@ -423,7 +583,8 @@ func showLogs() {
 	}()
 }
 
-func showNotification(title, message string, success bool) {
+// showNotification は通知を表示します（actions は通知に表示するボタン）
+func showNotification(title, message string, success bool, actions ...toast.Action) {
 	appConfigMu.RLock()
 	enableNotifications := appConfig.Settings.EnableNotifications
 	appConfigMu.RUnlock()
@@ -436,6 +597,7 @@ func showNotification(title, message string, success bool) {
 		AppID:   "Fast IP Change",
 		Title:   title,
 		Message: message,
+		Actions: actions,
 	}
 
 	if err := notification.Push(); err != nil {
@@ -443,6 +605,28 @@ func showNotification(title, message string, success bool) {
 	}
 }
 
+// configureNetwork は設定に応じてIPアドレスの重複を検出した場合の動作を設定し、適用を続ける警告を通知します
+func configureNetwork(cfg *models.Config) {
+	client := network.DefaultClient()
+	client.SetAddressConflictPolicy(cfg.Settings.AddressConflictPolicy())
+	client.SetWarningHandler(func(message string) {
+		showNotification("警告", message, false)
+	})
+}
+
+// showAppliedNotification は適用の成功を疎通確認の結果とともに通知します
+// 疎通確認に失敗した場合（変更前の設定に戻さないプロファイル）は警告として通知します
+func showAppliedNotification(title, message string, report *network.HealthReport, actions ...toast.Action) {
+	switch {
+	case report == nil:
+		showNotification(title, message, true, actions...)
+	case !report.Passed():
+		showNotification("警告", message+"\n"+report.String(), false, actions...)
+	default:
+		showNotification(title, message+"\n"+report.String(), true, actions...)
+	}
+}
+
 func getIcon() []byte {
 	// 埋め込まれたアイコンデータを返す
 	return assets.IconData
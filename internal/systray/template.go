@@ -0,0 +1,80 @@
+package systray
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/fast-ip-change/fast-ip-change/pkg/models"
+)
+
+// applyTemplate は変数の値を入力してテンプレートからプロファイルを作成し、適用します
+func applyTemplate(templateID string) {
+	appConfigMu.RLock()
+	var template *models.ProfileTemplate
+	if t := appConfig.FindTemplate(templateID); t != nil {
+		copied := *t // コピーを作成
+		template = &copied
+	}
+	appConfigMu.RUnlock()
+
+	if template == nil {
+		showNotification("エラー", "テンプレートが見つかりませんでした", false)
+		return
+	}
+
+	values, ok := promptTemplateValues(template)
+	if !ok {
+		logger.Info("テンプレートの適用をキャンセルしました", "template", template.Name)
+		return
+	}
+
+	profile, err := template.Expand(values)
+	if err != nil {
+		logger.Error("テンプレートからプロファイルを作成できません", err, "template", template.Name)
+		showNotification("エラー", fmt.Sprintf("テンプレートからプロファイルを作成できません: %v", err), false)
+		return
+	}
+	applyProfileValue(profile)
+}
+
+// promptTemplateValues は設定アプリ（settings.exe -template <ID>）で変数の値を入力します
+// キャンセルされた場合や入力できなかった場合は ok が false になります
+func promptTemplateValues(template *models.ProfileTemplate) (values map[string]string, ok bool) {
+	exePath, err := os.Executable()
+	if err != nil {
+		logger.Error("実行ファイルのパス取得に失敗", err)
+		showNotification("エラー", "変数の入力画面を起動できませんでした", false)
+		return nil, false
+	}
+
+	settingsPath := filepath.Join(filepath.Dir(exePath), "settings.exe")
+	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+		logger.Error("設定アプリが見つかりません", err, "path", settingsPath)
+		showNotification("エラー", "settings.exe が見つかりません", false)
+		return nil, false
+	}
+
+	// 入力ダイアログを表示するため、ウィンドウを非表示にするフラグは指定しない
+	output, err := exec.Command(settingsPath, "-template", template.ID).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// キャンセル
+		return nil, false
+	}
+	if err != nil {
+		logger.Error("変数の入力画面の起動に失敗", err)
+		showNotification("エラー", fmt.Sprintf("変数の入力画面を起動できませんでした: %v", err), false)
+		return nil, false
+	}
+
+	if err := json.Unmarshal(output, &values); err != nil {
+		logger.Error("入力された変数の値を読み取れません", err)
+		return nil, false
+	}
+	return values, true
+}
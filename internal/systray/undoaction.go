@@ -0,0 +1,126 @@
+package systray
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unsafe"
+
+	"github.com/fast-ip-change/fast-ip-change/internal/logger"
+	"github.com/go-toast/toast"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// 通知の「元に戻す」ボタンは URL スキームで実行ファイルを起動し、
+// 起動されたプロセスが名前付きイベントで起動中のシステムトレイに元に戻すよう依頼します
+const (
+	// UndoActionURI は通知の「元に戻す」ボタンで起動される URI です
+	UndoActionURI = "fast-ip-change:undo"
+	// undoProtocolKey は URL スキームを登録するレジストリキー（HKEY_CURRENT_USER）です
+	undoProtocolKey = `Software\Classes\fast-ip-change`
+	// undoEventName は元に戻す依頼を受け付ける名前付きイベントです
+	undoEventName = `Local\FastIPChangeUndo`
+	// undoEventSDDL は管理者権限で実行しているシステムトレイのイベントを、
+	// URL スキームから通常の権限で起動されたプロセスが通知できるようにするアクセス許可です
+	undoEventSDDL = "D:(A;;GA;;;SY)(A;;GA;;;BA)(A;;0x100002;;;IU)"
+)
+
+// undoActionAvailable は通知に「元に戻す」ボタンを表示できるかどうかです
+var undoActionAvailable bool
+
+// IsUndoActionURI は引数が通知の「元に戻す」ボタンで起動された URI かどうかを判定します
+func IsUndoActionURI(arg string) bool {
+	return strings.EqualFold(strings.TrimSuffix(arg, "/"), UndoActionURI)
+}
+
+// RequestUndo は起動中のシステムトレイに、直前の設定に戻すよう依頼します
+func RequestUndo() error {
+	name, err := windows.UTF16PtrFromString(undoEventName)
+	if err != nil {
+		return err
+	}
+	event, err := windows.OpenEvent(windows.EVENT_MODIFY_STATE, false, name)
+	if err != nil {
+		return fmt.Errorf("システムトレイが起動していません: %w", err)
+	}
+	defer windows.CloseHandle(event)
+	return windows.SetEvent(event)
+}
+
+// startUndoAction は URL スキームを登録し、通知の「元に戻す」ボタンからの依頼を待ち受けます
+// 登録できない場合はボタンを表示せず、メニューからのみ元に戻せます
+func startUndoAction() {
+	if err := registerUndoProtocol(); err != nil {
+		logger.Warn("URL スキームの登録に失敗", "error", err)
+		return
+	}
+	event, err := createUndoEvent()
+	if err != nil {
+		logger.Warn("元に戻す依頼のイベントの作成に失敗", "error", err)
+		return
+	}
+	undoActionAvailable = true
+
+	go func() {
+		for {
+			if _, err := windows.WaitForSingleObject(event, windows.INFINITE); err != nil {
+				logger.Error("元に戻す依頼の待ち受けに失敗", err)
+				return
+			}
+			logger.Info("通知から直前の設定に戻します")
+			undoLastChange()
+		}
+	}()
+}
+
+// registerUndoProtocol は UndoActionURI で実行ファイルを起動するよう、現在のユーザーに URL スキームを登録します
+func registerUndoProtocol() error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, undoProtocolKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+	if err := key.SetStringValue("", "URL:Fast IP Change"); err != nil {
+		return err
+	}
+	if err := key.SetStringValue("URL Protocol", ""); err != nil {
+		return err
+	}
+
+	command, _, err := registry.CreateKey(registry.CURRENT_USER, undoProtocolKey+`\shell\open\command`, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer command.Close()
+	return command.SetStringValue("", fmt.Sprintf(`"%s" "%%1"`, exePath))
+}
+
+// createUndoEvent は元に戻す依頼を受け付ける自動リセットのイベントを作成します
+func createUndoEvent() (windows.Handle, error) {
+	sd, err := windows.SecurityDescriptorFromString(undoEventSDDL)
+	if err != nil {
+		return 0, err
+	}
+	sa := &windows.SecurityAttributes{SecurityDescriptor: sd}
+	sa.Length = uint32(unsafe.Sizeof(*sa))
+
+	name, err := windows.UTF16PtrFromString(undoEventName)
+	if err != nil {
+		return 0, err
+	}
+	return windows.CreateEvent(sa, 0, 0, name)
+}
+
+// undoActions は通知に表示する「元に戻す」ボタンを返します（表示できない場合は nil）
+func undoActions() []toast.Action {
+	if !undoActionAvailable {
+		return nil
+	}
+	return []toast.Action{{Type: "protocol", Label: "元に戻す", Arguments: UndoActionURI}}
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrInvalidAutoSwitchRule は自動切り替えの条件が不正な場合のエラーです
+var ErrInvalidAutoSwitchRule = errors.New("自動切り替えの条件が無効です")
+
+const (
+	// DefaultAutoSwitchDebounce は条件に一致してから自動で適用するまでの既定の待ち時間です
+	DefaultAutoSwitchDebounce = 15 * time.Second
+	// DefaultAutoSwitchCooldown は切り替えた後、次に自動で切り替えるまでの既定の間隔です
+	DefaultAutoSwitchCooldown = 5 * time.Minute
+	// maxSSIDLength はSSIDの最大長（バイト）です
+	maxSSIDLength = 32
+)
+
+// AutoSwitchRule はネットワーク環境に応じてプロファイルを自動で適用する条件です
+// 指定した条件をすべて満たした場合に一致します（空の項目は判定しません）
+type AutoSwitchRule struct {
+	// GatewayMAC はプロファイルのNICのデフォルトゲートウェイ（DHCPで割り当てられたもの）のMACアドレスです
+	GatewayMAC string `json:"gatewayMac,omitempty"`
+	// SSID は接続しているWi-Fiのネットワーク名です（いずれかの無線NICが接続していれば一致）
+	SSID string `json:"ssid,omitempty"`
+	// DNSSuffix はプロファイルのNICの接続固有のDNSサフィックス（DHCPで割り当てられたもの）です
+	DNSSuffix string `json:"dnsSuffix,omitempty"`
+	// ProbeHost は ping に応答があることを確認するホスト（IPアドレスまたはホスト名）です
+	ProbeHost string `json:"probeHost,omitempty"`
+	// LinkUpNIC はリンクアップしていることを確認するNICの名前です
+	LinkUpNIC string `json:"linkUpNic,omitempty"`
+}
+
+// IsEmpty は条件が1つも指定されていないかどうかを返します
+func (r *AutoSwitchRule) IsEmpty() bool {
+	return r.GatewayMAC == "" && r.SSID == "" && r.DNSSuffix == "" && r.ProbeHost == "" && r.LinkUpNIC == ""
+}
+
+// String は条件を表示用の文字列で返します（例: "SSID=office, ゲートウェイ=00-11-22-33-44-55"）
+func (r *AutoSwitchRule) String() string {
+	var conditions []string
+	add := func(label, value string) {
+		if value != "" {
+			conditions = append(conditions, label+"="+value)
+		}
+	}
+	add("ゲートウェイ", r.GatewayMAC)
+	add("SSID", r.SSID)
+	add("DNSサフィックス", r.DNSSuffix)
+	add("応答", r.ProbeHost)
+	add("リンク", r.LinkUpNIC)
+	return strings.Join(conditions, ", ")
+}
+
+// normalizeAutoSwitch は自動切り替えの条件を正規化し、条件がない場合は nil にします
+func (p *Profile) normalizeAutoSwitch() {
+	if p.AutoSwitch == nil {
+		return
+	}
+	p.AutoSwitch.normalize()
+	if p.AutoSwitch.IsEmpty() {
+		p.AutoSwitch = nil
+	}
+}
+
+// normalize は前後の空白を取り除き、MACアドレスを Windows の表記に変換します
+func (r *AutoSwitchRule) normalize() {
+	r.GatewayMAC = strings.TrimSpace(r.GatewayMAC)
+	if mac, err := NormalizeMAC(r.GatewayMAC); err == nil {
+		r.GatewayMAC = mac
+	}
+	r.SSID = strings.TrimSpace(r.SSID)
+	r.DNSSuffix = strings.TrimSpace(r.DNSSuffix)
+	r.ProbeHost = strings.TrimSpace(r.ProbeHost)
+	r.LinkUpNIC = strings.TrimSpace(r.LinkUpNIC)
+}
+
+// validate は条件を検証します（Path は "autoSwitch.gatewayMac" のようなプロファイル内の項目名）
+func (r *AutoSwitchRule) validate(issues *issueList) {
+	if r.IsEmpty() {
+		issues.error("autoSwitch", fmt.Errorf("%w: 条件を1つ以上指定してください", ErrInvalidAutoSwitchRule))
+		return
+	}
+	if r.GatewayMAC != "" {
+		if _, err := NormalizeMAC(r.GatewayMAC); err != nil {
+			issues.error("autoSwitch.gatewayMac", fmt.Errorf("%w: MACアドレス: %s", ErrInvalidAutoSwitchRule, r.GatewayMAC))
+		}
+	}
+	if r.SSID != "" && !isValidSSID(r.SSID) {
+		issues.error("autoSwitch.ssid", fmt.Errorf("%w: SSID: %q", ErrInvalidAutoSwitchRule, r.SSID))
+	}
+	if r.DNSSuffix != "" && !isValidDNSSuffix(r.DNSSuffix) {
+		issues.error("autoSwitch.dnsSuffix", fmt.Errorf("%w: %s", ErrInvalidDNSSuffix, r.DNSSuffix))
+	}
+	if r.ProbeHost != "" && !isValidIPv4(r.ProbeHost) && !isValidIPv6(r.ProbeHost) && !isValidDNSSuffix(r.ProbeHost) {
+		issues.error("autoSwitch.probeHost", fmt.Errorf("%w: ホスト: %s", ErrInvalidAutoSwitchRule, r.ProbeHost))
+	}
+	if r.LinkUpNIC != "" && !IsValidNICName(r.LinkUpNIC) {
+		issues.error("autoSwitch.linkUpNic", fmt.Errorf("%w: %q", ErrInvalidNICName, r.LinkUpNIC))
+	}
+}
+
+// NormalizeMAC はMACアドレスを Windows の表記（"00-11-22-AA-BB-CC"）に変換します
+// "00:11:22:aa:bb:cc" や "0011.22aa.bbcc" の形式も指定できます
+func NormalizeMAC(mac string) (string, error) {
+	hw, err := net.ParseMAC(strings.TrimSpace(mac))
+	if err != nil {
+		return "", err
+	}
+	if len(hw) != 6 {
+		return "", fmt.Errorf("MACアドレスは6バイトで指定してください: %s", mac)
+	}
+	return strings.ToUpper(strings.ReplaceAll(hw.String(), ":", "-")), nil
+}
+
+// isValidSSID はSSIDが32バイト以内で、制御文字と引用符を含まないかどうかを判定します
+func isValidSSID(ssid string) bool {
+	if len(ssid) > maxSSIDLength {
+		return false
+	}
+	for _, c := range ssid {
+		if unicode.IsControl(c) || c == '"' {
+			return false
+		}
+	}
+	return true
+}
+
+// AutoSwitchDebounce は条件に一致してから自動で適用するまでの待ち時間を返します
+func (s *Settings) AutoSwitchDebounce() time.Duration {
+	if s.AutoSwitchDebounceSeconds <= 0 {
+		return DefaultAutoSwitchDebounce
+	}
+	return time.Duration(s.AutoSwitchDebounceSeconds) * time.Second
+}
+
+// AutoSwitchCooldown は切り替えた後、次に自動で切り替えるまでの間隔を返します
+func (s *Settings) AutoSwitchCooldown() time.Duration {
+	if s.AutoSwitchCooldownSeconds <= 0 {
+		return DefaultAutoSwitchCooldown
+	}
+	return time.Duration(s.AutoSwitchCooldownSeconds) * time.Second
+}
@@ -7,4 +7,39 @@ var (
 	ErrInvalidIPAddress   = errors.New("IPアドレスが無効です")
 	ErrInvalidSubnetMask  = errors.New("サブネットマスクが無効です")
 	ErrInvalidNICName     = errors.New("NIC名が無効です")
+	ErrInvalidIPv6Address = errors.New("IPv6アドレスが無効です")
+	ErrInvalidIPv6Prefix  = errors.New("IPv6プレフィックス長が無効です")
+	ErrInvalidGateway     = errors.New("ゲートウェイアドレスが無効です")
+	ErrInvalidDNSServer   = errors.New("DNSサーバーが無効です")
+	ErrInvalidDNSSuffix   = errors.New("DNSサフィックスが無効です")
+	ErrInvalidMetric      = errors.New("インターフェイスメトリックが無効です")
+	ErrInvalidMTU         = errors.New("MTUが無効です")
+	ErrInvalidRoute       = errors.New("ルートが無効です")
+	ErrDuplicateValue     = errors.New("値が重複しています")
+	ErrManagedProfile     = errors.New("集中管理されたプロファイルは変更・削除できません")
+
+	// 項目の組み合わせの誤り
+	ErrGatewayOutsideSubnet = errors.New("ゲートウェイがIPアドレスと同じサブネットにありません")
+	ErrGatewayIsHost        = errors.New("ゲートウェイがIPアドレスと同じです")
+	ErrNetworkAddress       = errors.New("ネットワークアドレスはホストのアドレスに使用できません")
+	ErrBroadcastAddress     = errors.New("ブロードキャストアドレスはホストのアドレスに使用できません")
+
+	// 警告（保存・適用は可能）
+	ErrUnreachableDNSServer = errors.New("DNSサーバーがループバックまたはリンクローカルのアドレスです")
 )
+
+// FieldError は検証エラーと、エラーのある項目を表します
+// Field は項目の位置（JSON の名前。例: gateway、dnsServers[1]、profiles[3].gateway）です
+// 画面の入力欄とエラーを対応付けるために使用します。エラーの種類は errors.Is で判定してください
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
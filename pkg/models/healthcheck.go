@@ -0,0 +1,169 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidHealthCheck は疎通確認の設定が不正な場合のエラーです
+var ErrInvalidHealthCheck = errors.New("疎通確認が無効です")
+
+// 疎通確認の種類
+const (
+	// HealthCheckGateway はゲートウェイが ping（応答がない場合はARP）に応答することを確認します
+	HealthCheckGateway = "gateway"
+	// HealthCheckDNS はホスト名を名前解決できることを確認します
+	HealthCheckDNS = "dns"
+	// HealthCheckTCP はホストのポートにTCPで接続できることを確認します
+	HealthCheckTCP = "tcp"
+)
+
+const (
+	// DefaultHealthCheckTimeout は疎通確認の既定のタイムアウトです
+	DefaultHealthCheckTimeout = 5 * time.Second
+	// MaxHealthCheckTimeoutSeconds は疎通確認のタイムアウトの最大値（秒）です
+	MaxHealthCheckTimeoutSeconds = 60
+)
+
+// HealthCheck はプロファイルの適用後に行う疎通確認です
+// 適用直後はアドレスの重複確認やARPの解決に時間がかかるため、成功するかタイムアウトするまで繰り返します
+type HealthCheck struct {
+	// Type は確認の種類です（"gateway"、"dns"、"tcp"）
+	Type string `json:"type"`
+	// Target は確認の対象です
+	// gateway: ゲートウェイのIPアドレス（省略時はプロファイルの gateway）、dns: ホスト名、tcp: "ホスト:ポート"
+	Target string `json:"target,omitempty"`
+	// TimeoutSeconds は確認を打ち切るまでの秒数です（0 の場合は DefaultHealthCheckTimeout）
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+}
+
+// Timeout は確認を打ち切るまでの時間を返します
+func (h *HealthCheck) Timeout() time.Duration {
+	if h.TimeoutSeconds <= 0 {
+		return DefaultHealthCheckTimeout
+	}
+	return time.Duration(h.TimeoutSeconds) * time.Second
+}
+
+// String は確認の内容を表示用の文字列で返します（例: "ゲートウェイ 192.168.1.1"、"TCP intranet:443"）
+func (h *HealthCheck) String() string {
+	switch h.Type {
+	case HealthCheckGateway:
+		return "ゲートウェイ " + h.Target
+	case HealthCheckDNS:
+		return "名前解決 " + h.Target
+	case HealthCheckTCP:
+		return "TCP " + h.Target
+	default:
+		return h.Type + " " + h.Target
+	}
+}
+
+// Format は設定画面やコマンドラインの入力形式（"種類 対象 タイムアウト秒"）で返します
+// 例: "gateway"、"dns intranet.corp.example.com"、"tcp intranet:443 10"
+func (h HealthCheck) Format() string {
+	parts := []string{h.Type}
+	if h.Target != "" {
+		parts = append(parts, h.Target)
+	}
+	if h.TimeoutSeconds != 0 {
+		parts = append(parts, strconv.Itoa(h.TimeoutSeconds))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseHealthCheck は "種類 [対象] [タイムアウト秒]" 形式の文字列を疎通確認に変換します
+// 種類と対象の形式は検証しません（Profile.ValidateAll で検証）
+func ParseHealthCheck(s string) (HealthCheck, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 3 {
+		return HealthCheck{}, fmt.Errorf("%w: 「種類 対象 タイムアウト秒」の形式で指定してください: %q", ErrInvalidHealthCheck, s)
+	}
+
+	check := HealthCheck{Type: fields[0]}
+	// ゲートウェイは対象を省略してタイムアウトだけを指定できる（"gateway 10"）
+	if len(fields) == 2 && strings.EqualFold(check.Type, HealthCheckGateway) {
+		if _, err := strconv.Atoi(fields[1]); err == nil {
+			fields = []string{fields[0], "", fields[1]}
+		}
+	}
+	if len(fields) >= 2 {
+		check.Target = fields[1]
+	}
+	if len(fields) == 3 {
+		timeout, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return HealthCheck{}, fmt.Errorf("%w: タイムアウトは秒数で指定してください: %q", ErrInvalidHealthCheck, fields[2])
+		}
+		check.TimeoutSeconds = timeout
+	}
+	check.normalize()
+	return check, nil
+}
+
+// normalizeHealthChecks はプロファイルの疎通確認をすべて正規化します
+func (p *Profile) normalizeHealthChecks() {
+	for i := range p.HealthChecks {
+		p.HealthChecks[i].normalize()
+	}
+}
+
+// normalize は前後の空白を取り除き、種類を小文字にします
+func (h *HealthCheck) normalize() {
+	h.Type = strings.ToLower(strings.TrimSpace(h.Type))
+	h.Target = strings.TrimSpace(h.Target)
+}
+
+// validate は疎通確認を検証します（field は "healthChecks[0]" のようなプロファイル内の項目名）
+func (h *HealthCheck) validate(p *Profile, field string, issues *issueList) {
+	if h.TimeoutSeconds < 0 || h.TimeoutSeconds > MaxHealthCheckTimeoutSeconds {
+		issues.error(field+".timeoutSeconds", fmt.Errorf("%w: タイムアウトは0〜%d秒で指定してください: %d", ErrInvalidHealthCheck, MaxHealthCheckTimeoutSeconds, h.TimeoutSeconds))
+	}
+
+	switch h.Type {
+	case HealthCheckGateway:
+		switch {
+		case h.Target == "" && p.Gateway == "":
+			issues.error(field+".target", fmt.Errorf("%w: プロファイルにゲートウェイがないため、確認するIPアドレスを指定してください", ErrInvalidHealthCheck))
+		case h.Target != "" && !isValidIPv4(h.Target):
+			issues.error(field+".target", fmt.Errorf("%w: ゲートウェイ: %s", ErrInvalidHealthCheck, h.Target))
+		}
+	case HealthCheckDNS:
+		if !isValidDNSSuffix(h.Target) {
+			issues.error(field+".target", fmt.Errorf("%w: 名前解決するホスト名: %q", ErrInvalidHealthCheck, h.Target))
+		}
+	case HealthCheckTCP:
+		if err := validateHostPort(h.Target); err != nil {
+			issues.error(field+".target", fmt.Errorf("%w: %w", ErrInvalidHealthCheck, err))
+		}
+	default:
+		issues.error(field+".type", fmt.Errorf("%w: 種類は %s, %s, %s のいずれかで指定してください: %q", ErrInvalidHealthCheck, HealthCheckGateway, HealthCheckDNS, HealthCheckTCP, h.Type))
+	}
+}
+
+// ResolvedTarget は確認の対象を返します（gateway で Target が空の場合はプロファイルのゲートウェイ）
+func (h *HealthCheck) ResolvedTarget(p *Profile) string {
+	if h.Type == HealthCheckGateway && h.Target == "" {
+		return p.Gateway
+	}
+	return h.Target
+}
+
+// validateHostPort は "ホスト:ポート" の形式を検証します（IPv6アドレスは "[::1]:443" の形式）
+func validateHostPort(target string) error {
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		return fmt.Errorf("接続先は「ホスト:ポート」の形式で指定してください: %q", target)
+	}
+	if !isValidIPv4(host) && !isValidIPv6(host) && !isValidDNSSuffix(host) {
+		return fmt.Errorf("ホスト: %q", host)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("ポートは1〜65535で指定してください: %q", port)
+	}
+	return nil
+}
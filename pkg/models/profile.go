@@ -14,19 +14,264 @@ type Config struct {
 	Version   string    `json:"version"`
 	AutoStart bool      `json:"autoStart"`
 	Profiles  []Profile `json:"profiles"`
-	Settings  Settings  `json:"settings"`
+	// Templates は適用時に変数の値を指定してプロファイルを作成するテンプレートです
+	Templates []ProfileTemplate `json:"templates,omitempty"`
+	// Scenes は複数のNICをまとめて切り替えるシーンです
+	Scenes []Scene `json:"scenes,omitempty"`
+	// Schedules は指定した曜日・時刻にプロファイルを適用するスケジュールです
+	Schedules []Schedule `json:"schedules,omitempty"`
+	Settings  Settings   `json:"settings"`
+	// Revision は保存のたびに増える番号です（他のプロセスによる更新の検出に使用）
+	Revision int64 `json:"revision"`
+	// ShadowedProfiles は集中管理されたプロファイルとIDが重複するため無効になっているユーザーのプロファイルです
+	// 設定ファイルには Profiles と合わせて保存されます
+	ShadowedProfiles []Profile `json:"-"`
 }
 
 // Profile はIPアドレス設定のプロファイルを表します
 type Profile struct {
-	ID           string `json:"id"`
-	Name         string `json:"name"`
-	IPAddress    string `json:"ipAddress"`
-	SubnetMask   string `json:"subnetMask"`
-	Gateway      string `json:"gateway,omitempty"`
-	DNSPrimary   string `json:"dnsPrimary,omitempty"`
-	DNSSecondary string `json:"dnsSecondary,omitempty"`
-	NICName      string `json:"nicName"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	IPAddress  string `json:"ipAddress"`
+	SubnetMask string `json:"subnetMask"`
+	Gateway    string `json:"gateway,omitempty"`
+	// DNSServers はDNSサーバーの一覧です（先頭から優先順）
+	DNSServers []string `json:"dnsServers,omitempty"`
+	// DNSSuffix は接続固有のDNSサフィックスです（空の場合は変更しません）
+	DNSSuffix string `json:"dnsSuffix,omitempty"`
+	// RegisterDNS はこの接続のアドレスをDNSに登録するかどうかです（nil の場合は変更しません）
+	RegisterDNS *bool  `json:"registerDns,omitempty"`
+	NICName     string `json:"nicName"`
+	// AdditionalAddresses はプライマリアドレスに加えて同じNICに設定するアドレスです
+	AdditionalAddresses []IPAddressEntry `json:"additionalAddresses,omitempty"`
+	// IPv6 の設定（任意）。空の項目は適用時に変更しません
+	IPv6Address      string   `json:"ipv6Address,omitempty"`
+	IPv6PrefixLength int      `json:"ipv6PrefixLength,omitempty"`
+	IPv6Gateway      string   `json:"ipv6Gateway,omitempty"`
+	IPv6DNSServers   []string `json:"ipv6DnsServers,omitempty"`
+	// Routes はプロファイルの適用中だけ有効にする静的ルートです
+	Routes []StaticRoute `json:"routes,omitempty"`
+	// InterfaceMetric はインターフェイスメトリックです（空: 変更しない、"automatic": 自動、数値: 固定）
+	InterfaceMetric string `json:"interfaceMetric,omitempty"`
+	// MTU はIPv4のMTUです（0 の場合は変更しません）
+	MTU int `json:"mtu,omitempty"`
+	// Group はメニューで表示するグループです（"拠点/東京" のように "/" で区切ると階層になります）
+	Group string `json:"group,omitempty"`
+	// Pinned はグループに関係なくメニューの先頭に表示するかどうかです
+	Pinned bool `json:"pinned,omitempty"`
+	// AutoSwitch はネットワーク環境に応じて自動で適用する条件です（nil の場合は自動で適用しません）
+	AutoSwitch *AutoSwitchRule `json:"autoSwitch,omitempty"`
+	// HealthChecks は適用後に行う疎通確認です（上から順に実行）
+	HealthChecks []HealthCheck `json:"healthChecks,omitempty"`
+	// RollbackOnHealthCheckFailure は疎通確認に失敗した場合に変更前の設定に戻すかどうかです（false の場合は通知のみ）
+	RollbackOnHealthCheckFailure bool `json:"rollbackOnHealthCheckFailure,omitempty"`
+	// ManagedSource は集中管理されたプロファイルの読み込み元ファイルです（ユーザーのプロファイルは空）
+	// 集中管理されたプロファイルは読み取り専用で、設定ファイルには保存されません
+	ManagedSource string `json:"-"`
+}
+
+// IsManaged は集中管理された（読み取り専用の）プロファイルかどうかを返します
+func (p *Profile) IsManaged() bool {
+	return p.ManagedSource != ""
+}
+
+// GroupSeparator はグループの階層の区切り文字です
+const GroupSeparator = "/"
+
+// GroupPath はグループの階層を返します（グループがない場合は空）
+func (p *Profile) GroupPath() []string {
+	var path []string
+	for _, name := range strings.Split(p.Group, GroupSeparator) {
+		if name = strings.TrimSpace(name); name != "" {
+			path = append(path, name)
+		}
+	}
+	return path
+}
+
+// NormalizeGroup はグループの各階層の前後の空白と空の階層を取り除きます（例: " 拠点 / 東京/" → "拠点/東京"）
+func NormalizeGroup(group string) string {
+	p := Profile{Group: group}
+	return strings.Join(p.GroupPath(), GroupSeparator)
+}
+
+// Normalize は入力された項目を設定ファイルに保存する形式に変換します
+//
+//   - IPAddress・SubnetMask・追加アドレス・ルートのCIDR表記やプレフィックス長をサブネットマスク形式に変換
+//   - Group の各階層の前後の空白と空の階層を削除
+//   - AutoSwitch の前後の空白を削除し、MACアドレスを "00-11-22-AA-BB-CC" の形式に変換（条件がない場合は nil）
+//   - HealthChecks の前後の空白を削除し、種類を小文字に変換
+//
+// IPアドレスのプレフィックス長と SubnetMask が両方指定され、一致しない場合はエラーを返します
+func (p *Profile) Normalize() error {
+	p.Group = NormalizeGroup(p.Group)
+	p.normalizeAutoSwitch()
+	p.normalizeHealthChecks()
+	return p.normalizeSubnetMasks()
+}
+
+const (
+	// MetricAutomatic はインターフェイスメトリックを自動にする場合の値です
+	MetricAutomatic = "automatic"
+	// MaxInterfaceMetric はインターフェイスメトリックの最大値です
+	MaxInterfaceMetric = 9999
+	// MinMTU, MaxMTU は設定可能なMTUの範囲です
+	MinMTU = 576
+	MaxMTU = 65535
+)
+
+// FixedInterfaceMetric は固定のインターフェイスメトリックを返します
+// 自動または未指定の場合は ok が false になります
+func (p *Profile) FixedInterfaceMetric() (metric int, ok bool) {
+	if p.InterfaceMetric == "" || p.InterfaceMetric == MetricAutomatic {
+		return 0, false
+	}
+	metric, err := strconv.Atoi(p.InterfaceMetric)
+	return metric, err == nil
+}
+
+// StaticRoute は静的ルートを表します
+type StaticRoute struct {
+	Destination string `json:"destination"`
+	SubnetMask  string `json:"subnetMask"`
+	// Gateway は次ホップです（空の場合はリンク上のルート）
+	Gateway string `json:"gateway,omitempty"`
+	// Metric はルートメトリックです（0 の場合は自動）
+	Metric int `json:"metric,omitempty"`
+}
+
+// MaxRouteMetric はルートメトリックの最大値です
+const MaxRouteMetric = 9999
+
+// String は "宛先/マスク via ゲートウェイ" 形式の文字列を返します
+func (r StaticRoute) String() string {
+	s := r.Destination + "/" + r.SubnetMask
+	if r.Gateway != "" {
+		s += " via " + r.Gateway
+	}
+	if r.Metric > 0 {
+		s += fmt.Sprintf(" metric %d", r.Metric)
+	}
+	return s
+}
+
+// Format は ParseStaticRoute で解析できる "宛先/マスク [ゲートウェイ [メトリック]]" 形式の文字列を返します
+// ゲートウェイがない場合、メトリックは出力されません
+func (r StaticRoute) Format() string {
+	s := r.Destination + "/" + r.SubnetMask
+	if r.Gateway != "" {
+		s += " " + r.Gateway
+		if r.Metric > 0 {
+			s += " " + strconv.Itoa(r.Metric)
+		}
+	}
+	return s
+}
+
+// Validate は静的ルートの設定が有効かどうかを検証します
+func (r StaticRoute) Validate() error {
+	if !isValidIPv4(r.Destination) {
+		return fmt.Errorf("無効な宛先: %s", r.Destination)
+	}
+	if !isValidSubnetMask(r.SubnetMask) {
+		return fmt.Errorf("%w: %s", ErrInvalidSubnetMask, r.SubnetMask)
+	}
+	// 宛先はネットワークアドレスでなければならない（例: 10.1.2.3/255.255.0.0 は不可）
+	if !isNetworkAddress(r.Destination, r.SubnetMask) {
+		return fmt.Errorf("宛先 %s はサブネットマスク %s のネットワークアドレスではありません", r.Destination, r.SubnetMask)
+	}
+	if r.Gateway != "" && !isValidIPv4(r.Gateway) {
+		return fmt.Errorf("無効なゲートウェイアドレス: %s", r.Gateway)
+	}
+	if r.Metric < 0 || r.Metric > MaxRouteMetric {
+		return fmt.Errorf("無効なメトリック: %d（0〜%d）", r.Metric, MaxRouteMetric)
+	}
+	return nil
+}
+
+// ParseStaticRoute は "宛先/マスク [ゲートウェイ [メトリック]]" 形式の文字列を解析します
+func ParseStaticRoute(s string) (StaticRoute, error) {
+	fields := strings.Fields(strings.TrimSpace(s))
+	if len(fields) == 0 || len(fields) > 3 {
+		return StaticRoute{}, fmt.Errorf("無効なルート: %s（宛先/マスク [ゲートウェイ [メトリック]] の形式で指定してください）", s)
+	}
+	dest, err := ParseIPAddressEntry(fields[0])
+	if err != nil {
+		return StaticRoute{}, fmt.Errorf("無効なルート: %s（宛先/マスク [ゲートウェイ [メトリック]] の形式で指定してください）", s)
+	}
+	route := StaticRoute{Destination: dest.IPAddress, SubnetMask: dest.SubnetMask}
+	if len(fields) >= 2 {
+		route.Gateway = fields[1]
+	}
+	if len(fields) == 3 {
+		metric, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return StaticRoute{}, fmt.Errorf("無効なメトリック: %s", fields[2])
+		}
+		route.Metric = metric
+	}
+	return route, nil
+}
+
+// DefaultIPv6PrefixLength はプレフィックス長が省略された場合のIPv6プレフィックス長です
+const DefaultIPv6PrefixLength = 64
+
+// HasIPv6 はIPv6の設定項目が1つ以上指定されているかどうかを返します
+func (p *Profile) HasIPv6() bool {
+	return p.IPv6Address != "" || p.IPv6Gateway != "" || len(p.IPv6DNSServers) > 0
+}
+
+// IPv6CIDR は "IPv6アドレス/プレフィックス長" 形式の文字列を返します（アドレス未設定の場合は空文字列）
+func (p *Profile) IPv6CIDR() string {
+	if p.IPv6Address == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%d", p.IPv6Address, p.IPv6PrefixLength)
+}
+
+// ParseIPv6CIDR は "IPv6アドレス/プレフィックス長" 形式の文字列を解析します
+// プレフィックス長が省略された場合は DefaultIPv6PrefixLength を返します
+func ParseIPv6CIDR(s string) (string, int, error) {
+	addr, prefix, found := strings.Cut(strings.TrimSpace(s), "/")
+	if !isValidIPv6(addr) {
+		return "", 0, fmt.Errorf("%w: %s", ErrInvalidIPv6Address, s)
+	}
+	if !found {
+		return addr, DefaultIPv6PrefixLength, nil
+	}
+	length, err := strconv.Atoi(prefix)
+	if err != nil || !isValidIPv6PrefixLength(length) {
+		return "", 0, fmt.Errorf("%w: %s", ErrInvalidIPv6Prefix, prefix)
+	}
+	return addr, length, nil
+}
+
+// IPAddressEntry はIPアドレスとサブネットマスクの組を表します
+type IPAddressEntry struct {
+	IPAddress  string `json:"ipAddress"`
+	SubnetMask string `json:"subnetMask"`
+}
+
+// String は "IPアドレス/サブネットマスク" 形式の文字列を返します
+func (e IPAddressEntry) String() string {
+	return e.IPAddress + "/" + e.SubnetMask
+}
+
+// ParseIPAddressEntry は "IPアドレス/サブネットマスク"、"IPアドレス/プレフィックス長" または
+// "IPアドレス サブネットマスク" 形式の文字列を解析します（プレフィックス長はサブネットマスクに変換）
+func ParseIPAddressEntry(s string) (IPAddressEntry, error) {
+	fields := strings.FieldsFunc(strings.TrimSpace(s), func(r rune) bool {
+		return r == '/' || r == ' ' || r == '\t'
+	})
+	if len(fields) != 2 {
+		return IPAddressEntry{}, fmt.Errorf("%w: %s（IPアドレス/サブネットマスク の形式で指定してください）", ErrInvalidIPAddress, s)
+	}
+	entry := IPAddressEntry{IPAddress: fields[0], SubnetMask: fields[1]}
+	// 変換できないマスクはそのまま残し、検証でエラーにする
+	if mask, err := ParseSubnetMask(entry.SubnetMask); err == nil {
+		entry.SubnetMask = mask
+	}
+	return entry, nil
 }
 
 // Settings はアプリケーションの設定を表します
@@ -34,6 +279,53 @@ type Settings struct {
 	LogLevel            string   `json:"logLevel"`
 	EnableNotifications bool     `json:"enableNotifications"`
 	EnabledDHCPNICs     []string `json:"enabledDHCPNICs,omitempty"`
+	ConfirmBeforeApply  bool     `json:"confirmBeforeApply,omitempty"` // 適用前に変更内容を確認する
+	// ManagedProfilesPath は集中管理されたプロファイルの追加の読み込み元です（ファイルまたはディレクトリ）
+	ManagedProfilesPath string `json:"managedProfilesPath,omitempty"`
+	// AutoSwitch はプロファイルの自動切り替え（AutoSwitchRule）を有効にするかどうかです
+	AutoSwitch bool `json:"autoSwitch,omitempty"`
+	// AutoSwitchDebounceSeconds, AutoSwitchCooldownSeconds は自動切り替えの待ち時間と間隔（秒）です（0 の場合は既定値）
+	AutoSwitchDebounceSeconds int `json:"autoSwitchDebounceSeconds,omitempty"`
+	AutoSwitchCooldownSeconds int `json:"autoSwitchCooldownSeconds,omitempty"`
+	// AddressConflict は適用するIPアドレスを他のホストが使用していた場合の動作です（空の場合は AddressConflictAbort）
+	AddressConflict string `json:"addressConflict,omitempty"`
+}
+
+// Settings.AddressConflict に指定できる値
+const (
+	// AddressConflictAbort は適用を中止します（適用後に重複を検出した場合は変更前の設定に戻します）
+	AddressConflictAbort = "abort"
+	// AddressConflictWarn は警告を通知して適用を続けます
+	AddressConflictWarn = "warn"
+	// AddressConflictIgnore は重複を確認しません
+	AddressConflictIgnore = "ignore"
+)
+
+// AddressConflictPolicies は Settings.AddressConflict に指定できる値です
+var AddressConflictPolicies = []string{AddressConflictAbort, AddressConflictWarn, AddressConflictIgnore}
+
+// AddressConflictPolicy はIPアドレスの重複を検出した場合の動作を返します
+func (s *Settings) AddressConflictPolicy() string {
+	if s.AddressConflict == "" {
+		return AddressConflictAbort
+	}
+	return s.AddressConflict
+}
+
+// FindProfile はIDまたは名前が一致するプロファイルを返します（IDの一致を優先）
+// 見つからない場合は nil を返します
+func (c *Config) FindProfile(nameOrID string) *Profile {
+	for i := range c.Profiles {
+		if c.Profiles[i].ID == nameOrID {
+			return &c.Profiles[i]
+		}
+	}
+	for i := range c.Profiles {
+		if c.Profiles[i].Name == nameOrID {
+			return &c.Profiles[i]
+		}
+	}
+	return nil
 }
 
 // IsNICEnabledForDHCP は指定されたNICがDHCPメニューで有効かどうかを判定します
@@ -58,51 +350,266 @@ func NewProfile() *Profile {
 }
 
 // Validate はプロファイルの設定が有効かどうかを検証します
+// 最初に見つかったエラーを返します。すべての問題を取得する場合は ValidateAll を使用してください
 func (p *Profile) Validate() error {
+	for _, issue := range p.ValidateAll() {
+		if issue.Severity == SeverityError {
+			return issue.Err
+		}
+	}
+	return nil
+}
+
+// ValidateAll はプロファイルの設定を検証し、見つかったすべての問題を返します
+// Path はプロファイル内の項目名（JSON の名前）です
+func (p *Profile) ValidateAll() ValidationIssues {
+	var issues issueList
+
 	if p.Name == "" {
-		return ErrInvalidProfileName
+		issues.error("name", ErrInvalidProfileName)
 	}
+
 	if p.IPAddress == "" {
-		return ErrInvalidIPAddress
+		issues.error("ipAddress", ErrInvalidIPAddress)
+	} else if !isValidIPv4(p.IPAddress) {
+		// IPアドレスの形式検証
+		issues.error("ipAddress", fmt.Errorf("%w: %s", ErrInvalidIPAddress, p.IPAddress))
 	}
+
 	if p.SubnetMask == "" {
-		return ErrInvalidSubnetMask
+		issues.error("subnetMask", ErrInvalidSubnetMask)
+	} else if !isValidSubnetMask(p.SubnetMask) {
+		// サブネットマスクの形式検証
+		issues.error("subnetMask", fmt.Errorf("%w: %s", ErrInvalidSubnetMask, p.SubnetMask))
 	}
+
 	if p.NICName == "" {
-		return ErrInvalidNICName
+		issues.error("nicName", ErrInvalidNICName)
+	} else if !IsValidNICName(p.NICName) {
+		// NIC名に危険な文字が含まれていないかチェック
+		issues.error("nicName", fmt.Errorf("%w: 不正な文字が含まれています", ErrInvalidNICName))
 	}
 
-	// NIC名に危険な文字が含まれていないかチェック
-	if !IsValidNICName(p.NICName) {
-		return fmt.Errorf("%w: 不正な文字が含まれています", ErrInvalidNICName)
+	// ゲートウェイの形式検証（設定されている場合）
+	if p.Gateway != "" && !isValidIPv4(p.Gateway) {
+		issues.error("gateway", fmt.Errorf("%w: %s", ErrInvalidGateway, p.Gateway))
 	}
 
-	// IPアドレスの形式検証
-	if !isValidIPv4(p.IPAddress) {
-		return fmt.Errorf("%w: %s", ErrInvalidIPAddress, p.IPAddress)
+	// IPアドレス・マスク・ゲートウェイの組み合わせの検証
+	if isValidIPv4(p.IPAddress) && isValidSubnetMask(p.SubnetMask) {
+		p.validateSubnet(&issues)
 	}
 
-	// サブネットマスクの形式検証
-	if !isValidSubnetMask(p.SubnetMask) {
-		return fmt.Errorf("%w: %s", ErrInvalidSubnetMask, p.SubnetMask)
+	// DNSサーバーの形式検証（設定されている場合）
+	seenDNS := make(map[string]bool)
+	for i, dns := range p.DNSServers {
+		field := fmt.Sprintf("dnsServers[%d]", i)
+		switch {
+		case !isValidIPv4(dns):
+			issues.error(field, fmt.Errorf("%w: DNSサーバー%d: %s", ErrInvalidDNSServer, i+1, dns))
+		case seenDNS[dns]:
+			issues.error(field, fmt.Errorf("%w: DNSサーバー%d: %s", ErrDuplicateValue, i+1, dns))
+		case isUnreachableDNSServer(dns):
+			issues.warn(field, fmt.Errorf("%w: DNSサーバー%d: %s", ErrUnreachableDNSServer, i+1, dns))
+		}
+		seenDNS[dns] = true
 	}
 
-	// ゲートウェイの形式検証（設定されている場合）
-	if p.Gateway != "" && !isValidIPv4(p.Gateway) {
-		return fmt.Errorf("無効なゲートウェイアドレス: %s", p.Gateway)
+	// DNSサフィックスの形式検証（設定されている場合）
+	if p.DNSSuffix != "" && !isValidDNSSuffix(p.DNSSuffix) {
+		issues.error("dnsSuffix", fmt.Errorf("%w: %s", ErrInvalidDNSSuffix, p.DNSSuffix))
 	}
 
-	// DNSサーバーの形式検証（設定されている場合）
-	if p.DNSPrimary != "" && !isValidIPv4(p.DNSPrimary) {
-		return fmt.Errorf("無効な優先DNSサーバー: %s", p.DNSPrimary)
+	// 追加アドレスの検証
+	p.validateAdditionalAddresses(&issues)
+
+	// IPv6設定の検証
+	p.validateIPv6(&issues)
+
+	// インターフェイスメトリックとMTUの検証
+	if p.InterfaceMetric != "" && p.InterfaceMetric != MetricAutomatic {
+		if metric, ok := p.FixedInterfaceMetric(); !ok || metric < 1 || metric > MaxInterfaceMetric {
+			issues.error("interfaceMetric", fmt.Errorf("%w: %s（%s または 1〜%d）", ErrInvalidMetric, p.InterfaceMetric, MetricAutomatic, MaxInterfaceMetric))
+		}
+	}
+	if p.MTU != 0 && (p.MTU < MinMTU || p.MTU > MaxMTU) {
+		issues.error("mtu", fmt.Errorf("%w: %d（%d〜%d）", ErrInvalidMTU, p.MTU, MinMTU, MaxMTU))
+	}
+
+	// 自動切り替えの条件の検証
+	if p.AutoSwitch != nil {
+		p.AutoSwitch.validate(&issues)
+	}
+
+	// 疎通確認の検証
+	for i := range p.HealthChecks {
+		p.HealthChecks[i].validate(p, fmt.Sprintf("healthChecks[%d]", i), &issues)
+	}
+	if p.RollbackOnHealthCheckFailure && len(p.HealthChecks) == 0 {
+		issues.warn("rollbackOnHealthCheckFailure", fmt.Errorf("疎通確認がないため、変更前の設定に戻すことはありません"))
+	}
+
+	// 静的ルートの検証
+	seenRoutes := make(map[string]bool)
+	for i, route := range p.Routes {
+		field := fmt.Sprintf("routes[%d]", i)
+		if err := route.Validate(); err != nil {
+			issues.error(field, fmt.Errorf("%w: ルート%d: %w", ErrInvalidRoute, i+1, err))
+			continue
+		}
+		key := route.Destination + "/" + route.SubnetMask
+		if seenRoutes[key] {
+			issues.error(field, fmt.Errorf("%w: ルート%d: %s", ErrDuplicateValue, i+1, key))
+		}
+		seenRoutes[key] = true
+	}
+
+	return issues.issues
+}
+
+// validateIPv6 はIPv6のアドレス、プレフィックス長、ゲートウェイ、DNSサーバーを検証します
+func (p *Profile) validateIPv6(issues *issueList) {
+	if p.IPv6Address != "" {
+		if !isValidIPv6(p.IPv6Address) {
+			issues.error("ipv6Address", fmt.Errorf("%w: %s", ErrInvalidIPv6Address, p.IPv6Address))
+		}
+		if !isValidIPv6PrefixLength(p.IPv6PrefixLength) {
+			issues.error("ipv6PrefixLength", fmt.Errorf("%w: %d", ErrInvalidIPv6Prefix, p.IPv6PrefixLength))
+		}
+	} else if p.IPv6PrefixLength != 0 {
+		issues.error("ipv6PrefixLength", fmt.Errorf("%w: IPv6アドレスが指定されていません", ErrInvalidIPv6Prefix))
+	}
+
+	if p.IPv6Gateway != "" && !isValidIPv6(p.IPv6Gateway) {
+		issues.error("ipv6Gateway", fmt.Errorf("%w: IPv6ゲートウェイ: %s", ErrInvalidGateway, p.IPv6Gateway))
+	}
+
+	for i, dns := range p.IPv6DNSServers {
+		field := fmt.Sprintf("ipv6DnsServers[%d]", i)
+		if !isValidIPv6(dns) {
+			issues.error(field, fmt.Errorf("%w: IPv6 DNSサーバー%d: %s", ErrInvalidDNSServer, i+1, dns))
+		} else if isUnreachableDNSServer(dns) {
+			issues.warn(field, fmt.Errorf("%w: IPv6 DNSサーバー%d: %s", ErrUnreachableDNSServer, i+1, dns))
+		}
+	}
+}
+
+// validateAdditionalAddresses は追加アドレスの形式と、プライマリアドレスとの重複を検証します
+func (p *Profile) validateAdditionalAddresses(issues *issueList) {
+	seen := map[string]bool{p.IPAddress: true}
+	for i, entry := range p.AdditionalAddresses {
+		field := fmt.Sprintf("additionalAddresses[%d]", i)
+		if !isValidIPv4(entry.IPAddress) {
+			issues.error(field+".ipAddress", fmt.Errorf("%w: 追加アドレス%d: %s", ErrInvalidIPAddress, i+1, entry.IPAddress))
+			continue
+		}
+		if !isValidSubnetMask(entry.SubnetMask) {
+			issues.error(field+".subnetMask", fmt.Errorf("%w: 追加アドレス%d: %s", ErrInvalidSubnetMask, i+1, entry.SubnetMask))
+			continue
+		}
+		if seen[entry.IPAddress] {
+			issues.error(field+".ipAddress", fmt.Errorf("%w: 追加アドレス%d: %s", ErrDuplicateValue, i+1, entry.IPAddress))
+			continue
+		}
+		seen[entry.IPAddress] = true
+		if err := checkHostAddress(entry.IPAddress, entry.SubnetMask); err != nil {
+			issues.error(field+".ipAddress", fmt.Errorf("%w: 追加アドレス%d: %s", err, i+1, entry.IPAddress))
+			continue
+		}
+
+		// プライマリと同じサブネットに属するアドレスは同じマスクでなければならない
+		if SameSubnet(p.IPAddress, entry.IPAddress, p.SubnetMask) && entry.SubnetMask != p.SubnetMask {
+			issues.error(field+".subnetMask", fmt.Errorf("%w: 追加アドレス%d: %s はプライマリと同じサブネットのため、マスクは %s である必要があります",
+				ErrInvalidSubnetMask, i+1, entry.IPAddress, p.SubnetMask))
+		}
 	}
-	if p.DNSSecondary != "" && !isValidIPv4(p.DNSSecondary) {
-		return fmt.Errorf("無効な代替DNSサーバー: %s", p.DNSSecondary)
+}
+
+// validateSubnet はIPアドレス・サブネットマスク・ゲートウェイの組み合わせを検証します
+// IPアドレスとサブネットマスクの形式が正しい場合のみ呼び出します
+//
+//   - /30 以下: ネットワークアドレスとブロードキャストアドレスはIPアドレス・ゲートウェイに使用できない
+//   - /31: ポイントツーポイント接続（RFC 3021）。2つのアドレスはどちらもホストに使用でき、
+//     ゲートウェイは対向のアドレスになる
+//   - /32: 単一ホスト。サブネット内に他のアドレスがないため、ゲートウェイは設定できない
+func (p *Profile) validateSubnet(issues *issueList) {
+	if err := checkHostAddress(p.IPAddress, p.SubnetMask); err != nil {
+		issues.error("ipAddress", fmt.Errorf("%w: %s/%s", err, p.IPAddress, p.SubnetMask))
 	}
 
+	if p.Gateway == "" || !isValidIPv4(p.Gateway) {
+		return
+	}
+	ones, _ := MaskToPrefixLength(p.SubnetMask)
+	switch {
+	case p.Gateway == p.IPAddress:
+		issues.error("gateway", fmt.Errorf("%w: %s", ErrGatewayIsHost, p.Gateway))
+	case ones == 32:
+		issues.error("gateway", fmt.Errorf("%w: /32 のサブネットにはゲートウェイを設定できません", ErrGatewayOutsideSubnet))
+	case !SameSubnet(p.IPAddress, p.Gateway, p.SubnetMask):
+		issues.error("gateway", fmt.Errorf("%w: %s（%s/%s）", ErrGatewayOutsideSubnet, p.Gateway, p.IPAddress, p.SubnetMask))
+	default:
+		if err := checkHostAddress(p.Gateway, p.SubnetMask); err != nil {
+			issues.error("gateway", fmt.Errorf("%w: %s", err, p.Gateway))
+		}
+	}
+}
+
+// checkHostAddress はアドレスがサブネットのネットワークアドレスまたはブロードキャストアドレスでないかを検証します
+// /31 と /32 にはネットワークアドレスとブロードキャストアドレスがないため、常に nil を返します
+func checkHostAddress(ip, mask string) error {
+	if ones, err := MaskToPrefixLength(mask); err != nil || ones >= 31 {
+		return nil
+	}
+	addr := net.ParseIP(ip).To4()
+	m := net.IPMask(net.ParseIP(mask).To4())
+	if addr == nil || m == nil {
+		return nil
+	}
+
+	network := addr.Mask(m)
+	broadcast := make(net.IP, len(network))
+	for i := range network {
+		broadcast[i] = network[i] | ^m[i]
+	}
+	switch {
+	case addr.Equal(network):
+		return ErrNetworkAddress
+	case addr.Equal(broadcast):
+		return ErrBroadcastAddress
+	}
 	return nil
 }
 
+// isUnreachableDNSServer はDNSサーバーがループバックまたはリンクローカルのアドレスかどうかを判定します
+// 通常はNICの外にあるDNSサーバーを指定するため、入力ミスの可能性があります
+func isUnreachableDNSServer(dns string) bool {
+	ip := net.ParseIP(dns)
+	return ip != nil && (ip.IsLoopback() || ip.IsLinkLocalUnicast())
+}
+
+// SameSubnet は2つのIPv4アドレスが指定されたマスクで同じサブネットに属するかどうかを判定します
+func SameSubnet(a, b, mask string) bool {
+	ipA := net.ParseIP(a).To4()
+	ipB := net.ParseIP(b).To4()
+	m := net.ParseIP(mask).To4()
+	if ipA == nil || ipB == nil || m == nil {
+		return false
+	}
+	ipMask := net.IPMask(m)
+	return ipA.Mask(ipMask).Equal(ipB.Mask(ipMask))
+}
+
+// isNetworkAddress はIPv4アドレスが指定されたマスクのネットワークアドレスかどうかを判定します
+func isNetworkAddress(ip, mask string) bool {
+	parsed := net.ParseIP(ip).To4()
+	m := net.ParseIP(mask).To4()
+	if parsed == nil || m == nil {
+		return false
+	}
+	return parsed.Mask(net.IPMask(m)).Equal(parsed)
+}
+
 // isValidIPv4 はIPv4アドレスが有効かどうかを検証します
 func isValidIPv4(ip string) bool {
 	parsed := net.ParseIP(ip)
@@ -113,6 +620,44 @@ func isValidIPv4(ip string) bool {
 	return parsed.To4() != nil
 }
 
+// isValidDNSSuffix はDNSサフィックスがドメイン名として有効かどうかを検証します
+// 各ラベルは英数字とハイフンのみで構成され、先頭と末尾にハイフンを含まないこと
+func isValidDNSSuffix(suffix string) bool {
+	if len(suffix) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(suffix, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return false
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			isAlnum := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+			if !isAlnum && c != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isValidIPv6 はIPv6アドレスが有効かどうかを検証します
+func isValidIPv6(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	// IPv4（IPv4射影アドレスを含む）は除外
+	return parsed.To4() == nil
+}
+
+// isValidIPv6PrefixLength はIPv6プレフィックス長が有効かどうかを検証します
+func isValidIPv6PrefixLength(length int) bool {
+	return length >= 1 && length <= 128
+}
+
 // isValidSubnetMask はサブネットマスクが有効かどうかを検証します
 func isValidSubnetMask(mask string) bool {
 	parts := strings.Split(mask, ".")
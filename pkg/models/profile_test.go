@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateSubnet(t *testing.T) {
+	tests := []struct {
+		name    string
+		ip      string
+		mask    string
+		gateway string
+		// wantField は問題のある項目です（空の場合は問題なし）
+		wantField string
+		wantErr   error
+	}{
+		{name: "/24", ip: "192.168.1.10", mask: "255.255.255.0", gateway: "192.168.1.1"},
+		{name: "/24 without gateway", ip: "192.168.1.10", mask: "255.255.255.0"},
+		{name: "network address", ip: "192.168.1.0", mask: "255.255.255.0", wantField: "ipAddress", wantErr: ErrNetworkAddress},
+		{name: "broadcast address", ip: "192.168.1.255", mask: "255.255.255.0", wantField: "ipAddress", wantErr: ErrBroadcastAddress},
+		{name: "gateway outside", ip: "192.168.1.10", mask: "255.255.255.0", gateway: "192.168.2.1", wantField: "gateway", wantErr: ErrGatewayOutsideSubnet},
+		{name: "gateway is host", ip: "192.168.1.10", mask: "255.255.255.0", gateway: "192.168.1.10", wantField: "gateway", wantErr: ErrGatewayIsHost},
+		{name: "gateway broadcast", ip: "192.168.1.10", mask: "255.255.255.0", gateway: "192.168.1.255", wantField: "gateway", wantErr: ErrBroadcastAddress},
+		{name: "/30 network", ip: "10.0.0.4", mask: "255.255.255.252", wantField: "ipAddress", wantErr: ErrNetworkAddress},
+		{name: "/30 host", ip: "10.0.0.5", mask: "255.255.255.252", gateway: "10.0.0.6"},
+		// /31 はネットワークアドレス・ブロードキャストアドレスがなく、両方のアドレスを使用できる（RFC 3021）
+		{name: "/31 lower", ip: "10.0.0.4", mask: "255.255.255.254", gateway: "10.0.0.5"},
+		{name: "/31 upper", ip: "10.0.0.5", mask: "255.255.255.254", gateway: "10.0.0.4"},
+		{name: "/31 gateway outside", ip: "10.0.0.4", mask: "255.255.255.254", gateway: "10.0.0.6", wantField: "gateway", wantErr: ErrGatewayOutsideSubnet},
+		// /32 は単一ホストのため、ゲートウェイは設定できない
+		{name: "/32", ip: "10.0.0.4", mask: "255.255.255.255"},
+		{name: "/32 with gateway", ip: "10.0.0.4", mask: "255.255.255.255", gateway: "10.0.0.1", wantField: "gateway", wantErr: ErrGatewayOutsideSubnet},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Profile{IPAddress: tt.ip, SubnetMask: tt.mask, Gateway: tt.gateway}
+			var issues issueList
+			p.validateSubnet(&issues)
+
+			if tt.wantField == "" {
+				if len(issues.issues) != 0 {
+					t.Errorf("validateSubnet() = %v, want none", issues.issues)
+				}
+				return
+			}
+			if len(issues.issues) != 1 {
+				t.Fatalf("validateSubnet() = %v, want 1 issue", issues.issues)
+			}
+			issue := issues.issues[0]
+			if issue.Path != tt.wantField || issue.Severity != SeverityError || !errors.Is(issue.Err, tt.wantErr) {
+				t.Errorf("validateSubnet() = %s (%s): %v, want %s: %v", issue.Path, issue.Severity, issue.Err, tt.wantField, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAdditionalAddresses(t *testing.T) {
+	tests := []struct {
+		name      string
+		entry     IPAddressEntry
+		wantField string
+		wantErr   error
+	}{
+		{name: "other subnet", entry: IPAddressEntry{IPAddress: "10.0.0.10", SubnetMask: "255.255.255.0"}},
+		{name: "same subnet", entry: IPAddressEntry{IPAddress: "192.168.1.11", SubnetMask: "255.255.255.0"}},
+		{name: "same subnet other mask", entry: IPAddressEntry{IPAddress: "192.168.1.11", SubnetMask: "255.255.0.0"},
+			wantField: "additionalAddresses[0].subnetMask", wantErr: ErrInvalidSubnetMask},
+		{name: "primary", entry: IPAddressEntry{IPAddress: "192.168.1.10", SubnetMask: "255.255.255.0"},
+			wantField: "additionalAddresses[0].ipAddress", wantErr: ErrDuplicateValue},
+		{name: "broadcast", entry: IPAddressEntry{IPAddress: "10.0.0.255", SubnetMask: "255.255.255.0"},
+			wantField: "additionalAddresses[0].ipAddress", wantErr: ErrBroadcastAddress},
+		{name: "/32", entry: IPAddressEntry{IPAddress: "10.0.0.255", SubnetMask: "255.255.255.255"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Profile{IPAddress: "192.168.1.10", SubnetMask: "255.255.255.0", AdditionalAddresses: []IPAddressEntry{tt.entry}}
+			var issues issueList
+			p.validateAdditionalAddresses(&issues)
+
+			if tt.wantField == "" {
+				if len(issues.issues) != 0 {
+					t.Errorf("validateAdditionalAddresses() = %v, want none", issues.issues)
+				}
+				return
+			}
+			if len(issues.issues) != 1 || issues.issues[0].Path != tt.wantField || !errors.Is(issues.issues[0].Err, tt.wantErr) {
+				t.Errorf("validateAdditionalAddresses() = %v, want %s: %v", issues.issues, tt.wantField, tt.wantErr)
+			}
+		})
+	}
+}
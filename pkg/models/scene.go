@@ -0,0 +1,136 @@
+package models
+
+import "fmt"
+
+// Scene は複数のNICの設定をまとめて切り替えるシーンです
+// ステップは順に適用され、いずれかのNICで失敗した場合はすべてのNICを変更前の設定に戻します
+type Scene struct {
+	ID    string      `json:"id"`
+	Name  string      `json:"name"`
+	Steps []SceneStep `json:"steps"`
+	// Group はメニューで表示するグループです（プロファイルの Group と同じ形式）
+	Group string `json:"group,omitempty"`
+}
+
+// SceneStep はシーンで1つのNICに適用する内容です（ProfileID と DHCPNIC のどちらか一方を指定）
+type SceneStep struct {
+	// ProfileID は適用するプロファイルのIDです（NICはプロファイルの nicName）
+	ProfileID string `json:"profileId,omitempty"`
+	// DHCPNIC はDHCPに切り替えるNICの名前です
+	DHCPNIC string `json:"dhcpNic,omitempty"`
+}
+
+// SceneTarget はシーンのステップを適用するNICとプロファイルに変換したものです
+type SceneTarget struct {
+	NICName string
+	// Profile は適用するプロファイルです（nil の場合はDHCPに切り替え）
+	Profile *Profile
+}
+
+// Description は適用内容を表示用の文字列で返します（例: "イーサネット: オフィス"）
+func (t SceneTarget) Description() string {
+	if t.Profile == nil {
+		return t.NICName + ": DHCP"
+	}
+	return fmt.Sprintf("%s: %s", t.NICName, t.Profile.Name)
+}
+
+// GroupPath はグループの階層を返します（グループがない場合は空）
+func (s *Scene) GroupPath() []string {
+	p := Profile{Group: s.Group}
+	return p.GroupPath()
+}
+
+// FindScene はIDまたは名前が一致するシーンを返します（IDの一致を優先）
+// 見つからない場合は nil を返します
+func (c *Config) FindScene(nameOrID string) *Scene {
+	for i := range c.Scenes {
+		if c.Scenes[i].ID == nameOrID {
+			return &c.Scenes[i]
+		}
+	}
+	for i := range c.Scenes {
+		if c.Scenes[i].Name == nameOrID {
+			return &c.Scenes[i]
+		}
+	}
+	return nil
+}
+
+// ResolveScene はシーンのステップを適用するNICとプロファイルに変換し、プロファイルを検証します
+func (c *Config) ResolveScene(scene *Scene) ([]SceneTarget, error) {
+	if issues := c.validateScene(scene); issues.HasErrors() {
+		return nil, issues.Err()
+	}
+
+	targets := make([]SceneTarget, 0, len(scene.Steps))
+	for i, step := range scene.Steps {
+		if step.DHCPNIC != "" {
+			targets = append(targets, SceneTarget{NICName: step.DHCPNIC})
+			continue
+		}
+		profile := *c.findProfileByID(step.ProfileID) // validateScene で存在を確認済み
+		if err := profile.Validate(); err != nil {
+			return nil, &FieldError{Field: fmt.Sprintf("steps[%d].profileId", i), Err: fmt.Errorf("プロファイル %s: %w", profile.Name, err)}
+		}
+		targets = append(targets, SceneTarget{NICName: profile.NICName, Profile: &profile})
+	}
+	return targets, nil
+}
+
+// validateScene はシーンの定義を検証します（Path はシーン内の位置）
+func (c *Config) validateScene(scene *Scene) ValidationIssues {
+	var issues issueList
+
+	if scene.Name == "" {
+		issues.error("name", fmt.Errorf("シーン名がありません"))
+	}
+	if len(scene.Steps) == 0 {
+		issues.error("steps", fmt.Errorf("シーンにステップがありません"))
+	}
+
+	// 同じNICを2回変更すると、失敗時にどの設定に戻すかが決まらないため不可
+	nics := make(map[string]int)
+	for i, step := range scene.Steps {
+		prefix := fmt.Sprintf("steps[%d]", i)
+		var nicName string
+		switch {
+		case step.ProfileID != "" && step.DHCPNIC != "":
+			issues.error(prefix, fmt.Errorf("profileId と dhcpNic のどちらか一方を指定してください"))
+			continue
+		case step.DHCPNIC != "":
+			if !IsValidNICName(step.DHCPNIC) {
+				issues.error(prefix+".dhcpNic", fmt.Errorf("%w: %q", ErrInvalidNICName, step.DHCPNIC))
+				continue
+			}
+			nicName = step.DHCPNIC
+		case step.ProfileID != "":
+			profile := c.findProfileByID(step.ProfileID)
+			if profile == nil {
+				issues.error(prefix+".profileId", fmt.Errorf("プロファイルが見つかりません: %s", step.ProfileID))
+				continue
+			}
+			nicName = profile.NICName
+		default:
+			issues.error(prefix, fmt.Errorf("profileId または dhcpNic を指定してください"))
+			continue
+		}
+
+		if j, ok := nics[nicName]; ok {
+			issues.error(prefix, fmt.Errorf("%w: NIC %s は steps[%d] でも変更します", ErrDuplicateValue, nicName, j))
+		} else {
+			nics[nicName] = i
+		}
+	}
+	return issues.issues
+}
+
+// findProfileByID はIDが一致するプロファイルを返します（名前では検索しません）
+func (c *Config) findProfileByID(id string) *Profile {
+	for i := range c.Profiles {
+		if c.Profiles[i].ID == id {
+			return &c.Profiles[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSchedule はスケジュールの設定が不正な場合のエラーです
+var ErrInvalidSchedule = errors.New("スケジュールが無効です")
+
+// ScheduleMissedGrace は予定時刻を過ぎても切り替えを実行する猶予です
+// スリープ中や起動していなかったために猶予を過ぎた切り替えは実行しません
+const ScheduleMissedGrace = 10 * time.Minute
+
+// ScheduleAction は Due が判定した、スケジュールに対して行う処理です
+type ScheduleAction int
+
+const (
+	// ScheduleIdle は実行済みのため何もしないことを表します
+	ScheduleIdle ScheduleAction = iota
+	// ScheduleRun は切り替えを実行することを表します
+	ScheduleRun
+	// ScheduleMissed は猶予を過ぎたため、実行せずに実行済みとして記録することを表します
+	ScheduleMissed
+	// ScheduleInitial は初めて確認したスケジュールのため、実行せずに直前の予定時刻を記録することを表します
+	// スケジュールを追加した直後に、追加する前の予定時刻の切り替えを実行しないようにします
+	ScheduleInitial
+)
+
+// weekdayNames はスケジュールの曜日の表記です（time.Weekday の順）
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// weekdayLabels は曜日の表示名です（time.Weekday の順）
+var weekdayLabels = []string{"日", "月", "火", "水", "木", "金", "土"}
+
+// Schedule は指定した曜日・時刻にプロファイルを適用する（またはNICをDHCPに切り替える）スケジュールです
+type Schedule struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Time は切り替える時刻です（"09:00" のような24時間制。コンピューターのタイムゾーン）
+	Time string `json:"time"`
+	// Weekdays は切り替える曜日です（"mon"〜"sun"。空の場合は毎日）
+	Weekdays []string `json:"weekdays,omitempty"`
+	// ProfileID は適用するプロファイルのIDです（DHCPNIC とどちらか一方を指定）
+	ProfileID string `json:"profileId,omitempty"`
+	// DHCPNIC はDHCPに切り替えるNICの名前です
+	DHCPNIC string `json:"dhcpNic,omitempty"`
+	// Disabled は一時的にスケジュールを止める場合に true にします
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// clock は Time を時と分に変換します
+func (s *Schedule) clock() (hour, minute int, err error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s.Time))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: 時刻は「09:00」の形式で指定してください: %q", ErrInvalidSchedule, s.Time)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// onWeekday は指定した曜日に切り替えるかどうかを返します
+func (s *Schedule) onWeekday(day time.Weekday) bool {
+	if len(s.Weekdays) == 0 {
+		return true
+	}
+	for _, name := range s.Weekdays {
+		if strings.EqualFold(name, weekdayNames[day]) {
+			return true
+		}
+	}
+	return false
+}
+
+// slotTime は day の日付の指定した時刻を返します
+// 夏時間の開始で時刻が存在しない場合は、時計を進めた後の同じ時刻（02:30 の場合は 03:30）を返します
+func slotTime(day time.Time, hour, minute int) time.Time {
+	at := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+	// 存在しない時刻は時計を進める前の時刻に変換されるため、進めた分を加える
+	if diff := (hour*60 + minute - at.Hour()*60 - at.Minute() + 24*60) % (24 * 60); diff > 0 {
+		at = at.Add(time.Duration(diff) * time.Minute)
+	}
+	return at
+}
+
+// Next は after より後の最初の切り替え時刻を返します
+func (s *Schedule) Next(after time.Time) (time.Time, error) {
+	hour, minute, err := s.clock()
+	if err != nil {
+		return time.Time{}, err
+	}
+	for i := 0; i <= 7; i++ {
+		at := slotTime(after.AddDate(0, 0, i), hour, minute)
+		if at.After(after) && s.onWeekday(at.Weekday()) {
+			return at, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: 切り替える曜日がありません", ErrInvalidSchedule)
+}
+
+// Prev は now 以前の最後の切り替え時刻を返します
+func (s *Schedule) Prev(now time.Time) (time.Time, error) {
+	hour, minute, err := s.clock()
+	if err != nil {
+		return time.Time{}, err
+	}
+	for i := 0; i <= 7; i++ {
+		at := slotTime(now.AddDate(0, 0, -i), hour, minute)
+		if !at.After(now) && s.onWeekday(at.Weekday()) {
+			return at, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: 切り替える曜日がありません", ErrInvalidSchedule)
+}
+
+// Due は now の時点でスケジュールに対して行う処理と、その予定時刻を返します
+// lastRun は最後に実行した（実行済みとして記録した）予定時刻で、記録がない場合はゼロ値です。
+// ScheduleIdle 以外の場合、呼び出し側は due を実行済みとして記録します
+func (s *Schedule) Due(lastRun, now time.Time) (due time.Time, action ScheduleAction, err error) {
+	due, err = s.Prev(now)
+	if err != nil {
+		return time.Time{}, ScheduleIdle, err
+	}
+	switch {
+	case lastRun.IsZero():
+		return due, ScheduleInitial, nil
+	case !lastRun.Before(due):
+		return due, ScheduleIdle, nil
+	case now.Sub(due) > ScheduleMissedGrace:
+		return due, ScheduleMissed, nil
+	}
+	return due, ScheduleRun, nil
+}
+
+// WhenString は曜日と時刻を表示用の文字列で返します（例: "月火水木金 09:00"、"毎日 18:30"）
+func (s *Schedule) WhenString() string {
+	if len(s.Weekdays) == 0 {
+		return "毎日 " + s.Time
+	}
+	var days strings.Builder
+	for day := range weekdayNames {
+		if s.onWeekday(time.Weekday(day)) {
+			days.WriteString(weekdayLabels[day])
+		}
+	}
+	return days.String() + " " + s.Time
+}
+
+// FormatWeekday は曜日の表示名を返します（例: "月"）
+func FormatWeekday(day time.Weekday) string {
+	return weekdayLabels[day]
+}
+
+// TargetString は切り替え先を表示用の文字列で返します（例: "オフィス"、"Wi-Fi: DHCP"）
+func (c *Config) TargetString(s *Schedule) string {
+	if s.DHCPNIC != "" {
+		return s.DHCPNIC + ": DHCP"
+	}
+	if profile := c.findProfileByID(s.ProfileID); profile != nil {
+		return profile.Name
+	}
+	return s.ProfileID
+}
+
+// validateSchedule はスケジュールを検証します（Path はスケジュール内の位置）
+func (c *Config) validateSchedule(s *Schedule) ValidationIssues {
+	var issues issueList
+
+	if _, _, err := s.clock(); err != nil {
+		issues.error("time", err)
+	}
+
+	seen := make(map[string]bool)
+	for i, name := range s.Weekdays {
+		field := fmt.Sprintf("weekdays[%d]", i)
+		name = strings.ToLower(name)
+		switch {
+		case !isWeekdayName(name):
+			issues.error(field, fmt.Errorf("%w: 曜日は %s のいずれかで指定してください: %q", ErrInvalidSchedule, strings.Join(weekdayNames, ", "), name))
+		case seen[name]:
+			issues.error(field, fmt.Errorf("%w: 曜日 %s", ErrDuplicateValue, name))
+		}
+		seen[name] = true
+	}
+
+	switch {
+	case s.ProfileID != "" && s.DHCPNIC != "":
+		issues.error("profileId", fmt.Errorf("%w: profileId と dhcpNic のどちらか一方を指定してください", ErrInvalidSchedule))
+	case s.DHCPNIC != "":
+		if !IsValidNICName(s.DHCPNIC) {
+			issues.error("dhcpNic", fmt.Errorf("%w: %q", ErrInvalidNICName, s.DHCPNIC))
+		}
+	case s.ProfileID != "":
+		if c.findProfileByID(s.ProfileID) == nil {
+			issues.error("profileId", fmt.Errorf("プロファイルが見つかりません: %s", s.ProfileID))
+		}
+	default:
+		issues.error("profileId", fmt.Errorf("%w: profileId または dhcpNic を指定してください", ErrInvalidSchedule))
+	}
+	return issues.issues
+}
+
+// isWeekdayName は曜日の表記（"mon" など）かどうかを判定します
+func isWeekdayName(name string) bool {
+	for _, n := range weekdayNames {
+		if name == n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata" // 夏時間のテストで America/New_York を使用する
+)
+
+// jst は夏時間のない固定のタイムゾーンです
+var jst = time.FixedZone("JST", 9*60*60)
+
+// at は jst の日時を返します（2026-04-06 は月曜日）
+func at(month time.Month, day, hour, minute int) time.Time {
+	return time.Date(2026, month, day, hour, minute, 0, 0, jst)
+}
+
+func TestScheduleNext(t *testing.T) {
+	weekdays := []string{"mon", "tue", "wed", "thu", "fri"}
+	tests := []struct {
+		name     string
+		schedule Schedule
+		after    time.Time
+		want     time.Time
+	}{
+		{"same day", Schedule{Time: "09:00", Weekdays: weekdays}, at(4, 6, 8, 0), at(4, 6, 9, 0)},
+		// after と同じ時刻は含まない
+		{"exactly at", Schedule{Time: "09:00", Weekdays: weekdays}, at(4, 6, 9, 0), at(4, 7, 9, 0)},
+		{"over weekend", Schedule{Time: "09:00", Weekdays: weekdays}, at(4, 10, 9, 30), at(4, 13, 9, 0)},
+		{"sunday after saturday", Schedule{Time: "23:30", Weekdays: []string{"sun"}}, at(4, 11, 23, 45), at(4, 12, 23, 30)},
+		{"one week later", Schedule{Time: "09:00", Weekdays: []string{"MON"}}, at(4, 6, 9, 0), at(4, 13, 9, 0)},
+		{"every day midnight", Schedule{Time: "00:00"}, at(4, 6, 23, 59), at(4, 7, 0, 0)},
+		{"end of month", Schedule{Time: " 08:15 "}, at(4, 30, 9, 0), at(5, 1, 8, 15)},
+		{"end of year", Schedule{Time: "00:00"}, time.Date(2026, 12, 31, 23, 0, 0, 0, jst), time.Date(2027, 1, 1, 0, 0, 0, 0, jst)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.schedule.Next(tt.after)
+			if err != nil || !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, %v, want %s", tt.after, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedulePrev(t *testing.T) {
+	weekdays := []string{"mon", "tue", "wed", "thu", "fri"}
+	tests := []struct {
+		name     string
+		schedule Schedule
+		now      time.Time
+		want     time.Time
+	}{
+		{"same day", Schedule{Time: "09:00", Weekdays: weekdays}, at(4, 7, 10, 0), at(4, 7, 9, 0)},
+		// now と同じ時刻を含む
+		{"exactly at", Schedule{Time: "09:00", Weekdays: weekdays}, at(4, 7, 9, 0), at(4, 7, 9, 0)},
+		{"before today's time", Schedule{Time: "09:00", Weekdays: weekdays}, at(4, 7, 8, 59), at(4, 6, 9, 0)},
+		{"over weekend", Schedule{Time: "09:00", Weekdays: weekdays}, at(4, 13, 8, 0), at(4, 10, 9, 0)},
+		{"one week earlier", Schedule{Time: "09:00", Weekdays: []string{"mon"}}, at(4, 13, 8, 0), at(4, 6, 9, 0)},
+		{"saturday before sunday", Schedule{Time: "23:30", Weekdays: []string{"sat"}}, at(4, 12, 0, 10), at(4, 11, 23, 30)},
+		{"start of year", Schedule{Time: "23:00"}, time.Date(2027, 1, 1, 1, 0, 0, 0, jst), time.Date(2026, 12, 31, 23, 0, 0, 0, jst)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.schedule.Prev(tt.now)
+			if err != nil || !got.Equal(tt.want) {
+				t.Errorf("Prev(%s) = %s, %v, want %s", tt.now, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleInvalid(t *testing.T) {
+	for _, s := range []Schedule{
+		{Time: "9時"},
+		{Time: "24:00"},
+		{Time: "09:00", Weekdays: []string{"monday"}},
+	} {
+		if _, err := s.Next(at(4, 6, 0, 0)); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("Next(%+v) error = %v, want ErrInvalidSchedule", s, err)
+		}
+		if _, err := s.Prev(at(4, 6, 0, 0)); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("Prev(%+v) error = %v, want ErrInvalidSchedule", s, err)
+		}
+		if _, _, err := s.Due(time.Time{}, at(4, 6, 0, 0)); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("Due(%+v) error = %v, want ErrInvalidSchedule", s, err)
+		}
+	}
+}
+
+func TestScheduleDue(t *testing.T) {
+	s := Schedule{Time: "09:00", Weekdays: []string{"mon", "tue", "wed", "thu", "fri"}}
+	tests := []struct {
+		name       string
+		lastRun    time.Time
+		now        time.Time
+		wantDue    time.Time
+		wantAction ScheduleAction
+	}{
+		{"already run", at(4, 7, 9, 0), at(4, 7, 17, 0), at(4, 7, 9, 0), ScheduleIdle},
+		{"due now", at(4, 6, 9, 0), at(4, 7, 9, 0), at(4, 7, 9, 0), ScheduleRun},
+		{"within grace", at(4, 6, 9, 0), at(4, 7, 9, 10), at(4, 7, 9, 0), ScheduleRun},
+		{"after grace", at(4, 6, 9, 0), at(4, 7, 9, 11), at(4, 7, 9, 0), ScheduleMissed},
+		// 金曜日に実行した後、月曜日の予定時刻まで実行しない
+		{"weekend", at(4, 10, 9, 0), at(4, 12, 9, 5), at(4, 10, 9, 0), ScheduleIdle},
+		{"monday after weekend", at(4, 10, 9, 0), at(4, 13, 9, 5), at(4, 13, 9, 0), ScheduleRun},
+		// スリープ中に複数の予定時刻を過ぎた場合も最後の予定時刻だけを判定する
+		{"slept over days", at(4, 6, 9, 0), at(4, 9, 9, 5), at(4, 9, 9, 0), ScheduleRun},
+		// 記録がない場合は猶予の間でも実行しない
+		{"created just after", time.Time{}, at(4, 7, 9, 2), at(4, 7, 9, 0), ScheduleInitial},
+		{"created long after", time.Time{}, at(4, 7, 18, 0), at(4, 7, 9, 0), ScheduleInitial},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due, action, err := s.Due(tt.lastRun, tt.now)
+			if err != nil || !due.Equal(tt.wantDue) || action != tt.wantAction {
+				t.Errorf("Due(%s, %s) = %s, %d, %v, want %s, %d", tt.lastRun, tt.now, due, action, err, tt.wantDue, tt.wantAction)
+			}
+		})
+	}
+}
+
+// TestScheduleDueSequence は一定間隔で確認した場合に、予定時刻ごとに1回だけ実行することを確認します
+func TestScheduleDueSequence(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		schedule Schedule
+		from     time.Time
+		to       time.Time
+		// wantRuns は実行する予定時刻（UTC）です
+		wantRuns []time.Time
+	}{
+		{
+			// 追加した直後の予定時刻は実行せず、翌日から実行する
+			name:     "created just after slot",
+			schedule: Schedule{Time: "09:00"},
+			from:     at(4, 6, 9, 2),
+			to:       at(4, 8, 12, 0),
+			wantRuns: []time.Time{at(4, 7, 9, 0), at(4, 8, 9, 0)},
+		},
+		{
+			name:     "weekdays over weekend",
+			schedule: Schedule{Time: "09:00", Weekdays: []string{"mon", "fri"}},
+			from:     at(4, 9, 12, 0),
+			to:       at(4, 14, 0, 0),
+			wantRuns: []time.Time{at(4, 10, 9, 0), at(4, 13, 9, 0)},
+		},
+		{
+			// 夏時間の開始で存在しない時刻（02:30）は、時計を進めた後に1回だけ実行する
+			name:     "spring forward",
+			schedule: Schedule{Time: "02:30"},
+			from:     time.Date(2026, 3, 7, 12, 0, 0, 0, newYork),
+			to:       time.Date(2026, 3, 9, 12, 0, 0, 0, newYork),
+			wantRuns: []time.Time{
+				time.Date(2026, 3, 8, 7, 30, 0, 0, time.UTC),
+				time.Date(2026, 3, 9, 6, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			// 夏時間の終了で2回ある時刻（01:30）は、1回だけ実行する
+			name:     "fall back",
+			schedule: Schedule{Time: "01:30"},
+			from:     time.Date(2026, 10, 31, 12, 0, 0, 0, newYork),
+			to:       time.Date(2026, 11, 2, 12, 0, 0, 0, newYork),
+			wantRuns: []time.Time{
+				time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC),
+				time.Date(2026, 11, 2, 6, 30, 0, 0, time.UTC),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				lastRun time.Time
+				runs    []time.Time
+			)
+			// 実際の確認間隔と同じく30秒ごとに確認する
+			for now := tt.from; !now.After(tt.to); now = now.Add(30 * time.Second) {
+				due, action, err := tt.schedule.Due(lastRun, now)
+				if err != nil {
+					t.Fatalf("Due() error = %v", err)
+				}
+				switch action {
+				case ScheduleIdle:
+					continue
+				case ScheduleMissed:
+					t.Fatalf("Due(%s) = missed %s", now, due)
+				case ScheduleRun:
+					runs = append(runs, due.UTC())
+				}
+				lastRun = due
+			}
+			if len(runs) != len(tt.wantRuns) {
+				t.Fatalf("runs = %v, want %v", runs, tt.wantRuns)
+			}
+			for i := range runs {
+				if !runs[i].Equal(tt.wantRuns[i]) {
+					t.Errorf("runs[%d] = %s, want %s", i, runs[i], tt.wantRuns[i])
+				}
+			}
+		})
+	}
+}
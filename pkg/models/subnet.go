@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// PrefixLengthToMask はIPv4のプレフィックス長（0〜32）をサブネットマスクに変換します
+func PrefixLengthToMask(prefixLength int) (string, error) {
+	if prefixLength < 0 || prefixLength > 32 {
+		return "", fmt.Errorf("%w: /%d（0〜32）", ErrInvalidSubnetMask, prefixLength)
+	}
+	return net.IP(net.CIDRMask(prefixLength, 32)).String(), nil
+}
+
+// MaskToPrefixLength はサブネットマスクをプレフィックス長に変換します
+func MaskToPrefixLength(mask string) (int, error) {
+	if !isValidSubnetMask(mask) {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidSubnetMask, mask)
+	}
+	ones, _ := net.IPMask(net.ParseIP(mask).To4()).Size()
+	return ones, nil
+}
+
+// ParseSubnetMask はサブネットマスク（255.255.255.0）またはプレフィックス長（24、/24）を解析し、
+// サブネットマスク形式で返します
+// プレフィックス長は 1〜32 です（/0 の 0.0.0.0 はアドレスのサブネットマスクとして使用できないため、エラーを返します）
+func ParseSubnetMask(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if digits := strings.TrimPrefix(s, "/"); digits != "" && !strings.Contains(digits, ".") {
+		prefixLength, err := strconv.Atoi(digits)
+		if err != nil || prefixLength < 1 || prefixLength > 32 {
+			return "", fmt.Errorf("%w: %s（プレフィックス長は 1〜32）", ErrInvalidSubnetMask, s)
+		}
+		return PrefixLengthToMask(prefixLength)
+	}
+	if !isValidSubnetMask(s) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidSubnetMask, s)
+	}
+	return s, nil
+}
+
+// FormatSubnetMask はサブネットマスクをプレフィックス長つきで返します（例: 255.255.255.0 (/24)）
+// サブネットマスクとして解釈できない場合はそのまま返します
+func FormatSubnetMask(mask string) string {
+	prefixLength, err := MaskToPrefixLength(mask)
+	if err != nil {
+		return mask
+	}
+	return fmt.Sprintf("%s (/%d)", mask, prefixLength)
+}
+
+// normalizeSubnetMasks は CIDR 表記やプレフィックス長で入力された項目をサブネットマスク形式に変換します
+//
+//   - IPAddress が "10.20.30.40/24" の場合、アドレスとサブネットマスクに分割
+//   - SubnetMask・追加アドレス・ルートのマスクが "24" や "/24" の場合、サブネットマスクに変換
+//
+// IPアドレスのプレフィックス長と SubnetMask が両方指定され、一致しない場合はエラーを返します
+func (p *Profile) normalizeSubnetMasks() error {
+	if addr, suffix, found := strings.Cut(p.IPAddress, "/"); found {
+		mask, err := ParseSubnetMask(suffix)
+		if err != nil {
+			return &FieldError{Field: "ipAddress", Err: err}
+		}
+		if p.SubnetMask != "" {
+			current, err := ParseSubnetMask(p.SubnetMask)
+			if err != nil {
+				return &FieldError{Field: "subnetMask", Err: err}
+			}
+			if current != mask {
+				return &FieldError{Field: "subnetMask", Err: fmt.Errorf("%w: %s はIPアドレスのプレフィックス長 /%s と一致しません", ErrInvalidSubnetMask, p.SubnetMask, strings.TrimPrefix(suffix, "/"))}
+			}
+		}
+		p.IPAddress = strings.TrimSpace(addr)
+		p.SubnetMask = mask
+	}
+
+	if p.SubnetMask != "" {
+		mask, err := ParseSubnetMask(p.SubnetMask)
+		if err != nil {
+			return &FieldError{Field: "subnetMask", Err: err}
+		}
+		p.SubnetMask = mask
+	}
+
+	for i := range p.AdditionalAddresses {
+		mask, err := ParseSubnetMask(p.AdditionalAddresses[i].SubnetMask)
+		if err != nil {
+			return &FieldError{Field: fmt.Sprintf("additionalAddresses[%d].subnetMask", i), Err: err}
+		}
+		p.AdditionalAddresses[i].SubnetMask = mask
+	}
+	for i := range p.Routes {
+		mask, err := ParseSubnetMask(p.Routes[i].SubnetMask)
+		if err != nil {
+			return &FieldError{Field: fmt.Sprintf("routes[%d]", i), Err: err}
+		}
+		p.Routes[i].SubnetMask = mask
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseSubnetMask(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "255.255.255.0", want: "255.255.255.0"},
+		{input: " 255.255.0.0 ", want: "255.255.0.0"},
+		{input: "24", want: "255.255.255.0"},
+		{input: "/24", want: "255.255.255.0"},
+		{input: "/1", want: "128.0.0.0"},
+		{input: "31", want: "255.255.255.254"},
+		{input: "/32", want: "255.255.255.255"},
+		{input: "", wantErr: true},
+		{input: "/", wantErr: true},
+		// 0.0.0.0 はアドレスのマスクとして使用できない
+		{input: "0", wantErr: true},
+		{input: "/0", wantErr: true},
+		{input: "0.0.0.0", wantErr: true},
+		{input: "33", wantErr: true},
+		{input: "/-1", wantErr: true},
+		{input: "24x", wantErr: true},
+		{input: "255.255.0.255", wantErr: true},
+		{input: "255.255.255.0.0", wantErr: true},
+		{input: "255.255.255.256", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSubnetMask(tt.input)
+		if tt.wantErr {
+			if err == nil || !errors.Is(err, ErrInvalidSubnetMask) || got != "" {
+				t.Errorf("ParseSubnetMask(%q) = %q, %v, want ErrInvalidSubnetMask", tt.input, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseSubnetMask(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestMaskToPrefixLength(t *testing.T) {
+	tests := []struct {
+		mask    string
+		want    int
+		wantErr bool
+	}{
+		{mask: "255.255.255.0", want: 24},
+		{mask: "255.255.255.255", want: 32},
+		{mask: "128.0.0.0", want: 1},
+		{mask: "0.0.0.0", wantErr: true},
+		{mask: "255.0.255.0", wantErr: true},
+		{mask: "24", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := MaskToPrefixLength(tt.mask)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("MaskToPrefixLength(%q) = %d, %v, want %d (error: %t)", tt.mask, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestProfileNormalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		profile   Profile
+		want      Profile
+		wantField string
+	}{
+		{
+			name:    "CIDR address",
+			profile: Profile{IPAddress: "10.20.30.40/24"},
+			want:    Profile{IPAddress: "10.20.30.40", SubnetMask: "255.255.255.0"},
+		},
+		{
+			name:    "CIDR address with same mask",
+			profile: Profile{IPAddress: "10.20.30.40/24", SubnetMask: "/24"},
+			want:    Profile{IPAddress: "10.20.30.40", SubnetMask: "255.255.255.0"},
+		},
+		{
+			name:    "prefix length mask",
+			profile: Profile{IPAddress: "10.20.30.40", SubnetMask: "16"},
+			want:    Profile{IPAddress: "10.20.30.40", SubnetMask: "255.255.0.0"},
+		},
+		{
+			name: "additional addresses and routes",
+			profile: Profile{
+				IPAddress: "10.20.30.40", SubnetMask: "255.255.255.0",
+				AdditionalAddresses: []IPAddressEntry{{IPAddress: "10.0.1.10", SubnetMask: "/24"}},
+				Routes:              []StaticRoute{{Destination: "172.16.0.0", SubnetMask: "12", Gateway: "10.20.30.1"}},
+			},
+			want: Profile{
+				IPAddress: "10.20.30.40", SubnetMask: "255.255.255.0",
+				AdditionalAddresses: []IPAddressEntry{{IPAddress: "10.0.1.10", SubnetMask: "255.255.255.0"}},
+				Routes:              []StaticRoute{{Destination: "172.16.0.0", SubnetMask: "255.240.0.0", Gateway: "10.20.30.1"}},
+			},
+		},
+		{
+			name:    "group",
+			profile: Profile{Group: " 本社 / / 3F ", IPAddress: "10.20.30.40", SubnetMask: "255.255.255.0"},
+			want:    Profile{Group: "本社/3F", IPAddress: "10.20.30.40", SubnetMask: "255.255.255.0"},
+		},
+		{
+			name:    "empty",
+			profile: Profile{},
+			want:    Profile{},
+		},
+		{name: "mismatched mask", profile: Profile{IPAddress: "10.20.30.40/24", SubnetMask: "255.255.0.0"}, wantField: "subnetMask"},
+		{name: "invalid prefix", profile: Profile{IPAddress: "10.20.30.40/33"}, wantField: "ipAddress"},
+		{name: "zero prefix", profile: Profile{IPAddress: "10.20.30.40/0"}, wantField: "ipAddress"},
+		{name: "invalid mask", profile: Profile{IPAddress: "10.20.30.40", SubnetMask: "255.0.255.0"}, wantField: "subnetMask"},
+		{
+			name:      "invalid additional mask",
+			profile:   Profile{IPAddress: "10.20.30.40", SubnetMask: "24", AdditionalAddresses: []IPAddressEntry{{IPAddress: "10.0.1.10", SubnetMask: "/0"}}},
+			wantField: "additionalAddresses[0].subnetMask",
+		},
+		{
+			name:      "invalid route mask",
+			profile:   Profile{IPAddress: "10.20.30.40", SubnetMask: "24", Routes: []StaticRoute{{Destination: "172.16.0.0", SubnetMask: "x"}}},
+			wantField: "routes[0]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.profile
+			err := p.Normalize()
+			if tt.wantField != "" {
+				var fieldErr *FieldError
+				if !errors.As(err, &fieldErr) || fieldErr.Field != tt.wantField || !errors.Is(err, ErrInvalidSubnetMask) {
+					t.Errorf("Normalize() error = %#v, want *FieldError{Field: %s} wrapping ErrInvalidSubnetMask", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+			if !reflect.DeepEqual(p, tt.want) {
+				t.Errorf("Normalize() = %+v, want %+v", p, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrUnknownVariable はテンプレートに定義されていない変数が使われている、または値が指定された場合のエラーです
+	ErrUnknownVariable = errors.New("テンプレートに定義されていない変数です")
+	// ErrMissingVariable は既定値のない変数に値が指定されていない場合のエラーです
+	ErrMissingVariable = errors.New("テンプレートの変数の値がありません")
+)
+
+// placeholderPattern はテンプレートの文字列の項目に書く変数（{{変数名}}）です
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// variableNamePattern はテンプレートの変数名に使用できる文字です
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// TemplateVariable はテンプレートの変数です
+type TemplateVariable struct {
+	Name string `json:"name"`
+	// Label は値を入力するときに表示する説明です（空の場合は Name を表示）
+	Label string `json:"label,omitempty"`
+	// Default は値が指定されなかった場合の値です（空の場合は入力が必要）
+	Default string `json:"default,omitempty"`
+}
+
+// DisplayName は値を入力するときに表示する名前を返します
+func (v *TemplateVariable) DisplayName() string {
+	if v.Label != "" {
+		return v.Label
+	}
+	return v.Name
+}
+
+// ProfileTemplate は変数を含むプロファイルのひな形です
+// Profile の文字列の項目（IPアドレスの最後のオクテットやNIC名など）に {{変数名}} を書くと、
+// 適用時に入力された値に置き換えたプロファイルを作成します
+type ProfileTemplate struct {
+	ID        string             `json:"id"`
+	Name      string             `json:"name"`
+	Variables []TemplateVariable `json:"variables"`
+	// Profile の ID は使用しません。Name が空の場合はテンプレートの名前を使用します
+	Profile Profile `json:"profile"`
+}
+
+// FindTemplate はIDまたは名前が一致するテンプレートを返します（IDの一致を優先）
+// 見つからない場合は nil を返します
+func (c *Config) FindTemplate(nameOrID string) *ProfileTemplate {
+	for i := range c.Templates {
+		if c.Templates[i].ID == nameOrID {
+			return &c.Templates[i]
+		}
+	}
+	for i := range c.Templates {
+		if c.Templates[i].Name == nameOrID {
+			return &c.Templates[i]
+		}
+	}
+	return nil
+}
+
+// Placeholders はプロファイルの項目で使用している変数名を名前順に返します
+func (t *ProfileTemplate) Placeholders() []string {
+	used := make(map[string]bool)
+	expandProfileStrings(&t.Profile, func(s string) (string, error) {
+		for _, m := range placeholderPattern.FindAllStringSubmatch(s, -1) {
+			used[m[1]] = true
+		}
+		return s, nil
+	})
+
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Expand は変数を値に置き換えたプロファイルを作成し、Profile.Validate で検証します
+// values にない変数は Default を使用します。作成したプロファイルの ID はテンプレートの ID です
+func (t *ProfileTemplate) Expand(values map[string]string) (*Profile, error) {
+	resolved := make(map[string]string, len(t.Variables))
+	for _, v := range t.Variables {
+		value := strings.TrimSpace(values[v.Name])
+		if value == "" {
+			value = v.Default
+		}
+		if value == "" {
+			return nil, &FieldError{Field: v.Name, Err: fmt.Errorf("%w: %s", ErrMissingVariable, v.DisplayName())}
+		}
+		resolved[v.Name] = value
+	}
+	for name := range values {
+		if _, ok := resolved[name]; !ok {
+			return nil, &FieldError{Field: name, Err: fmt.Errorf("%w: %s", ErrUnknownVariable, name)}
+		}
+	}
+
+	profile, err := expandProfileStrings(&t.Profile, func(s string) (string, error) {
+		var unknown string
+		expanded := placeholderPattern.ReplaceAllStringFunc(s, func(m string) string {
+			name := placeholderPattern.FindStringSubmatch(m)[1]
+			value, ok := resolved[name]
+			if !ok {
+				unknown = name
+			}
+			return value
+		})
+		if unknown != "" {
+			return "", fmt.Errorf("%w: %s", ErrUnknownVariable, unknown)
+		}
+		return expanded, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	profile.ID = t.ID
+	if profile.Name == "" {
+		profile.Name = t.Name
+	}
+	if err := profile.Normalize(); err != nil {
+		return nil, err
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// ValidateAll はテンプレートの定義を検証します（変数を置き換えた結果の検証は Expand で行います）
+func (t *ProfileTemplate) ValidateAll() ValidationIssues {
+	var issues issueList
+
+	if t.Name == "" {
+		issues.error("name", ErrInvalidProfileName)
+	}
+
+	defined := make(map[string]bool, len(t.Variables))
+	for i, v := range t.Variables {
+		path := fmt.Sprintf("variables[%d].name", i)
+		switch {
+		case !variableNamePattern.MatchString(v.Name):
+			issues.error(path, fmt.Errorf("変数名には英数字と _ を使用してください: %q", v.Name))
+		case defined[v.Name]:
+			issues.error(path, fmt.Errorf("%w: 変数 %s", ErrDuplicateValue, v.Name))
+		}
+		defined[v.Name] = true
+	}
+
+	used := make(map[string]bool)
+	for _, name := range t.Placeholders() {
+		used[name] = true
+		if !defined[name] {
+			issues.error("profile", fmt.Errorf("%w: %s", ErrUnknownVariable, name))
+		}
+	}
+	for i, v := range t.Variables {
+		if defined[v.Name] && !used[v.Name] {
+			issues.warn(fmt.Sprintf("variables[%d].name", i), fmt.Errorf("変数 %s はプロファイルで使用されていません", v.Name))
+		}
+	}
+	return issues.issues
+}
+
+// ParseTemplateValues は "変数名=値" の一覧を変数名と値の対応に変換します
+func ParseTemplateValues(pairs []string) (map[string]string, error) {
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("変数は「変数名=値」の形式で指定してください: %s", pair)
+		}
+		values[name] = strings.TrimSpace(value)
+	}
+	return values, nil
+}
+
+// expandProfileStrings はプロファイルのすべての文字列の項目を fn で変換したプロファイルを返します
+func expandProfileStrings(p *Profile, fn func(string) (string, error)) (*Profile, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var fields any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if fields, err = expandStrings(fields, fn); err != nil {
+		return nil, err
+	}
+	if data, err = json.Marshal(fields); err != nil {
+		return nil, err
+	}
+
+	var expanded Profile
+	if err := json.Unmarshal(data, &expanded); err != nil {
+		return nil, err
+	}
+	return &expanded, nil
+}
+
+// expandStrings は JSON の値に含まれる文字列を fn で変換します
+func expandStrings(v any, fn func(string) (string, error)) (any, error) {
+	switch v := v.(type) {
+	case string:
+		return fn(v)
+	case []any:
+		for i := range v {
+			expanded, err := expandStrings(v[i], fn)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = expanded
+		}
+	case map[string]any:
+		for key := range v {
+			expanded, err := expandStrings(v[key], fn)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = expanded
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,263 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Severity は検証で見つかった問題の重大度です
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // 保存・適用できない設定
+	SeverityWarning Severity = "warning" // 動作するが意図しない設定の可能性がある
+)
+
+// LogLevels は Settings.LogLevel に指定できる値です（大文字・小文字は区別しません）
+var LogLevels = []string{"DEBUG", "INFO", "WARN", "WARNING", "ERROR"}
+
+// ValidationIssue は検証で見つかった1つの問題を表します
+type ValidationIssue struct {
+	// Path は問題のある項目の位置です（例: profiles[3].gateway）
+	Path     string   `json:"path"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// Err は項目の位置を含む *FieldError です（errors.Is でエラーの種類を判定できます）
+	Err error `json:"-"`
+}
+
+// String は問題を表示用の文字列で返します
+func (i ValidationIssue) String() string {
+	label := "エラー"
+	if i.Severity == SeverityWarning {
+		label = "警告"
+	}
+	return fmt.Sprintf("[%s] %s: %s", label, i.Path, i.Message)
+}
+
+// ValidationIssues は検証で見つかった問題の一覧です
+type ValidationIssues []ValidationIssue
+
+// HasErrors はエラー（警告以外）が含まれているかどうかを返します
+func (issues ValidationIssues) HasErrors() bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Err はエラーが含まれている場合に *ValidationError を返します（警告のみの場合は nil）
+func (issues ValidationIssues) Err() error {
+	if !issues.HasErrors() {
+		return nil
+	}
+	return &ValidationError{Issues: issues}
+}
+
+// ValidationError は設定全体の検証エラーです
+type ValidationError struct {
+	Issues ValidationIssues
+}
+
+func (e *ValidationError) Error() string {
+	var lines []string
+	for _, issue := range e.Issues {
+		if issue.Severity == SeverityError {
+			lines = append(lines, fmt.Sprintf("%s: %s", issue.Path, issue.Message))
+		}
+	}
+	return fmt.Sprintf("設定に %d 件のエラーがあります\n%s", len(lines), strings.Join(lines, "\n"))
+}
+
+// issueList は検証で見つかった問題を集めます
+type issueList struct {
+	issues ValidationIssues
+}
+
+// error はエラーを追加します
+func (l *issueList) error(path string, err error) {
+	l.append(path, SeverityError, err)
+}
+
+// warn は警告を追加します
+func (l *issueList) warn(path string, err error) {
+	l.append(path, SeverityWarning, err)
+}
+
+// append は問題を追加します（Err は項目の位置を含む *FieldError になります）
+func (l *issueList) append(path string, severity Severity, err error) {
+	l.issues = append(l.issues, ValidationIssue{
+		Path:     path,
+		Severity: severity,
+		Message:  err.Error(),
+		Err:      &FieldError{Field: path, Err: err},
+	})
+}
+
+// add は prefix 配下の項目として問題を追加します
+func (l *issueList) add(prefix string, issues ValidationIssues) {
+	for _, issue := range issues {
+		cause := issue.Err
+		if fieldErr, ok := cause.(*FieldError); ok {
+			cause = fieldErr.Err
+		}
+		l.append(prefix+"."+issue.Path, issue.Severity, cause)
+	}
+}
+
+// Validate は設定全体を検証し、見つかったすべての問題を返します
+// 各プロファイルの検証に加えて、プロファイル間の重複と動作設定を確認します
+func (c *Config) Validate() ValidationIssues {
+	var issues issueList
+
+	ids := make(map[string]int)
+	names := make(map[string]int)
+	addresses := make(map[string]int)
+	rules := make(map[string]int)
+	for i := range c.Profiles {
+		p := &c.Profiles[i]
+		prefix := fmt.Sprintf("profiles[%d]", i)
+		issues.add(prefix, p.ValidateAll())
+
+		if p.ID == "" {
+			issues.error(prefix+".id", fmt.Errorf("プロファイルIDがありません"))
+		} else if j, ok := ids[p.ID]; ok {
+			issues.error(prefix+".id", fmt.Errorf("%w: プロファイルID %s は profiles[%d] と同じです", ErrDuplicateValue, p.ID, j))
+		} else {
+			ids[p.ID] = i
+		}
+
+		if p.Name != "" {
+			if j, ok := names[p.Name]; ok {
+				issues.error(prefix+".name", fmt.Errorf("%w: プロファイル名 %s は profiles[%d] と同じです", ErrDuplicateValue, p.Name, j))
+			} else {
+				names[p.Name] = i
+			}
+		}
+
+		// 同じNICに同じIPアドレスを設定するプロファイルは、どちらを適用しても同じ結果になる
+		if p.NICName != "" && p.IPAddress != "" {
+			key := p.NICName + "\x00" + p.IPAddress
+			if j, ok := addresses[key]; ok {
+				issues.warn(prefix+".ipAddress", fmt.Errorf("%w: %s の %s は profiles[%d] と同じ設定です", ErrDuplicateValue, p.NICName, p.IPAddress, j))
+			} else {
+				addresses[key] = i
+			}
+		}
+
+		// 同じ条件のプロファイルは、自動切り替えでは先のプロファイルだけが適用される
+		if p.AutoSwitch != nil && !p.AutoSwitch.IsEmpty() {
+			key := p.NICName + "\x00" + p.AutoSwitch.String()
+			if j, ok := rules[key]; ok {
+				issues.warn(prefix+".autoSwitch", fmt.Errorf("%w: 自動切り替えの条件が profiles[%d] と同じです（profiles[%d] を適用します）", ErrDuplicateValue, j, j))
+			} else {
+				rules[key] = i
+			}
+		}
+	}
+
+	// テンプレートのIDは適用時にプロファイルのIDとして使用するため、プロファイルとも重複できない
+	templateIDs := make(map[string]int)
+	templateNames := make(map[string]int)
+	for i := range c.Templates {
+		t := &c.Templates[i]
+		prefix := fmt.Sprintf("templates[%d]", i)
+		issues.add(prefix, t.ValidateAll())
+
+		if t.ID == "" {
+			issues.error(prefix+".id", fmt.Errorf("テンプレートIDがありません"))
+		} else if j, ok := ids[t.ID]; ok {
+			issues.error(prefix+".id", fmt.Errorf("%w: テンプレートID %s は profiles[%d] と同じです", ErrDuplicateValue, t.ID, j))
+		} else if j, ok := templateIDs[t.ID]; ok {
+			issues.error(prefix+".id", fmt.Errorf("%w: テンプレートID %s は templates[%d] と同じです", ErrDuplicateValue, t.ID, j))
+		} else {
+			templateIDs[t.ID] = i
+		}
+
+		if t.Name != "" {
+			if j, ok := templateNames[t.Name]; ok {
+				issues.error(prefix+".name", fmt.Errorf("%w: テンプレート名 %s は templates[%d] と同じです", ErrDuplicateValue, t.Name, j))
+			} else {
+				templateNames[t.Name] = i
+			}
+		}
+	}
+
+	// シーンのIDもコマンドラインで区別できるよう、プロファイル・テンプレートと重複できない
+	sceneIDs := make(map[string]int)
+	sceneNames := make(map[string]int)
+	for i := range c.Scenes {
+		s := &c.Scenes[i]
+		prefix := fmt.Sprintf("scenes[%d]", i)
+		issues.add(prefix, c.validateScene(s))
+
+		if s.ID == "" {
+			issues.error(prefix+".id", fmt.Errorf("シーンIDがありません"))
+		} else if j, ok := ids[s.ID]; ok {
+			issues.error(prefix+".id", fmt.Errorf("%w: シーンID %s は profiles[%d] と同じです", ErrDuplicateValue, s.ID, j))
+		} else if j, ok := templateIDs[s.ID]; ok {
+			issues.error(prefix+".id", fmt.Errorf("%w: シーンID %s は templates[%d] と同じです", ErrDuplicateValue, s.ID, j))
+		} else if j, ok := sceneIDs[s.ID]; ok {
+			issues.error(prefix+".id", fmt.Errorf("%w: シーンID %s は scenes[%d] と同じです", ErrDuplicateValue, s.ID, j))
+		} else {
+			sceneIDs[s.ID] = i
+		}
+
+		if s.Name != "" {
+			if j, ok := sceneNames[s.Name]; ok {
+				issues.error(prefix+".name", fmt.Errorf("%w: シーン名 %s は scenes[%d] と同じです", ErrDuplicateValue, s.Name, j))
+			} else {
+				sceneNames[s.Name] = i
+			}
+		}
+	}
+
+	// スケジュールのIDは最後に実行した時刻の記録（state.json）に使用する
+	scheduleIDs := make(map[string]int)
+	for i := range c.Schedules {
+		s := &c.Schedules[i]
+		prefix := fmt.Sprintf("schedules[%d]", i)
+		issues.add(prefix, c.validateSchedule(s))
+
+		if s.ID == "" {
+			issues.error(prefix+".id", fmt.Errorf("スケジュールIDがありません"))
+		} else if j, ok := scheduleIDs[s.ID]; ok {
+			issues.error(prefix+".id", fmt.Errorf("%w: スケジュールID %s は schedules[%d] と同じです", ErrDuplicateValue, s.ID, j))
+		} else {
+			scheduleIDs[s.ID] = i
+		}
+	}
+
+	if c.Settings.LogLevel != "" && !isValidLogLevel(c.Settings.LogLevel) {
+		issues.error("settings.logLevel", fmt.Errorf("不明なログレベル: %s（%s のいずれか）", c.Settings.LogLevel, strings.Join(LogLevels, ", ")))
+	}
+	for i, nic := range c.Settings.EnabledDHCPNICs {
+		if !IsValidNICName(nic) {
+			issues.error(fmt.Sprintf("settings.enabledDHCPNICs[%d]", i), fmt.Errorf("%w: %q", ErrInvalidNICName, nic))
+		}
+	}
+	if c.Settings.AutoSwitchDebounceSeconds < 0 {
+		issues.error("settings.autoSwitchDebounceSeconds", fmt.Errorf("自動切り替えの待ち時間は0以上で指定してください: %d", c.Settings.AutoSwitchDebounceSeconds))
+	}
+	if c.Settings.AutoSwitchCooldownSeconds < 0 {
+		issues.error("settings.autoSwitchCooldownSeconds", fmt.Errorf("自動切り替えの間隔は0以上で指定してください: %d", c.Settings.AutoSwitchCooldownSeconds))
+	}
+	if c.Settings.AddressConflict != "" && !slices.Contains(AddressConflictPolicies, c.Settings.AddressConflict) {
+		issues.error("settings.addressConflict", fmt.Errorf("IPアドレスの重複時の動作は %s のいずれかで指定してください: %s", strings.Join(AddressConflictPolicies, ", "), c.Settings.AddressConflict))
+	}
+
+	return issues.issues
+}
+
+// isValidLogLevel はログレベルが LogLevels のいずれかかどうかを判定します
+func isValidLogLevel(level string) bool {
+	for _, l := range LogLevels {
+		if strings.EqualFold(level, l) {
+			return true
+		}
+	}
+	return false
+}
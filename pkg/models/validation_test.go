@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+// validProfile は検証エラーのないプロファイルを返します
+func validProfile(id, name string) Profile {
+	return Profile{
+		ID:         id,
+		Name:       name,
+		NICName:    "Ethernet",
+		IPAddress:  "192.168.1.10",
+		SubnetMask: "255.255.255.0",
+		Gateway:    "192.168.1.1",
+	}
+}
+
+// wantIssue は期待する検証結果です（Err が nil の場合はエラーの種類を確認しません）
+type wantIssue struct {
+	Path     string
+	Severity Severity
+	Err      error
+}
+
+// checkIssues は issues が want と同じ位置・重大度の問題を同じ順序で含むかを確認します
+func checkIssues(t *testing.T, issues ValidationIssues, want []wantIssue) {
+	t.Helper()
+	if len(issues) != len(want) {
+		t.Errorf("issues = %d件, want %d件", len(issues), len(want))
+		for _, issue := range issues {
+			t.Log(issue.String())
+		}
+		return
+	}
+	for i, w := range want {
+		got := issues[i]
+		if got.Path != w.Path || got.Severity != w.Severity {
+			t.Errorf("issues[%d] = %s (%s), want %s (%s)", i, got.Path, got.Severity, w.Path, w.Severity)
+			continue
+		}
+		var fieldErr *FieldError
+		if !errors.As(got.Err, &fieldErr) || fieldErr.Field != w.Path {
+			t.Errorf("issues[%d].Err = %#v, want *FieldError{Field: %s}", i, got.Err, w.Path)
+		}
+		if w.Err != nil && !errors.Is(got.Err, w.Err) {
+			t.Errorf("issues[%d].Err = %v, want %v", i, got.Err, w.Err)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		cfg := &Config{Profiles: []Profile{validProfile("a", "A"), validProfile("b", "B")}}
+		cfg.Profiles[1].IPAddress = "192.168.1.11"
+		if issues := cfg.Validate(); len(issues) != 0 {
+			t.Errorf("Validate() = %v, want none", issues)
+		}
+	})
+
+	t.Run("profiles and settings", func(t *testing.T) {
+		broken := validProfile("a", "A")
+		broken.Gateway = "192.168.1.x"
+		broken.DNSServers = []string{"127.0.0.1", "8.8.8.8", "8.8.8.8"}
+		cfg := &Config{
+			Profiles: []Profile{validProfile("a", "A"), broken, validProfile("", "C")},
+			Settings: Settings{
+				LogLevel:        "TRACE",
+				EnabledDHCPNICs: []string{"Ethernet", "Wi-Fi; calc"},
+				AddressConflict: "ask",
+			},
+		}
+		checkIssues(t, cfg.Validate(), []wantIssue{
+			{"profiles[1].gateway", SeverityError, ErrInvalidGateway},
+			{"profiles[1].dnsServers[0]", SeverityWarning, ErrUnreachableDNSServer},
+			{"profiles[1].dnsServers[2]", SeverityError, ErrDuplicateValue},
+			{"profiles[1].id", SeverityError, ErrDuplicateValue},
+			{"profiles[1].name", SeverityError, ErrDuplicateValue},
+			{"profiles[1].ipAddress", SeverityWarning, ErrDuplicateValue},
+			{"profiles[2].id", SeverityError, nil},
+			{"profiles[2].ipAddress", SeverityWarning, ErrDuplicateValue},
+			{"settings.logLevel", SeverityError, nil},
+			{"settings.enabledDHCPNICs[1]", SeverityError, ErrInvalidNICName},
+			{"settings.addressConflict", SeverityError, nil},
+		})
+	})
+
+	t.Run("nested paths", func(t *testing.T) {
+		p := validProfile("a", "A")
+		p.AdditionalAddresses = []IPAddressEntry{{IPAddress: "10.0.0.10", SubnetMask: "255.255.0.255"}}
+		p.Routes = []StaticRoute{{Destination: "10.1.0.0", SubnetMask: "255.255.0.0"}, {Destination: "10.1.0.0", SubnetMask: "255.255.0.0"}}
+		cfg := &Config{
+			Profiles:  []Profile{p},
+			Schedules: []Schedule{{ID: "s", Time: "25:00", Weekdays: []string{"mon", "mon"}, ProfileID: "missing"}},
+		}
+		checkIssues(t, cfg.Validate(), []wantIssue{
+			{"profiles[0].additionalAddresses[0].subnetMask", SeverityError, ErrInvalidSubnetMask},
+			{"profiles[0].routes[1]", SeverityError, ErrDuplicateValue},
+			{"schedules[0].time", SeverityError, ErrInvalidSchedule},
+			{"schedules[0].weekdays[1]", SeverityError, ErrDuplicateValue},
+			{"schedules[0].profileId", SeverityError, nil},
+		})
+	})
+}
+
+func TestValidationIssuesErr(t *testing.T) {
+	warnings := ValidationIssues{{Path: "profiles[0].dnsServers[0]", Severity: SeverityWarning, Message: "w"}}
+	if err := warnings.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil for warnings only", err)
+	}
+
+	issues := append(warnings, ValidationIssue{Path: "profiles[0].gateway", Severity: SeverityError, Message: "e"})
+	var validationErr *ValidationError
+	if err := issues.Err(); !errors.As(err, &validationErr) || len(validationErr.Issues) != 2 {
+		t.Errorf("Err() = %v, want *ValidationError with all issues", err)
+	}
+}